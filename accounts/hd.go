@@ -40,6 +40,13 @@ var DefaultBaseDerivationPath = DerivationPath{0x80000000 + 44, 0x80000000 + 571
 // at m/44'/5718350'/0'/1, etc.
 var DefaultLedgerBaseDerivationPath = DerivationPath{0x80000000 + 44, 0x80000000 + 5718350, 0x80000000 + 0, 0}
 
+// ViewKeyComponent is the hardened component appended to the derivation path of
+// the spend key (PrivateKey) of a WAN account to derive its view key (PrivateKey2).
+// As such, the view key of the account at m/44'/5718350'/0'/0/0 is derived at
+// m/44'/5718350'/0'/0/0/1'. The hardened step keeps the view key underivable from
+// the public extended key of the spend key.
+const ViewKeyComponent = 0x80000000 + 1
+
 // DerivationPath represents the computer friendly version of a hierarchical
 // deterministic wallet account derivaion path.
 //
@@ -135,3 +142,11 @@ func (path DerivationPath) String() string {
 	}
 	return result
 }
+
+// ViewKeyPath returns the derivation path of the view key of the WAN account whose
+// spend key is derived at path.
+func (path DerivationPath) ViewKeyPath() DerivationPath {
+	viewPath := make(DerivationPath, len(path), len(path)+1)
+	copy(viewPath, path)
+	return append(viewPath, ViewKeyComponent)
+}
@@ -77,3 +77,14 @@ func TestHDPathParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestViewKeyPath(t *testing.T) {
+	path := DerivationPath{0x80000000 + 44, 0x80000000 + 5718350, 0x80000000 + 0, 0, 3}
+	view := path.ViewKeyPath()
+	if view.String() != "m/44'/5718350'/0'/0/3/1'" {
+		t.Fatalf("wrong view key path %s", view)
+	}
+	if path.String() != "m/44'/5718350'/0'/0/3" {
+		t.Fatalf("spend key path changed to %s", path)
+	}
+}
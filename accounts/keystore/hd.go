@@ -0,0 +1,147 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/tyler-smith/go-bip39"
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+// mnemonicEntropyBits is the entropy of the generated mnemonics, 24 words.
+const mnemonicEntropyBits = 256
+
+var (
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+	ErrInvalidHDKey    = errors.New("invalid derived key, use the next index")
+
+	hdMasterKeySalt = []byte("Bitcoin seed")
+)
+
+// NewMnemonic returns a new random BIP39 mnemonic.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// SeedFromMnemonic returns the BIP39 seed of the mnemonic and its optional
+// passphrase.
+func SeedFromMnemonic(mnemonic string, passphrase string) ([]byte, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+	return bip39.NewSeed(mnemonic, passphrase), nil
+}
+
+// hdChild derives the BIP32 child key of the private key and chain code at index.
+func hdChild(key []byte, chainCode []byte, index uint32) ([]byte, []byte, error) {
+	data := make([]byte, 0, 37)
+	if index >= 0x80000000 {
+		data = append(data, 0)
+		data = append(data, key...)
+	} else {
+		priv, err := crypto.ToECDSA(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = append(data, ECDSAPKCompression(&priv.PublicKey)...)
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	I := mac.Sum(nil)
+
+	N := crypto.S256().Params().N
+	il := new(big.Int).SetBytes(I[:32])
+	if il.Cmp(N) >= 0 {
+		return nil, nil, ErrInvalidHDKey
+	}
+	k := il.Add(il, new(big.Int).SetBytes(key))
+	k.Mod(k, N)
+	if k.Sign() == 0 {
+		return nil, nil, ErrInvalidHDKey
+	}
+
+	child := make([]byte, 32)
+	kb := k.Bytes()
+	copy(child[32-len(kb):], kb)
+	return child, I[32:], nil
+}
+
+// DeriveHDKey derives the BIP32 private key of the seed at path.
+func DeriveHDKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	mac := hmac.New(sha512.New, hdMasterKeySalt)
+	mac.Write(seed)
+	I := mac.Sum(nil)
+
+	key, chainCode := I[:32], I[32:]
+	if k := new(big.Int).SetBytes(key); k.Sign() == 0 || k.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, ErrInvalidHDKey
+	}
+
+	var err error
+	for _, index := range path {
+		if key, chainCode, err = hdChild(key, chainCode, index); err != nil {
+			return nil, err
+		}
+	}
+	return crypto.ToECDSA(key)
+}
+
+// NewKeyFromSeed derives the two keys of a WAN account from the seed. The spend key
+// (PrivateKey) is derived at path and the view key (PrivateKey2) at
+// path.ViewKeyPath(), so the whole account can be restored from the seed.
+func NewKeyFromSeed(seed []byte, path accounts.DerivationPath) (*Key, error) {
+	sk1, err := DeriveHDKey(seed, path)
+	if err != nil {
+		return nil, err
+	}
+	sk2, err := DeriveHDKey(seed, path.ViewKeyPath())
+	if err != nil {
+		return nil, err
+	}
+	return newKeyFromECDSA(sk1, sk2), nil
+}
+
+// ImportMnemonic derives the WAN account of the mnemonic at path and stores it in
+// the key directory, encrypted with passphrase.
+func (ks *KeyStore) ImportMnemonic(mnemonic string, path accounts.DerivationPath, passphrase string) (accounts.Account, error) {
+	seed, err := SeedFromMnemonic(mnemonic, "")
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	key, err := NewKeyFromSeed(seed, path)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	if ks.cache.hasAddress(key.Address) {
+		return accounts.Account{}, errors.New("account already exists")
+	}
+	return ks.importKey(key, passphrase)
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+// Tests the derivation against the test vector 1 of BIP32.
+func TestDeriveHDKey(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for i, tt := range tests {
+		path, err := accounts.ParseDerivationPath(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := DeriveHDKey(seed, path)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if got := hex.EncodeToString(crypto.FromECDSA(key)); got != tt.key {
+			t.Errorf("test %d: key mismatch: have %s, want %s", i, got, tt.key)
+		}
+	}
+}
+
+func TestSeedFromMnemonic(t *testing.T) {
+	mnemonic := strings.Repeat("abandon ", 11) + "about"
+	seed, err := SeedFromMnemonic(mnemonic, "TREZOR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"
+	if hex.EncodeToString(seed) != want {
+		t.Fatalf("seed mismatch: have %x", seed)
+	}
+	if _, err := SeedFromMnemonic(strings.Repeat("abandon ", 12), ""); err != ErrInvalidMnemonic {
+		t.Fatal("bad checksum should be rejected", err)
+	}
+}
+
+func TestImportMnemonic(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	mnemonic, err := NewMnemonic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(strings.Fields(mnemonic)) != 24 {
+		t.Fatal("mnemonic should have 24 words", mnemonic)
+	}
+	seed, _ := SeedFromMnemonic(mnemonic, "")
+
+	path := append(accounts.DerivationPath{}, accounts.DefaultBaseDerivationPath...)
+	a, err := ks.ImportMnemonic(mnemonic, path, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.ImportMnemonic(mnemonic, path, "foo"); err == nil {
+		t.Fatal("the same account shouldn't be imported twice")
+	}
+
+	// the restored account has both keys of the stored one
+	restored, err := NewKeyFromSeed(seed, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ks.GetKey(a, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Address != restored.Address || key.WAddress != restored.WAddress {
+		t.Fatal("restored account mismatch")
+	}
+	if key.PrivateKey2.D.Cmp(key.PrivateKey.D) == 0 {
+		t.Fatal("view key should differ from the spend key")
+	}
+
+	path[len(path)-1]++
+	next, _ := NewKeyFromSeed(seed, path)
+	if next.Address == key.Address || next.WAddress == (common.WAddress{}) {
+		t.Fatal("next index should derive another account")
+	}
+}
@@ -95,6 +95,7 @@ type encryptedKeyJSONV3 struct {
 	Id       string     `json:"id"`
 	Version  int        `json:"version"`
 	WAddress string     `json:"waddress"`
+	Watch    bool       `json:"watch,omitempty"`
 }
 
 type encryptedKeyJSONV1 struct {
@@ -267,6 +267,9 @@ func (ks *KeyStore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
 	if !found {
 		return nil, ErrLocked
 	}
+	if unlockedKey.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
 	// Sign the hash using plain ECDSA operations
 	return crypto.Sign(hash, unlockedKey.PrivateKey)
 }
@@ -281,6 +284,9 @@ func (ks *KeyStore) SignTx(a accounts.Account, tx *types.Transaction, chainID *b
 	if !found {
 		return nil, ErrLocked
 	}
+	if unlockedKey.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
 	// Depending on the presence of the chain ID, sign with EIP155 or homestead
 	if chainID != nil {
 		return types.SignTx(tx, types.NewEIP155Signer(chainID), unlockedKey.PrivateKey)
@@ -296,6 +302,9 @@ func (ks *KeyStore) ComputeOTAPPKeys(a accounts.Account, AX, AY, BX, BY string)
 	if !found {
 		return nil, ErrLocked
 	}
+	if unlockedKey.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
 
 	pub1, priv1, priv2, err := crypto.GenerteOTAPrivateKey(unlockedKey.PrivateKey, unlockedKey.PrivateKey2, AX, AY, BX, BY)
 
@@ -307,6 +316,73 @@ func (ks *KeyStore) ComputeOTAPPKeys(a accounts.Account, AX, AY, BX, BY string)
 	return []string{pub1X, pub1Y, priv1D, priv2D}, err
 }
 
+// GetOTAViewKey returns the public key A and the view key b of an unlocked
+// account, they are enough to recognize the OTAs sent to its wan address.
+func (ks *KeyStore) GetOTAViewKey(a accounts.Account) (*ecdsa.PublicKey, *big.Int, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[a.Address]
+	if !found {
+		return nil, nil, ErrLocked
+	}
+	if unlockedKey.PrivateKey2 == nil {
+		return nil, nil, ErrWAddressInvalid
+	}
+
+	pub, err := unlockedKey.spendPublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return pub, new(big.Int).Set(unlockedKey.PrivateKey2.D), nil
+}
+
+// ComputeOTAKeyImage returns the key image that spending the OTA of the wan
+// address ota reveals. The account must be unlocked.
+func (ks *KeyStore) ComputeOTAKeyImage(a accounts.Account, ota []byte) ([]byte, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	unlockedKey, found := ks.unlocked[a.Address]
+	if !found {
+		return nil, ErrLocked
+	}
+	if unlockedKey.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
+
+	A1, S1, err := GeneratePKPairFromWAddress(ota)
+	if err != nil {
+		return nil, err
+	}
+	priv1, _, err := crypto.GenerateOneTimePrivateKey2528(unlockedKey.PrivateKey, unlockedKey.PrivateKey2, A1, S1)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.FromECDSAPub(crypto.KeyImage(priv1.D, A1)), nil
+}
+
+// CheckOTAOwner reports whether the OTA of the wan address ota was generated for
+// the unlocked account a. Unlike ComputeOTAPPKeys, it only needs the view key and
+// works for view-only accounts.
+func (ks *KeyStore) CheckOTAOwner(a accounts.Account, ota []byte) (bool, error) {
+	A, b, err := ks.GetOTAViewKey(a)
+	if err != nil {
+		return false, err
+	}
+	return IsOTAOwner(A, b, ota), nil
+}
+
+// IsOTAOwner reports whether the OTA of the wan address ota was generated for the
+// account of public key A and view key b.
+func IsOTAOwner(A *ecdsa.PublicKey, b *big.Int, ota []byte) bool {
+	A1, S1, err := GeneratePKPairFromWAddress(ota)
+	if err != nil {
+		return false
+	}
+	return crypto.CompareA1(b.Bytes(), A, S1, A1)
+}
+
 // SignHashWithPassphrase signs hash if the private key matching the given address
 // can be decrypted with the given passphrase. The produced signature is in the
 // [R || S || V] format where V is 0 or 1.
@@ -315,6 +391,9 @@ func (ks *KeyStore) SignHashWithPassphrase(a accounts.Account, passphrase string
 	if err != nil {
 		return nil, err
 	}
+	if key.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
 	defer zeroKey(key.PrivateKey)
 	return crypto.Sign(hash, key.PrivateKey)
 }
@@ -326,6 +405,9 @@ func (ks *KeyStore) SignTxWithPassphrase(a accounts.Account, passphrase string,
 	if err != nil {
 		return nil, err
 	}
+	if key.IsWatchOnly() {
+		return nil, ErrWatchOnly
+	}
 	defer zeroKey(key.PrivateKey)
 
 	// Depending on the presence of the chain ID, sign with EIP155 or homestead
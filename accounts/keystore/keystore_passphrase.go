@@ -174,9 +174,13 @@ func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
 		return nil, ErrInvalidAccountKey
 	}
 
-	cryptoStruct, err := EncryptOnePrivateKey(key.PrivateKey, auth, scryptN, scryptP)
-	if err != nil {
-		return nil, err
+	// a view-only key has no spend key to encrypt
+	cryptoStruct := new(cryptoJSON)
+	if !key.IsWatchOnly() {
+		var err error
+		if cryptoStruct, err = EncryptOnePrivateKey(key.PrivateKey, auth, scryptN, scryptP); err != nil {
+			return nil, err
+		}
 	}
 
 	cryptoStruct2, err := EncryptOnePrivateKey(key.PrivateKey2, auth, scryptN, scryptP)
@@ -191,6 +195,7 @@ func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
 		key.Id.String(),
 		version,
 		hex.EncodeToString(key.WAddress[:]),
+		key.IsWatchOnly(),
 	}
 	return json.Marshal(encryptedKeyJSONV3)
 }
@@ -285,6 +290,9 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 		}
 
 		waddressStr = &k.WAddress
+		if k.Watch {
+			return decryptWatchKey(keyBytes2, keyId, *waddressStr)
+		}
 	}
 
 	key, err := crypto.ToECDSA(keyBytes)
@@ -314,6 +322,25 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 	}, nil
 }
 
+// decryptWatchKey returns the view-only key of the decrypted view key and the
+// hex encoded wan address.
+func decryptWatchKey(keyBytes2 []byte, keyId []byte, waddressStr string) (*Key, error) {
+	key2, err := crypto.ToECDSA(keyBytes2)
+	if err != nil || key2 == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	waddressRaw, err := hex.DecodeString(waddressStr)
+	if err != nil {
+		return nil, err
+	}
+	key, err := NewWatchKey(waddressRaw, key2)
+	if err != nil {
+		return nil, err
+	}
+	key.Id = uuid.UUID(keyId)
+	return key, nil
+}
+
 func decryptKeyV3(keyProtected *encryptedKeyJSONV3, auth string) (keyBytes []byte, keyBytes2 []byte, keyId []byte, err error) {
 	if keyProtected.Version != version {
 		return nil, nil, nil, fmt.Errorf("Version not supported: %v", keyProtected.Version)
@@ -321,9 +348,13 @@ func decryptKeyV3(keyProtected *encryptedKeyJSONV3, auth string) (keyBytes []byt
 
 	keyId = uuid.Parse(keyProtected.Id)
 
-	plainText, err := decryptKeyV3Item(keyProtected.Crypto, auth)
-	if err != nil {
-		return nil, nil, nil, err
+	var plainText []byte
+	if !keyProtected.Watch {
+		if plainText, err = decryptKeyV3Item(keyProtected.Crypto, auth); err != nil {
+			return nil, nil, nil, err
+		}
+	} else if keyProtected.Crypto2.Cipher == "" {
+		return nil, nil, nil, ErrInvalidPrivateKey
 	}
 
 	plainText2, err2 := decryptKeyV3Item(keyProtected.Crypto2, auth)
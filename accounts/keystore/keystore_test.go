@@ -534,3 +534,52 @@ func TestComputeOTAPPKeys(t *testing.T) {
 		t.Errorf("invalid ota pk. pk lenght:%d", len(pk))
 	}
 }
+
+func TestOTAViewKey(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	auth := "wanchain_test"
+	a, err := ks.NewAccount(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ks.GetOTAViewKey(a); err != ErrLocked {
+		t.Fatal("locked account should have no view key", err)
+	}
+	if err := ks.Unlock(a, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	wAddr, err := ks.GetWanAddress(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otaStr, err := genOTA(hexutil.Encode(wAddr[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ota, _ := hexutil.Decode(otaStr)
+
+	A, b, err := ks.GetOTAViewKey(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsOTAOwner(A, b, ota) {
+		t.Fatal("ota of the account not recognized")
+	}
+
+	other, _ := crypto.GenerateKey()
+	if IsOTAOwner(&other.PublicKey, b, ota) || IsOTAOwner(A, other.D, ota) {
+		t.Fatal("ota recognized with another key")
+	}
+
+	image1, err := ks.ComputeOTAKeyImage(a, ota)
+	if err != nil {
+		t.Fatal(err)
+	}
+	image2, _ := ks.ComputeOTAKeyImage(a, ota)
+	if len(image1) == 0 || hexutil.Encode(image1) != hexutil.Encode(image2) {
+		t.Fatal("key image should be deterministic")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/pborman/uuid"
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+var (
+	ErrWatchOnly     = errors.New("view-only account has no spend key")
+	ErrViewKeyDiffer = errors.New("view key doesn't match the wan address")
+)
+
+// A view-only (watch) key holds the view key PrivateKey2 and the wan address but
+// no spend key. It recognizes the OTAs sent to its wan address, but it can't
+// sign, compute the OTA private keys nor their key images, so it doesn't know
+// which of them were spent.
+
+// IsWatchOnly reports whether the key is a view-only key.
+func (k *Key) IsWatchOnly() bool {
+	return k.PrivateKey == nil && k.PrivateKey2 != nil
+}
+
+// spendPublicKey returns the public spend key A of the key, taken from the wan
+// address for view-only keys.
+func (k *Key) spendPublicKey() (*ecdsa.PublicKey, error) {
+	if k.PrivateKey != nil {
+		pub := k.PrivateKey.PublicKey
+		return &pub, nil
+	}
+	A, _, err := GeneratePKPairFromWAddress(k.WAddress[:])
+	return A, err
+}
+
+// NewWatchKey returns the view-only key of the wan address waddr, whose view key
+// is sk2.
+func NewWatchKey(waddr []byte, sk2 *ecdsa.PrivateKey) (*Key, error) {
+	if sk2 == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	A, B, err := GeneratePKPairFromWAddress(waddr)
+	if err != nil {
+		return nil, err
+	}
+	if B.X.Cmp(sk2.PublicKey.X) != 0 || B.Y.Cmp(sk2.PublicKey.Y) != 0 {
+		return nil, ErrViewKeyDiffer
+	}
+
+	key := &Key{
+		Id:          uuid.NewRandom(),
+		Address:     crypto.PubkeyToAddress(*A),
+		PrivateKey2: sk2,
+	}
+	copy(key.WAddress[:], waddr)
+	return key, nil
+}
+
+// ImportWatch stores the view-only key of the wan address waddr and view key sk2
+// into the key directory, encrypting it with the passphrase.
+func (ks *KeyStore) ImportWatch(waddr []byte, sk2 *ecdsa.PrivateKey, passphrase string) (accounts.Account, error) {
+	key, err := NewWatchKey(waddr, sk2)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	if ks.cache.hasAddress(key.Address) {
+		return accounts.Account{}, errors.New("account already exists")
+	}
+	return ks.importKey(key, passphrase)
+}
+
+// ExportWatch exports the view-only key of the account as a JSON key, encrypted
+// with newPassphrase. It can be imported with Import into another key directory.
+func (ks *KeyStore) ExportWatch(a accounts.Account, passphrase, newPassphrase string) (keyJSON []byte, err error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	zeroKey(key.PrivateKey)
+	key.PrivateKey = nil
+
+	var N, P int
+	if store, ok := ks.storage.(*keyStorePassphrase); ok {
+		N, P = store.scryptN, store.scryptP
+	} else {
+		N, P = StandardScryptN, StandardScryptP
+	}
+	return EncryptKey(key, newPassphrase, N, P)
+}
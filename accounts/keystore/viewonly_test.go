@@ -0,0 +1,88 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+func TestWatchAccount(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+	watchDir, watchKs := tmpKeyStore(t, true)
+	defer os.RemoveAll(watchDir)
+
+	a, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyJSON, err := ks.ExportWatch(a, "foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := watchKs.Import(keyJSON, "bar", "baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Address != a.Address {
+		t.Fatal("view-only account address mismatch")
+	}
+
+	key, err := watchKs.GetKey(w, "baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.IsWatchOnly() || key.PrivateKey != nil {
+		t.Fatal("exported key should be view-only")
+	}
+	if _, err := watchKs.ImportWatch(key.WAddress[:], key.PrivateKey2, "baz"); err == nil {
+		t.Fatal("the same account shouldn't be imported twice")
+	}
+	other, _ := crypto.GenerateKey()
+	if _, err := NewWatchKey(key.WAddress[:], other); err != ErrViewKeyDiffer {
+		t.Fatal("view key of another wan address accepted", err)
+	}
+
+	otaStr, err := genOTA(hexutil.Encode(key.WAddress[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ota, _ := hexutil.Decode(otaStr)
+
+	if err := watchKs.Unlock(w, "baz"); err != nil {
+		t.Fatal(err)
+	}
+	if owned, err := watchKs.CheckOTAOwner(w, ota); err != nil || !owned {
+		t.Fatal("ota of the view-only account not recognized", err)
+	}
+	if _, err := watchKs.ComputeOTAKeyImage(w, ota); err != ErrWatchOnly {
+		t.Fatal("view-only account shouldn't compute key images", err)
+	}
+	if _, err := watchKs.SignHash(w, make([]byte, 32)); err != ErrWatchOnly {
+		t.Fatal("view-only account shouldn't sign", err)
+	}
+	tx := types.NewTransaction(0, common.Address{}, new(big.Int), big.NewInt(21000), new(big.Int), nil)
+	if _, err := watchKs.SignTxWithPassphrase(w, "baz", tx, big.NewInt(1)); err != ErrWatchOnly {
+		t.Fatal("view-only account shouldn't sign transactions", err)
+	}
+}
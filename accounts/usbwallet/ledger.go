@@ -21,6 +21,7 @@
 package usbwallet
 
 import (
+	"crypto/ecdsa"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
@@ -32,6 +33,7 @@ import (
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/common/hexutil"
 	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/rlp"
 )
@@ -113,7 +115,7 @@ func (w *ledgerDriver) offline() bool {
 func (w *ledgerDriver) Open(device io.ReadWriter, passphrase string) error {
 	w.device, w.failure = device, nil
 
-	_, err := w.ledgerDerive(accounts.DefaultBaseDerivationPath)
+	_, _, err := w.ledgerDerive(accounts.DefaultBaseDerivationPath)
 	if err != nil {
 		// Ethereum app is not running or in browser mode, nothing more to do, return
 		if err == errLedgerReplyInvalidHeader {
@@ -148,7 +150,15 @@ func (w *ledgerDriver) Heartbeat() error {
 // Derive implements usbwallet.driver, sending a derivation request to the Ledger
 // and returning the Ethereum address located on that derivation path.
 func (w *ledgerDriver) Derive(path accounts.DerivationPath) (common.Address, error) {
-	return w.ledgerDerive(path)
+	_, address, err := w.ledgerDerive(path)
+	return address, err
+}
+
+// DerivePublicKey implements usbwallet.driver, sending a derivation request to the
+// Ledger and returning the public key located on that derivation path.
+func (w *ledgerDriver) DerivePublicKey(path accounts.DerivationPath) (*ecdsa.PublicKey, error) {
+	pub, _, err := w.ledgerDerive(path)
+	return pub, err
 }
 
 // SignTx implements usbwallet.driver, sending the transaction to the Ledger and
@@ -202,8 +212,8 @@ func (w *ledgerDriver) ledgerVersion() ([3]byte, error) {
 	return version, nil
 }
 
-// ledgerDerive retrieves the currently active Ethereum address from a Ledger
-// wallet at the specified derivation path.
+// ledgerDerive retrieves the currently active public key and Ethereum address
+// from a Ledger wallet at the specified derivation path.
 //
 // The address derivation protocol is defined as follows:
 //
@@ -233,7 +243,7 @@ func (w *ledgerDriver) ledgerVersion() ([3]byte, error) {
 //   Ethereum address length | 1 byte
 //   Ethereum address        | 40 bytes hex ascii
 //   Chain code if requested | 32 bytes
-func (w *ledgerDriver) ledgerDerive(derivationPath []uint32) (common.Address, error) {
+func (w *ledgerDriver) ledgerDerive(derivationPath []uint32) (*ecdsa.PublicKey, common.Address, error) {
 	// Flatten the derivation path into the Ledger request
 	path := make([]byte, 1+4*len(derivationPath))
 	path[0] = byte(len(derivationPath))
@@ -243,24 +253,28 @@ func (w *ledgerDriver) ledgerDerive(derivationPath []uint32) (common.Address, er
 	// Send the request and wait for the response
 	reply, err := w.ledgerExchange(ledgerOpRetrieveAddress, ledgerP1DirectlyFetchAddress, ledgerP2DiscardAddressChainCode, path)
 	if err != nil {
-		return common.Address{}, err
+		return nil, common.Address{}, err
 	}
-	// Discard the public key, we don't need that for now
+	// Extract the uncompressed public key
 	if len(reply) < 1 || len(reply) < 1+int(reply[0]) {
-		return common.Address{}, errors.New("reply lacks public key entry")
+		return nil, common.Address{}, errors.New("reply lacks public key entry")
+	}
+	pub := crypto.ToECDSAPub(reply[1 : 1+int(reply[0])])
+	if pub == nil {
+		return nil, common.Address{}, errors.New("reply has invalid public key entry")
 	}
 	reply = reply[1+int(reply[0]):]
 
 	// Extract the Ethereum hex address string
 	if len(reply) < 1 || len(reply) < 1+int(reply[0]) {
-		return common.Address{}, errors.New("reply lacks address entry")
+		return nil, common.Address{}, errors.New("reply lacks address entry")
 	}
 	hexstr := reply[1 : 1+int(reply[0])]
 
 	// Decode the hex sting into an Ethereum address and return
 	var address common.Address
 	hex.Decode(address[:], hexstr)
-	return address, nil
+	return pub, address, nil
 }
 
 // ledgerSign sends the transaction to the Ledger wallet, and waits for the user
@@ -21,12 +21,14 @@
 package usbwallet
 
 import (
+	"crypto/ecdsa"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"math/big"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/golang/protobuf/proto"
 	"github.com/wanchain/go-wanchain/accounts"
 	"github.com/wanchain/go-wanchain/accounts/usbwallet/internal/trezor"
@@ -150,6 +152,20 @@ func (w *trezorDriver) Derive(path accounts.DerivationPath) (common.Address, err
 	return w.trezorDerive(path)
 }
 
+// DerivePublicKey implements usbwallet.driver, sending a public key request to
+// the Trezor and returning the public key located on that derivation path.
+func (w *trezorDriver) DerivePublicKey(path accounts.DerivationPath) (*ecdsa.PublicKey, error) {
+	key := new(trezor.PublicKey)
+	if _, err := w.trezorExchange(&trezor.GetPublicKey{AddressN: path}, key); err != nil {
+		return nil, err
+	}
+	pub, err := btcec.ParsePubKey(key.GetNode().GetPublicKey(), btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	return pub.ToECDSA(), nil
+}
+
 // SignTx implements usbwallet.driver, sending the transaction to the Trezor and
 // waiting for the user to confirm or deny the transaction.
 func (w *trezorDriver) SignTx(path accounts.DerivationPath, tx *types.Transaction, chainID *big.Int) (common.Address, *types.Transaction, error) {
@@ -20,17 +20,21 @@ package usbwallet
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"io"
 	"math/big"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/karalabe/hid"
 	ethereum "github.com/wanchain/go-wanchain"
 	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/log"
 )
 
@@ -64,6 +68,10 @@ type driver interface {
 	// address located on that path.
 	Derive(path accounts.DerivationPath) (common.Address, error)
 
+	// DerivePublicKey sends a derivation request to the USB device and returns the
+	// public key located on that path.
+	DerivePublicKey(path accounts.DerivationPath) (*ecdsa.PublicKey, error)
+
 	// SignTx sends the transaction to the USB device and waits for the user to confirm
 	// or deny the transaction.
 	SignTx(path accounts.DerivationPath, tx *types.Transaction, chainID *big.Int) (common.Address, *types.Transaction, error)
@@ -562,9 +570,43 @@ func (w *wallet) SignTxWithPassphrase(account accounts.Account, passphrase strin
 	return w.SignTx(account, tx, chainID)
 }
 
-// TODO: TBI
+// GetWanAddress implements accounts.Wallet, deriving on the USB device the public
+// keys of the spend key of the account and of its view key, located at the view
+// key path of the spend key one. The path of accounts not pinned to the wallet is
+// taken from their URL, as returned by Derive.
 func (w *wallet) GetWanAddress(account accounts.Account) (common.WAddress, error) {
-	return common.WAddress{}, nil
+	w.stateLock.RLock() // Comms have own mutex, this is for the state fields
+	defer w.stateLock.RUnlock()
+
+	// If the wallet is closed, abort
+	if w.device == nil {
+		return common.WAddress{}, accounts.ErrWalletClosed
+	}
+	path, ok := w.paths[account.Address]
+	if !ok {
+		var err error
+		if account.URL.Scheme != w.url.Scheme || !strings.HasPrefix(account.URL.Path, w.url.Path+"/") {
+			return common.WAddress{}, accounts.ErrUnknownAccount
+		}
+		if path, err = accounts.ParseDerivationPath(strings.TrimPrefix(account.URL.Path, w.url.Path+"/")); err != nil {
+			return common.WAddress{}, err
+		}
+	}
+	<-w.commsLock
+	defer func() { w.commsLock <- struct{}{} }()
+
+	spend, err := w.driver.DerivePublicKey(path)
+	if err != nil {
+		return common.WAddress{}, err
+	}
+	if address := crypto.PubkeyToAddress(*spend); address != account.Address {
+		return common.WAddress{}, fmt.Errorf("account mismatch: expected %s, got %s", account.Address.Hex(), address.Hex())
+	}
+	view, err := w.driver.DerivePublicKey(path.ViewKeyPath())
+	if err != nil {
+		return common.WAddress{}, err
+	}
+	return *keystore.GenerateWaddressFromPK(spend, view), nil
 }
 
 // TODO: TBI
@@ -0,0 +1,137 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/wanchain/go-wanchain/cmd/utils"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/node"
+	"github.com/wanchain/go-wanchain/pos/posapi"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	incentiveCommandAttachFlag = cli.StringFlag{
+		Name:  "attach",
+		Value: node.DefaultIPCEndpoint(clientIdentifier),
+		Usage: "API endpoint to attach to",
+	}
+	incentiveCommandOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "CSV file to write, stdout if not set",
+	}
+	incentiveCommand = cli.Command{
+		Name:      "incentive",
+		Usage:     "Query pos incentive payouts",
+		ArgsUsage: "",
+		Category:  "INCENTIVE COMMANDS",
+		Description: `
+    gwan incentive export --output ./incentive.csv 0x1111111111111111111111111111111111111111 100 200
+
+will export the incentives received by the address from epoch 100 to epoch 200 as csv.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "export",
+				Usage:     "export the incentive payouts of an address as csv",
+				ArgsUsage: "<address> <fromEpoch> <toEpoch>",
+				Action:    utils.MigrateFlags(exportIncentive),
+				Category:  "INCENTIVE COMMANDS",
+				Flags: []cli.Flag{
+					incentiveCommandAttachFlag,
+					incentiveCommandOutputFlag,
+				},
+				Description: `
+	gwan incentive export --output ./incentive.csv 0x1111111111111111111111111111111111111111 100 200
+
+will query pos_getDelegatorIncentives of the attached node and write one row per
+payout: epoch, role, validator, address, type, amount and fee deducted in wei.`,
+			},
+		},
+	}
+)
+
+func exportIncentive(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) < 3 {
+		return errors.New("args count not enough")
+	}
+	if !common.IsHexAddress(args[0]) {
+		return errors.New("invalid address")
+	}
+	addr := common.HexToAddress(args[0])
+	fromEpoch, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return err
+	}
+	toEpoch, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	client, err := dialRPC(ctx.String(incentiveCommandAttachFlag.Name))
+	if err != nil {
+		utils.Fatalf("Unable to attach to gwan node: %v", err)
+	}
+	defer client.Close()
+
+	var entries []posapi.IncentiveLedgerJson
+	if err := client.Call(&entries, "pos_getDelegatorIncentives", addr, fromEpoch, toEpoch); err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if path := ctx.String(incentiveCommandOutputFlag.Name); path != "" {
+		fh, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		out = fh
+	}
+	return writeIncentiveCsv(out, entries)
+}
+
+func writeIncentiveCsv(out io.Writer, entries []posapi.IncentiveLedgerJson) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"epoch", "role", "validator", "address", "type", "amount", "feeDeducted"})
+	for _, e := range entries {
+		amount, fee := "0", "0"
+		if e.Amount != nil {
+			amount = (*big.Int)(e.Amount).String()
+		}
+		if e.FeeDeducted != nil {
+			fee = (*big.Int)(e.FeeDeducted).String()
+		}
+		w.Write([]string{
+			strconv.FormatUint(e.EpochId, 10),
+			e.Role,
+			e.Validator.Hex(),
+			e.Address.Hex(),
+			e.Type,
+			amount,
+			fee,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -96,6 +96,7 @@ var (
 		utils.GasPriceFlag,
 		utils.MinerThreadsFlag,
 		utils.MiningEnabledFlag,
+		utils.PosSignerFlag,
 		utils.TargetGasLimitFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
@@ -178,6 +179,10 @@ func init() {
 		accountCommand,
 		walletCommand,
 		transactionCommand,
+		incentiveCommand,
+		stakingCommand,
+		privacyCommand,
+		posCommand,
 		// See consolecmd.go:
 		consoleCommand,
 		attachCommand,
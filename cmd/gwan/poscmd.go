@@ -0,0 +1,238 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/cmd/utils"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/possigner"
+	"github.com/wanchain/go-wanchain/pos/simulate"
+	"github.com/wanchain/go-wanchain/pos/util"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	posSimulateStakersFlag = cli.StringFlag{
+		Name:  "stakers",
+		Usage: "JSON staker list to simulate, the staker set of the local chain head if not set",
+	}
+	posSimulateEpochFlag = cli.Uint64Flag{
+		Name:  "epoch",
+		Usage: "Epoch to simulate, the current epoch if not set",
+	}
+	posSimulateRFlag = cli.StringFlag{
+		Name:  "r",
+		Usage: "Hex random beacon value, the one of the epoch in the local chain if not set",
+	}
+	posSimulateOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "JSON file to write, stdout if not set",
+	}
+	posSignerEndpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "IPC endpoint to serve the validator signer on",
+	}
+
+	posCommand = cli.Command{
+		Name:     "pos",
+		Usage:    "Pos tools",
+		Category: "POS COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:   "simulate",
+				Usage:  "Simulate the leader selection of an epoch",
+				Action: utils.MigrateFlags(posSimulate),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					posSimulateStakersFlag,
+					posSimulateEpochFlag,
+					posSimulateRFlag,
+					posSimulateOutputFlag,
+				},
+				Description: `
+    gwan pos simulate --stakers ./stakers.json --epoch 18000 --r 0x1234
+
+selects the epoch leaders, random beacon proposers and slot leaders of the epoch the
+same way a node does, and prints them with the selection count and probability of
+each staker. Without --stakers the staker set and the white listed epoch leaders
+are read from the head state of the local chain in --datadir, with --stakers the
+default white list of the network is used.
+
+The staker list is an array of
+    {"pubSec256": "0x04...", "amount": "0x...", "lockEpochs": 30, "feeRate": 1000, "delegated": "0x..."}
+with amounts in wei.
+
+The slot leader sequence depends on secrets of the epoch leaders, it is sampled from
+a deterministic secret message array, so only its distribution is meaningful.`,
+			},
+			{
+				Name:      "signer",
+				Usage:     "Serve the validator keys of an account as a remote signer",
+				ArgsUsage: "<address>",
+				Action:    utils.MigrateFlags(posSigner),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					posSignerEndpointFlag,
+				},
+				Description: `
+    gwan pos signer --endpoint /secure/validator.ipc 0x...
+
+unlocks the account and serves the pos validator crypto (block seal, slot leader
+proof, SMA and random beacon signature share) on the ipc endpoint until it is
+interrupted. A node started with --pos.signer /secure/validator.ipc and the same
+etherbase then mines without the validator keys in its process.`,
+			},
+		},
+	}
+)
+
+func posSimulate(ctx *cli.Context) error {
+	epochID, _ := util.CalEpochSlotID(uint64(time.Now().Unix()))
+	if ctx.IsSet(posSimulateEpochFlag.Name) {
+		epochID = ctx.Uint64(posSimulateEpochFlag.Name)
+	}
+
+	var r *big.Int
+	if s := ctx.String(posSimulateRFlag.Name); s != "" {
+		b, err := hexutil.DecodeBig(s)
+		if err != nil {
+			return err
+		}
+		r = b
+	}
+
+	var (
+		stakers []vm.StakerInfo
+		white   [][]byte
+	)
+	if path := ctx.String(posSimulateStakersFlag.Name); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var list []simulate.Staker
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		for i := range list {
+			info, err := list[i].ToStakerInfo(epochID)
+			if err != nil {
+				return fmt.Errorf("staker %d: %v", i, err)
+			}
+			stakers = append(stakers, info)
+		}
+		// without a state the white list is the default one of the network
+		makeConfigNode(ctx)
+		white = whiteLeaders(&vm.UpgradeWhiteEpochLeaderDefault)
+	} else {
+		stack := makeFullNode(ctx)
+		chain, _ := utils.MakeChain(ctx, stack)
+		stateDb, err := chain.State()
+		if err != nil {
+			return err
+		}
+		stakers = vm.GetStakersSnap(stateDb)
+		white = whiteLeaders(vm.GetEpochWLInfo(stateDb, epochID))
+		if r == nil {
+			// the leaders are selected with the random beacon of the previous epoch
+			rEpochID := epochID
+			if rEpochID > 0 {
+				rEpochID--
+			}
+			r = vm.GetR(stateDb, rEpochID)
+		}
+	}
+	if r == nil {
+		return errors.New("no random beacon value, use --r")
+	}
+
+	schedule, err := simulate.Simulate(stakers, white, r, epochID)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(schedule, "", "  ")
+	if err != nil {
+		return err
+	}
+	if path := ctx.String(posSimulateOutputFlag.Name); path != "" {
+		return ioutil.WriteFile(path, out, 0644)
+	}
+	_, err = os.Stdout.Write(append(out, '\n'))
+	return err
+}
+
+// whiteLeaders returns the public keys of the white listed epoch leaders of info.
+func whiteLeaders(info *vm.UpgradeWhiteEpochLeaderParam) [][]byte {
+	from, count := info.WlIndex.Uint64(), info.WlCount.Uint64()
+	if from+count > uint64(len(posconfig.EpochLeadersHold)) {
+		return nil
+	}
+	return posconfig.EpochLeadersHold[from : from+count]
+}
+
+func posSigner(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("address must be given as argument")
+	}
+	endpoint := ctx.String(posSignerEndpointFlag.Name)
+	if endpoint == "" {
+		utils.Fatalf("--%s must be given", posSignerEndpointFlag.Name)
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, err := utils.MakeAddress(ks, ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Could not find the account: %v", err)
+	}
+	account, err = ks.Find(account)
+	if err != nil {
+		utils.Fatalf("Could not find the account: %v", err)
+	}
+
+	password := getPassPhrase("Unlock the validator account", false, 0, utils.MakePasswordList(ctx))
+	key, err := ks.GetKey(account, password)
+	if err != nil {
+		utils.Fatalf("Could not unlock the account: %v", err)
+	}
+
+	listener, err := possigner.Serve(endpoint, possigner.NewLocalSigner(key))
+	if err != nil {
+		utils.Fatalf("Could not serve the signer: %v", err)
+	}
+	defer listener.Close()
+
+	fmt.Printf("Serving validator %s on %s\n", account.Address.Hex(), endpoint)
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	<-sigc
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wanchain/go-wanchain/cmd/utils"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	privacyAttachFlag = cli.StringFlag{
+		Name:  "attach",
+		Value: node.DefaultIPCEndpoint(clientIdentifier),
+		Usage: "API endpoint to attach to",
+	}
+	privacyToFlag = cli.StringFlag{
+		Name:  "to",
+		Usage: "Account owning the OTA and receiving the refund",
+	}
+	privacyMixSizeFlag = cli.IntFlag{
+		Name:  "mixsize",
+		Value: 8,
+		Usage: "Number of OTAs of the same value mixed in the ring signature",
+	}
+
+	privacyCommand = cli.Command{
+		Name:     "privacy",
+		Usage:    "Manage privacy transactions",
+		Category: "PRIVACY COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "refund",
+				Usage:     "Refund an OTA of the privacy coin contract",
+				ArgsUsage: "<otaAddress>",
+				Action:    utils.MigrateFlags(privacyRefund),
+				Flags: []cli.Flag{
+					privacyAttachFlag,
+					privacyToFlag,
+					privacyMixSizeFlag,
+					utils.PasswordFileFlag,
+				},
+				Description: `
+    gwan privacy refund 0x... --to 0x... --mixsize 8
+
+attaches to a running node, picks the mix set among the OTAs of the same value,
+ring signs the refund with the OTA key derived from the keystore account --to,
+and sends the refund transaction from --to.`,
+			},
+		},
+	}
+)
+
+func privacyRefund(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		return errors.New("OTA address must be given as argument")
+	}
+	ota, err := hexutil.Decode(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("invalid OTA address: %v", err)
+	}
+	if !common.IsHexAddress(ctx.String(privacyToFlag.Name)) {
+		return errors.New("--to must be a valid address")
+	}
+	to := common.HexToAddress(ctx.String(privacyToFlag.Name))
+
+	client, err := dialRPC(ctx.String(privacyAttachFlag.Name))
+	if err != nil {
+		utils.Fatalf("Unable to attach to gwan node: %v", err)
+	}
+	defer client.Close()
+
+	passwd := getPassPhrase("", false, 0, utils.MakePasswordList(ctx))
+	var hash common.Hash
+	if err := client.Call(&hash, "personal_refundOTA", hexutil.Bytes(ota), to, ctx.Int(privacyMixSizeFlag.Name), passwd); err != nil {
+		return err
+	}
+	fmt.Println("Transaction sent:", hash.Hex())
+	return nil
+}
@@ -0,0 +1,252 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/cmd/utils"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/internal/ethapi"
+	"github.com/wanchain/go-wanchain/node"
+	"github.com/wanchain/go-wanchain/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	stakingAttachFlag = cli.StringFlag{
+		Name:  "attach",
+		Value: node.DefaultIPCEndpoint(clientIdentifier),
+		Usage: "API endpoint to attach to",
+	}
+	stakingFromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "Account sending the staking transaction",
+	}
+	stakingValidatorFlag = cli.StringFlag{
+		Name:  "validator",
+		Usage: "Validator address, for register it is the keystore account providing the validator keys (default = from)",
+	}
+	stakingValueFlag = cli.StringFlag{
+		Name:  "value",
+		Value: "0",
+		Usage: "Amount in wan sent with the transaction",
+	}
+	stakingLockEpochsFlag = cli.Uint64Flag{
+		Name:  "lockepochs",
+		Usage: "Lock epochs of the validator (0 means quit for update)",
+	}
+	stakingFeeRateFlag = cli.Uint64Flag{
+		Name:  "feerate",
+		Usage: "Fee rate of the validator, 10000 means 100%",
+	}
+	stakingMaxFeeRateFlag = cli.Uint64Flag{
+		Name:  "maxfeerate",
+		Usage: "Max fee rate of the validator, default is feerate",
+	}
+	stakingRenewalFlag = cli.BoolFlag{
+		Name:  "renewal",
+		Usage: "Renew the partner stake automatically",
+	}
+	stakingSecPkFlag = cli.StringFlag{
+		Name:  "secpk",
+		Usage: "Hex secp256k1 public key of the validator, only needed with --unsigned",
+	}
+	stakingBn256PkFlag = cli.StringFlag{
+		Name:  "bn256pk",
+		Usage: "Hex bn256 public key of the validator, only needed with --unsigned",
+	}
+	stakingUnsignedFlag = cli.BoolFlag{
+		Name:  "unsigned",
+		Usage: "Print the unsigned transaction instead of sending it",
+	}
+	stakingSignOnlyFlag = cli.BoolFlag{
+		Name:  "signonly",
+		Usage: "Print the signed transaction instead of sending it",
+	}
+
+	stakingCommonFlags = []cli.Flag{
+		stakingAttachFlag,
+		stakingFromFlag,
+		stakingUnsignedFlag,
+		stakingSignOnlyFlag,
+		utils.PasswordFileFlag,
+	}
+
+	stakingCommand = cli.Command{
+		Name:     "staking",
+		Usage:    "Build and send pos staking transactions",
+		Category: "STAKING COMMANDS",
+		Description: `
+    gwan staking register --from 0x... --value 50000 --lockepochs 30 --feerate 1000
+
+The staking commands attach to a running node, derive the validator keys from its
+keystore, check the call against the staking rules and the latest state, and send
+the signed transaction. With --signonly the signed transaction is printed instead,
+with --unsigned the unsigned one.`,
+		Subcommands: []cli.Command{
+			{
+				Name:   "in",
+				Usage:  "Register a validator with stakeIn",
+				Action: utils.MigrateFlags(stakingAction("stakeIn")),
+				Flags: append(stakingCommonFlags, stakingValidatorFlag, stakingValueFlag,
+					stakingLockEpochsFlag, stakingFeeRateFlag, stakingSecPkFlag, stakingBn256PkFlag),
+			},
+			{
+				Name:   "register",
+				Usage:  "Register a validator with a max fee rate",
+				Action: utils.MigrateFlags(stakingAction("stakeRegister")),
+				Flags: append(stakingCommonFlags, stakingValidatorFlag, stakingValueFlag,
+					stakingLockEpochsFlag, stakingFeeRateFlag, stakingMaxFeeRateFlag, stakingSecPkFlag, stakingBn256PkFlag),
+			},
+			{
+				Name:   "append",
+				Usage:  "Append stake to a validator",
+				Action: utils.MigrateFlags(stakingAction("stakeAppend")),
+				Flags:  append(stakingCommonFlags, stakingValidatorFlag, stakingValueFlag),
+			},
+			{
+				Name:   "update",
+				Usage:  "Update the lock epochs of a validator",
+				Action: utils.MigrateFlags(stakingAction("stakeUpdate")),
+				Flags:  append(stakingCommonFlags, stakingValidatorFlag, stakingLockEpochsFlag),
+			},
+			{
+				Name:   "updatefee",
+				Usage:  "Update the fee rate of a validator",
+				Action: utils.MigrateFlags(stakingAction("stakeUpdateFeeRate")),
+				Flags:  append(stakingCommonFlags, stakingValidatorFlag, stakingFeeRateFlag),
+			},
+			{
+				Name:   "partnerin",
+				Usage:  "Join a validator as partner",
+				Action: utils.MigrateFlags(stakingAction("partnerIn")),
+				Flags:  append(stakingCommonFlags, stakingValidatorFlag, stakingValueFlag, stakingRenewalFlag),
+			},
+			{
+				Name:   "delegatein",
+				Usage:  "Delegate to a validator",
+				Action: utils.MigrateFlags(stakingAction("delegateIn")),
+				Flags:  append(stakingCommonFlags, stakingValidatorFlag, stakingValueFlag),
+			},
+			{
+				Name:   "delegateout",
+				Usage:  "Quit the delegation of a validator",
+				Action: utils.MigrateFlags(stakingAction("delegateOut")),
+				Flags:  append(stakingCommonFlags, stakingValidatorFlag),
+			},
+		},
+	}
+)
+
+// parseWan converts a decimal wan amount to wei.
+func parseWan(s string) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("invalid value %s", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(big.NewInt(params.Wan)))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("too many decimals in value %s", s)
+	}
+	return r.Num(), nil
+}
+
+func makeStakingTxArgs(ctx *cli.Context, method string) (*ethapi.StakingTxArgs, error) {
+	if !common.IsHexAddress(ctx.String(stakingFromFlag.Name)) {
+		return nil, errors.New("--from must be a valid address")
+	}
+	value, err := parseWan(ctx.String(stakingValueFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	args := &ethapi.StakingTxArgs{
+		From:       common.HexToAddress(ctx.String(stakingFromFlag.Name)),
+		Method:     method,
+		LockEpochs: hexutil.Uint64(ctx.Uint64(stakingLockEpochsFlag.Name)),
+		FeeRate:    hexutil.Uint64(ctx.Uint64(stakingFeeRateFlag.Name)),
+		Renewal:    ctx.Bool(stakingRenewalFlag.Name),
+		Value:      (*hexutil.Big)(value),
+	}
+	if v := ctx.String(stakingValidatorFlag.Name); v != "" {
+		if !common.IsHexAddress(v) {
+			return nil, errors.New("--validator must be a valid address")
+		}
+		validator := common.HexToAddress(v)
+		args.Validator = &validator
+	}
+	if ctx.IsSet(stakingMaxFeeRateFlag.Name) {
+		maxFeeRate := hexutil.Uint64(ctx.Uint64(stakingMaxFeeRateFlag.Name))
+		args.MaxFeeRate = &maxFeeRate
+	}
+	if pk := ctx.String(stakingSecPkFlag.Name); pk != "" {
+		if args.SecPk, err = hexutil.Decode(pk); err != nil {
+			return nil, err
+		}
+	}
+	if pk := ctx.String(stakingBn256PkFlag.Name); pk != "" {
+		if args.Bn256Pk, err = hexutil.Decode(pk); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+// stakingAction returns the action of the staking subcommand calling method.
+func stakingAction(method string) func(ctx *cli.Context) error {
+	return func(ctx *cli.Context) error {
+		args, err := makeStakingTxArgs(ctx, method)
+		if err != nil {
+			return err
+		}
+
+		client, err := dialRPC(ctx.String(stakingAttachFlag.Name))
+		if err != nil {
+			utils.Fatalf("Unable to attach to gwan node: %v", err)
+		}
+		defer client.Close()
+
+		if ctx.Bool(stakingUnsignedFlag.Name) {
+			var res ethapi.SignTransactionResult
+			if err := client.Call(&res, "pos_buildStakeTx", args); err != nil {
+				return err
+			}
+			fmt.Println(hexutil.Encode(res.Raw))
+			return nil
+		}
+
+		passwd := getPassPhrase("", false, 0, utils.MakePasswordList(ctx))
+		if ctx.Bool(stakingSignOnlyFlag.Name) {
+			var res ethapi.SignTransactionResult
+			if err := client.Call(&res, "personal_buildStakeTx", args, passwd); err != nil {
+				return err
+			}
+			fmt.Println(hexutil.Encode(res.Raw))
+			return nil
+		}
+
+		var hash common.Hash
+		if err := client.Call(&hash, "personal_sendStakeTx", args, passwd); err != nil {
+			return err
+		}
+		fmt.Println("Transaction sent:", hash.Hex())
+		return nil
+	}
+}
@@ -333,6 +333,11 @@ var (
 		Name:  "extradata",
 		Usage: "Block extra data set by the miner (default = client version)",
 	}
+	PosSignerFlag = cli.StringFlag{
+		Name:  "pos.signer",
+		Usage: "Endpoint of the remote signer holding the pos validator keys (see gwan pos signer)",
+		Value: "",
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -1091,6 +1096,10 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		cfg.PowTest = true
 	}
 
+	if ctx.GlobalIsSet(PosSignerFlag.Name) {
+		posconfig.RemoteSigner = ctx.GlobalString(PosSignerFlag.Name)
+	}
+
 	// TODO(fjl): move trie cache generations into config
 	if gen := ctx.GlobalInt(TrieCacheGenFlag.Name); gen > 0 {
 		state.MaxTrieCacheGen = uint16(gen)
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/wanchain/go-wanchain/pos/epochLeader"
+	"github.com/wanchain/go-wanchain/pos/equivocation"
 	"github.com/wanchain/go-wanchain/pos/util"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -47,6 +48,7 @@ import (
 	"github.com/wanchain/go-wanchain/params"
 	"github.com/wanchain/go-wanchain/pos/incentive"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/possigner"
 	"github.com/wanchain/go-wanchain/pos/slotleader"
 	posUtil "github.com/wanchain/go-wanchain/pos/util"
 	"github.com/wanchain/go-wanchain/rlp"
@@ -636,6 +638,10 @@ func (c *Pluto) verifySeal(chain consensus.ChainReader, header *types.Header, pa
 				if err != nil {
 					return err
 				}
+
+				// the signer is the proven slot leader, a different header of the
+				// same slot is an equivocation
+				equivocation.Check(header, epochID, slotID, signer)
 			}
 
 			log.Debug("end c *Pluto ValidateBody")
@@ -793,7 +799,7 @@ func (c *Pluto) Finalize(chain consensus.ChainReader, header *types.Header, stat
 }
 
 // Authorize injects a private key into the consensus engine to mint new blocks
-// with.
+// with. With a remote validator signer the key only carries the address.
 func (c *Pluto) Authorize(signer common.Address, signFn SignerFn, key *keystore.Key) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -838,7 +844,11 @@ func (c *Pluto) Seal(chain consensus.ChainReader, block *types.Block, stop <-cha
 	if epochSlotId <= lastEpochSlotId {
 		return nil, nil
 	}
-	localPublicKey := hex.EncodeToString(crypto.FromECDSAPub(&c.key.PrivateKey.PublicKey))
+	posSigner, err := possigner.ForKey(key)
+	if err != nil {
+		return nil, err
+	}
+	localPublicKey := hex.EncodeToString(crypto.FromECDSAPub(posSigner.PublicKey()))
 	leaderPub, err := slotleader.GetSlotLeaderSelection().GetSlotLeader(epochId, slotId)
 	if err != nil {
 		return nil, err
@@ -868,7 +878,7 @@ func (c *Pluto) Seal(chain consensus.ChainReader, block *types.Block, stop <-cha
 	header.Coinbase = signer
 
 	s := slotleader.GetSlotLeaderSelection()
-	buf, err := s.PackSlotProof(epochId, slotId, key.PrivateKey)
+	buf, err := s.PackSlotProof(epochId, slotId, posSigner)
 	if err != nil {
 		log.Warn("PackSlotProof failed in Seal", "epochID", epochId, "slotID", slotId, "error", err.Error())
 		return nil, err
@@ -888,7 +898,7 @@ func (c *Pluto) Seal(chain consensus.ChainReader, block *types.Block, stop <-cha
 
 	log.Debug("signature", "hex", hex.EncodeToString(sighash))
 	log.Debug("sigHash(header)", "Bytes", hex.EncodeToString(sigHash(header).Bytes()))
-	log.Debug("Packed slotleader proof info success", "epochID", epochId, "slotID", slotId, "len", len(header.Extra), "pk", hex.EncodeToString(crypto.FromECDSAPub(&key.PrivateKey.PublicKey)))
+	log.Debug("Packed slotleader proof info success", "epochID", epochId, "slotID", slotId, "len", len(header.Extra), "pk", localPublicKey)
 
 	err = c.verifySeal(nil, header, nil, false)
 	if err != nil {
@@ -43,6 +43,7 @@ import (
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/metrics"
 	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/pos/equivocation"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 	posUtil "github.com/wanchain/go-wanchain/pos/util"
 	"github.com/wanchain/go-wanchain/rlp"
@@ -1400,7 +1401,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 			return i, events, coalescedLogs, err
 		}
 		// Process block using the parent state as reference point.
-		receipts, logs, usedGas, err := bc.processor.Process(block, state, bc.vmConfig)
+		vmConfig := bc.vmConfig
+		vmConfig.RecordRBFailures = true
+		receipts, logs, usedGas, err := bc.processor.Process(block, state, vmConfig)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			return i, events, coalescedLogs, err
@@ -1418,6 +1421,13 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 				bc.reportBlock(block, receipts, err)
 				return i, events, coalescedLogs, err
 			}
+
+			// The sealer is the proven slot leader, a different block of the
+			// same slot, canonical or not, is an equivocation
+			if signer, err := bc.engine.Author(block.Header()); err == nil {
+				epochID, slotID := posUtil.GetEpochSlotIDFromDifficulty(block.Difficulty())
+				equivocation.Check(block.Header(), epochID, slotID, signer)
+			}
 		}
 
 		// Write the block to the chain and get the status.
@@ -62,7 +62,10 @@ var (
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
-	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	BloomBitsIndexPrefix    = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	StakeHistoryIndexPrefix = []byte("iS") // StakeHistoryIndexPrefix is the data table of the pos staking history indexer
+	OTAMixIndexPrefix       = []byte("iO") // OTAMixIndexPrefix is the data table of the OTA mix set indexer
+	OTAImageIndexPrefix     = []byte("iI") // OTAImageIndexPrefix is the data table of the OTA key image indexer
 
 	// used by old db, now only used for conversion
 	oldReceiptsPrefix = []byte("receipts-")
@@ -196,3 +196,14 @@ func TestSetupGenesis(t *testing.T) {
 		}
 	}
 }
+
+func TestSetupGenesisForkOrder(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	genesis := &Genesis{Config: &params.ChainConfig{ChainId: big.NewInt(6), IstanbulBlock: big.NewInt(0)}}
+	if _, _, err := SetupGenesisBlock(db, genesis); err == nil {
+		t.Fatal("istanbul fork accepted without the petersburg one")
+	}
+	if stored := GetCanonicalHash(db, 0); stored != (common.Hash{}) {
+		t.Fatal("genesis of a rejected config written", stored.Hex())
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package otaindex
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+var (
+	imagePrefix = []byte("i") // imagePrefix + key image -> rlp([]ImageSpend)
+
+	ErrImageIndexBehind = errors.New("OTA image index too far behind the chain head")
+
+	imageIndexer *core.ChainIndexer
+	imageDb      ethdb.Database
+	chainDb      ethdb.Database
+)
+
+// ImageSpend is the transaction revealing a key image, which spent an OTA.
+type ImageSpend struct {
+	Image       []byte
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+	TxIndex     uint64
+	Refund      bool     // refundCoin of the coin contract, else stamp of a privacy transaction
+	Value       *big.Int // refunded value, 0 for stamps
+}
+
+// ImageIndexer implements core.ChainIndexerBackend, mapping the key images revealed
+// by refunds and privacy transactions to the transactions revealing them.
+//
+// Entries of blocks reorganized away are kept and skipped when read.
+type ImageIndexer struct {
+	db      ethdb.Database // chain database to read blocks and receipts from
+	table   ethdb.Database // index table to write the spends into
+	section uint64
+
+	spends map[string][]ImageSpend
+}
+
+// NewImageIndexer returns a chain indexer that builds the key image index of the
+// canonical chain.
+func NewImageIndexer(db ethdb.Database) *core.ChainIndexer {
+	table := ethdb.NewTable(db, string(core.OTAImageIndexPrefix))
+	backend := &ImageIndexer{
+		db:    db,
+		table: table,
+	}
+	indexer := core.NewChainIndexer(db, table, backend, SectionSize, sectionConfirms, sectionThrottling, "otaimage")
+
+	mu.Lock()
+	imageIndexer, imageDb, chainDb = indexer, table, db
+	mu.Unlock()
+
+	return indexer
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new section.
+func (b *ImageIndexer) Reset(section uint64) {
+	b.section = section
+	b.spends = make(map[string][]ImageSpend)
+}
+
+// Process implements core.ChainIndexerBackend, collecting the key images of a block.
+func (b *ImageIndexer) Process(header *types.Header) {
+	for _, spend := range blockImageSpends(b.db, header.Hash(), header.Number.Uint64()) {
+		key := string(spend.Image)
+		b.spends[key] = append(b.spends[key], spend)
+	}
+}
+
+// Commit implements core.ChainIndexerBackend, writing the section out into the database.
+func (b *ImageIndexer) Commit() error {
+	batch := b.table.NewBatch()
+	for key, spends := range b.spends {
+		image := []byte(key)
+		for _, old := range readImageSpends(b.table, image) {
+			if !hasSpend(spends, old) {
+				spends = append(spends, old)
+			}
+		}
+		enc, err := rlp.EncodeToBytes(spends)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(imageKey(image), enc); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// blockImageSpends returns the key images revealed by the block. A privacy
+// transaction always spends its stamp, a refund only when it succeeded.
+func blockImageSpends(db ethdb.Database, hash common.Hash, number uint64) []ImageSpend {
+	body := core.GetBody(db, hash, number)
+	if body == nil {
+		log.Error("Missing block body of OTA image index", "number", number, "hash", hash)
+		return nil
+	}
+
+	var (
+		spends   []ImageSpend
+		receipts types.Receipts
+	)
+	for i, tx := range body.Transactions {
+		spend := ImageSpend{
+			BlockNumber: number,
+			BlockHash:   hash,
+			TxHash:      tx.Hash(),
+			TxIndex:     uint64(i),
+			Value:       new(big.Int),
+		}
+
+		var err error
+		if types.IsPrivacyTransaction(tx.Txtype()) {
+			if spend.Image, err = core.PrivacyTxKeyImage(tx.Data()); err != nil {
+				continue
+			}
+		} else if tx.To() != nil && *tx.To() == vm.GetWanCoinSCAddress() {
+			ringSignedData, value, err := vm.DecodeRefundCoinInput(tx.Data())
+			if err != nil {
+				continue
+			}
+			if receipts == nil {
+				receipts = core.GetBlockReceipts(db, hash, number)
+			}
+			if i >= len(receipts) || receipts[i].Status != types.ReceiptStatusSuccessful {
+				continue
+			}
+			if spend.Image, err = vm.RingSignKeyImage(ringSignedData); err != nil {
+				continue
+			}
+			spend.Refund, spend.Value = true, value
+		} else {
+			continue
+		}
+		spends = append(spends, spend)
+	}
+	return spends
+}
+
+func hasSpend(spends []ImageSpend, spend ImageSpend) bool {
+	for i := range spends {
+		if spends[i].BlockHash == spend.BlockHash && spends[i].TxHash == spend.TxHash {
+			return true
+		}
+	}
+	return false
+}
+
+func imageKey(image []byte) []byte {
+	return append(append([]byte{}, imagePrefix...), image...)
+}
+
+func readImageSpends(db ethdb.Database, image []byte) []ImageSpend {
+	data, _ := db.Get(imageKey(image))
+	if len(data) == 0 {
+		return nil
+	}
+	var spends []ImageSpend
+	if err := rlp.DecodeBytes(data, &spends); err != nil {
+		log.Error("Invalid OTA image index RLP", "image", common.ToHex(image), "err", err)
+		return nil
+	}
+	return spends
+}
+
+// GetImageSpend returns the canonical transaction revealing the key image, or nil
+// if it isn't revealed. The blocks not indexed yet are searched one by one, they
+// are at most a section and its confirmations unless the indexer is catching up,
+// ErrImageIndexBehind is returned then.
+func GetImageSpend(image []byte) (*ImageSpend, error) {
+	mu.RLock()
+	indexer, table, chain := imageIndexer, imageDb, chainDb
+	mu.RUnlock()
+	if indexer == nil {
+		return nil, ErrNotInitialized
+	}
+
+	for _, spend := range readImageSpends(table, image) {
+		// entries left over by a reorg of an already indexed section are skipped
+		if core.GetCanonicalHash(chain, spend.BlockNumber) == spend.BlockHash {
+			spend := spend
+			return &spend, nil
+		}
+	}
+
+	sections, _, _ := indexer.Sections()
+	headHash := core.GetHeadBlockHash(chain)
+	if headHash == (common.Hash{}) {
+		return nil, nil
+	}
+	head := core.GetBlockNumber(chain, headHash)
+	start := sections * SectionSize
+	if head >= start && head-start >= SectionSize+sectionConfirms {
+		return nil, ErrImageIndexBehind
+	}
+	for number := start; number <= head; number++ {
+		hash := core.GetCanonicalHash(chain, number)
+		if hash == (common.Hash{}) {
+			break
+		}
+		for _, spend := range blockImageSpends(chain, hash, number) {
+			if bytes.Equal(spend.Image, image) {
+				spend := spend
+				return &spend, nil
+			}
+		}
+	}
+	return nil, nil
+}
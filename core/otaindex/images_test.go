@@ -0,0 +1,98 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package otaindex
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/ethdb"
+)
+
+// privacyTx returns a privacy transaction spending the stamp of key image, its
+// ring signature isn't valid but only the key image is read.
+func privacyTx(t *testing.T, image []byte) *types.Transaction {
+	mix, _ := crypto.GenerateKey()
+	one := hexutil.EncodeBig(big.NewInt(1))
+	ringSignedData := strings.Join([]string{common.ToHex(crypto.FromECDSAPub(&mix.PublicKey)), common.ToHex(image), one, one}, "+")
+	data, err := core.TokenAbi.Pack("combine", ringSignedData, []byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.NewOTATransaction(0, common.Address{}, new(big.Int), big.NewInt(1000000), big.NewInt(1), data)
+}
+
+func writeCanonicalBlock(t *testing.T, db ethdb.Database, number uint64, extra []byte, txs []*types.Transaction) *types.Header {
+	header := &types.Header{Number: new(big.Int).SetUint64(number), Extra: extra}
+	hash := header.Hash()
+	if err := core.WriteHeader(db, header); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.WriteBody(db, hash, number, &types.Body{Transactions: txs}); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.WriteCanonicalHash(db, hash, number); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.WriteHeadBlockHash(db, hash); err != nil {
+		t.Fatal(err)
+	}
+	return header
+}
+
+func TestGetImageSpend(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	NewImageIndexer(db)
+	b := &ImageIndexer{db: db, table: imageDb}
+
+	key, _ := crypto.GenerateKey()
+	image := crypto.FromECDSAPub(&key.PublicKey)
+	tx := privacyTx(t, image)
+
+	writeCanonicalBlock(t, db, 0, nil, nil)
+	header := writeCanonicalBlock(t, db, 1, nil, []*types.Transaction{tx})
+
+	// the block isn't indexed yet, it's searched
+	spend, err := GetImageSpend(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spend == nil || spend.TxHash != tx.Hash() || spend.BlockHash != header.Hash() || spend.Refund {
+		t.Fatalf("wrong spend %v", spend)
+	}
+
+	b.Reset(0)
+	b.Process(header)
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if spends := readImageSpends(b.table, image); len(spends) != 1 || !bytes.Equal(spends[0].Image, image) {
+		t.Fatal("image not indexed", spends)
+	}
+	if spend, _ = GetImageSpend(image); spend == nil || spend.BlockNumber != 1 {
+		t.Fatal("indexed image not found")
+	}
+
+	// the spending block is reorganized away
+	writeCanonicalBlock(t, db, 1, []byte("reorg"), nil)
+	if spend, _ = GetImageSpend(image); spend != nil {
+		t.Fatal("spend of a reorganized block returned", spend)
+	}
+
+	// at most a section and its confirmations are searched
+	writeCanonicalBlock(t, db, SectionSize+sectionConfirms-1, nil, nil)
+	if _, err = GetImageSpend(image); err != nil {
+		t.Fatal("unindexed blocks not searched", err)
+	}
+	writeCanonicalBlock(t, db, SectionSize+sectionConfirms, nil, nil)
+	if _, err = GetImageSpend(image); err != ErrImageIndexBehind {
+		t.Fatal("too many unindexed blocks searched", err)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package otaindex maintains node local indexes of the privacy OTAs: the OTAs of
+// every value, so that mix sets can be sampled without traversing the OTA
+// storage, and the transactions revealing the key images of spent OTAs.
+package otaindex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+const (
+	// SectionSize is the number of blocks digested in one index section.
+	SectionSize = 128
+
+	// sectionConfirms is the number of confirmation blocks before a section is indexed.
+	sectionConfirms = 64
+
+	// sectionThrottling is the time to wait between processing two consecutive sections.
+	sectionThrottling = 100 * time.Millisecond
+)
+
+var (
+	countPrefix = []byte("c") // countPrefix + denomination -> number of OTAs (uint64 big endian)
+	entryPrefix = []byte("e") // entryPrefix + denomination + position (uint64 big endian) -> OTA wan address
+	startPrefix = []byte("s") // startPrefix + section (uint64 big endian) -> rlp([]denomCount) before the section
+
+	ErrNotInitialized = errors.New("OTA indexer is not running")
+	ErrNotEnoughOTAs  = errors.New("not enough indexed OTAs of the value")
+
+	mu      sync.RWMutex
+	indexDb ethdb.Database
+)
+
+// denomCount is the number of indexed OTAs of a denomination, which is the OTA
+// storage address of the value (vm.OTABalance2ContractAddr).
+type denomCount struct {
+	Denom common.Address
+	Count uint64
+}
+
+// Indexer implements core.ChainIndexerBackend, appending the OTAs added to the
+// state by every block to a position indexed table per value.
+//
+// The counts before every section are kept, a section reprocessed after a reorg
+// starts again from them and overwrites the OTAs of the abandoned blocks.
+type Indexer struct {
+	db      ethdb.Database // chain database to read headers from
+	states  state.Database // state database to read the OTAs from
+	table   ethdb.Database // index table to write the OTAs into
+	section uint64
+
+	counts map[common.Address]uint64   // counts before the section
+	added  map[common.Address][][]byte // OTAs found in the section
+}
+
+// NewIndexer returns a chain indexer that builds the OTA mix set index of the
+// canonical chain.
+func NewIndexer(db ethdb.Database) *core.ChainIndexer {
+	table := ethdb.NewTable(db, string(core.OTAMixIndexPrefix))
+	backend := &Indexer{
+		db:     db,
+		states: state.NewDatabase(db),
+		table:  table,
+	}
+
+	mu.Lock()
+	indexDb = table
+	mu.Unlock()
+
+	return core.NewChainIndexer(db, table, backend, SectionSize, sectionConfirms, sectionThrottling, "otamix")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new section.
+func (b *Indexer) Reset(section uint64) {
+	b.section = section
+	b.counts = make(map[common.Address]uint64)
+	b.added = make(map[common.Address][][]byte)
+	for _, dc := range readStartCounts(b.table, section) {
+		b.counts[dc.Denom] = dc.Count
+	}
+}
+
+// Process implements core.ChainIndexerBackend, collecting the OTAs of a block.
+// These are the OTAs of its state missing in the state of its parent, whether
+// bought by a transaction or by a contract calling the privacy coin or stamp
+// contract, and the OTAs of the genesis state.
+func (b *Indexer) Process(header *types.Header) {
+	number := header.Number.Uint64()
+	if number == 0 {
+		b.processGenesis(header)
+		return
+	}
+
+	parentHeader := core.GetHeader(b.db, header.ParentHash, number-1)
+	if parentHeader == nil {
+		log.Error("Missing parent header of OTA mix index", "number", number, "hash", header.Hash())
+		return
+	}
+	parent, err := state.New(parentHeader.Root, b.states)
+	if err != nil {
+		log.Error("Failed to open parent state of OTA mix index", "number", number, "err", err)
+		return
+	}
+	statedb, err := state.New(header.Root, b.states)
+	if err != nil {
+		log.Error("Failed to open state of OTA mix index", "number", number, "err", err)
+		return
+	}
+
+	err = vm.ForEachNewOTA(parent, statedb, func(ota []byte, balance *big.Int) bool {
+		b.add(balance, ota)
+		return true
+	})
+	if err != nil {
+		log.Error("Failed to read the new OTAs of OTA mix index", "number", number, "err", err)
+	}
+}
+
+func (b *Indexer) processGenesis(header *types.Header) {
+	statedb, err := state.New(header.Root, b.states)
+	if err != nil {
+		log.Error("Failed to open genesis state of OTA mix index", "err", err)
+		return
+	}
+	vm.ForEachOTA(statedb, func(ota []byte, balance *big.Int) bool {
+		b.add(balance, ota)
+		return true
+	})
+}
+
+func (b *Indexer) add(value *big.Int, ota []byte) {
+	denom := vm.OTABalance2ContractAddr(value)
+	b.added[denom] = append(b.added[denom], common.CopyBytes(ota))
+}
+
+// Commit implements core.ChainIndexerBackend, writing the section out into the database.
+func (b *Indexer) Commit() error {
+	batch := b.table.NewBatch()
+	for denom, otas := range b.added {
+		start := b.counts[denom]
+		for i, ota := range otas {
+			if err := batch.Put(entryKey(denom, start+uint64(i)), ota); err != nil {
+				return err
+			}
+		}
+		b.counts[denom] = start + uint64(len(otas))
+	}
+
+	// every count is written, also truncating the denominations of a reorg
+	counts := make([]denomCount, 0, len(b.counts))
+	for denom, count := range b.counts {
+		if err := batch.Put(countKey(denom), encodeUint64(count)); err != nil {
+			return err
+		}
+		counts = append(counts, denomCount{denom, count})
+	}
+	sort.Slice(counts, func(i, j int) bool { return bytes.Compare(counts[i].Denom[:], counts[j].Denom[:]) < 0 })
+
+	enc, err := rlp.EncodeToBytes(counts)
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(startKey(b.section+1), enc); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func countKey(denom common.Address) []byte {
+	return append(append([]byte{}, countPrefix...), denom[:]...)
+}
+
+func entryKey(denom common.Address, pos uint64) []byte {
+	key := make([]byte, 0, len(entryPrefix)+common.AddressLength+8)
+	key = append(key, entryPrefix...)
+	key = append(key, denom[:]...)
+	return append(key, encodeUint64(pos)...)
+}
+
+func startKey(section uint64) []byte {
+	return append(append([]byte{}, startPrefix...), encodeUint64(section)...)
+}
+
+func encodeUint64(n uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, n)
+	return enc
+}
+
+func readStartCounts(db ethdb.Database, section uint64) []denomCount {
+	data, _ := db.Get(startKey(section))
+	if len(data) == 0 {
+		return nil
+	}
+	var counts []denomCount
+	if err := rlp.DecodeBytes(data, &counts); err != nil {
+		log.Error("Invalid OTA mix index counts RLP", "section", section, "err", err)
+		return nil
+	}
+	return counts
+}
+
+func readCount(db ethdb.Database, denom common.Address) uint64 {
+	data, _ := db.Get(countKey(denom))
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// GetOTASet returns setNum distinct OTAs of the same value as the OTA of otaAX,
+// but not that OTA, picked uniformly at random among the indexed ones. Only the
+// OTAs existing in statedb are returned. Recent OTAs, not indexed yet, are never
+// picked.
+func GetOTASet(statedb vm.StateDB, otaAX []byte, setNum int) ([][]byte, *big.Int, error) {
+	mu.RLock()
+	db := indexDb
+	mu.RUnlock()
+	if db == nil {
+		return nil, nil, ErrNotInitialized
+	}
+	if setNum <= 0 {
+		return nil, nil, ErrNotEnoughOTAs
+	}
+
+	balance, err := vm.GetOtaBalanceFromAX(statedb, otaAX)
+	if err != nil {
+		return nil, nil, err
+	}
+	if balance.Sign() == 0 {
+		return nil, nil, vm.ErrOTABalanceIsZero
+	}
+
+	denom := vm.OTABalance2ContractAddr(balance)
+	count := readCount(db, denom)
+	if count <= uint64(setNum) {
+		return nil, balance, ErrNotEnoughOTAs
+	}
+
+	// partial Fisher-Yates shuffle of the positions, swapped keeps the moved ones
+	set := make([][]byte, 0, setNum)
+	swapped := make(map[uint64]uint64)
+	for i := uint64(0); i < count && len(set) < setNum; i++ {
+		j := i + uint64(rand.Int63n(int64(count-i)))
+		pos, ok := swapped[j]
+		if !ok {
+			pos = j
+		}
+		if moved, ok := swapped[i]; ok {
+			swapped[j] = moved
+		} else {
+			swapped[j] = i
+		}
+
+		ota, _ := db.Get(entryKey(denom, pos))
+		if len(ota) != common.WAddressLength || vm.IsAXPointToWanAddr(otaAX, ota) {
+			continue
+		}
+		// OTAs of reorganized blocks not reindexed yet aren't in the state
+		ax, _ := vm.GetAXFromWanAddr(ota)
+		stored, value, err := vm.GetOTAInfoFromAX(statedb, ax)
+		if err != nil || value.Cmp(balance) != 0 || !bytes.Equal(stored, ota) {
+			continue
+		}
+		set = append(set, ota)
+	}
+
+	if len(set) < setNum {
+		return nil, balance, ErrNotEnoughOTAs
+	}
+	return set, balance, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package otaindex
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+)
+
+var testValue = new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18))
+
+func randomOTA() []byte {
+	ota := make([]byte, common.WAddressLength)
+	rand.Read(ota)
+	return ota
+}
+
+func commitSection(t *testing.T, b *Indexer, section uint64, otas [][]byte) {
+	b.Reset(section)
+	for _, ota := range otas {
+		b.add(testValue, ota)
+	}
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetOTASet(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	b := &Indexer{db: db, states: state.NewDatabase(db), table: ethdb.NewTable(db, string(core.OTAMixIndexPrefix))}
+	mu.Lock()
+	indexDb = b.table
+	mu.Unlock()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	otas := make([][]byte, 20)
+	for i := range otas {
+		otas[i] = randomOTA()
+		if _, err := vm.AddOTAIfNotExist(statedb, testValue, otas[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commitSection(t, b, 0, otas[:10])
+	commitSection(t, b, 1, otas[10:])
+
+	denom := vm.OTABalance2ContractAddr(testValue)
+	if count := readCount(b.table, denom); count != 20 {
+		t.Fatal("wrong OTA count", count)
+	}
+
+	ax, _ := vm.GetAXFromWanAddr(otas[0])
+	set, balance, err := GetOTASet(statedb, ax, 19)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(testValue) != 0 || len(set) != 19 {
+		t.Fatal("wrong mix set", balance, len(set))
+	}
+	for i, ota := range set {
+		if bytes.Equal(ota, otas[0]) {
+			t.Fatal("mix set contains the OTA itself")
+		}
+		for _, other := range set[:i] {
+			if bytes.Equal(ota, other) {
+				t.Fatal("duplicated OTA in mix set")
+			}
+		}
+	}
+	if _, _, err := GetOTASet(statedb, ax, 20); err != ErrNotEnoughOTAs {
+		t.Fatal("mix set larger than the indexed OTAs", err)
+	}
+
+	// a reorg of section 1 truncates its OTAs, the ones not in the state are skipped
+	commitSection(t, b, 1, [][]byte{randomOTA(), randomOTA()})
+	if count := readCount(b.table, denom); count != 12 {
+		t.Fatal("wrong OTA count after reorg", count)
+	}
+	if _, _, err := GetOTASet(statedb, ax, 10); err != ErrNotEnoughOTAs {
+		t.Fatal("OTAs missing in the state picked", err)
+	}
+	if set, _, err = GetOTASet(statedb, ax, 9); err != nil || len(set) != 9 {
+		t.Fatal("wrong mix set after reorg", err)
+	}
+}
+
+func TestIndexerProcess(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	b := &Indexer{db: db, states: state.NewDatabase(db), table: ethdb.NewTable(db, string(core.OTAMixIndexPrefix))}
+
+	// The block adds OTAs to the state of its parent, whatever the transactions
+	old, bought := randomOTA(), [][]byte{randomOTA(), randomOTA()}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	vm.AddOTAIfNotExist(statedb, testValue, old)
+	root, _ := statedb.CommitTo(db, false)
+	genesis := &types.Header{Number: big.NewInt(0), Root: root}
+	if err := core.WriteHeader(db, genesis); err != nil {
+		t.Fatal(err)
+	}
+	statedb, _ = state.New(root, state.NewDatabase(db))
+	for _, ota := range bought {
+		vm.AddOTAIfNotExist(statedb, testValue, ota)
+	}
+	root, _ = statedb.CommitTo(db, false)
+	header := &types.Header{Number: big.NewInt(1), ParentHash: genesis.Hash(), Root: root}
+
+	b.Reset(0)
+	b.Process(genesis)
+	b.Process(header)
+	added := b.added[vm.OTABalance2ContractAddr(testValue)]
+	if len(added) != 3 || !bytes.Equal(added[0], old) {
+		t.Fatal("wrong indexed OTAs", len(added))
+	}
+	for _, ota := range bought {
+		if !bytes.Equal(added[1], ota) && !bytes.Equal(added[2], ota) {
+			t.Fatalf("bought OTA %x not indexed", ota)
+		}
+	}
+}
@@ -96,6 +96,7 @@ type stateObject struct {
 
 	cachedStorage Storage // Storage entry cache to avoid duplicate reads
 	dirtyStorage  Storage // Storage entries that need to be flushed to disk
+	originStorage Storage // Committed storage entries of the dirty ones, read for net gas metering
 
 	cachedStorageByteArray StorageByteArray
 	dirtyStorageByteArray  StorageByteArray
@@ -143,6 +144,7 @@ func newObject(db *StateDB, address common.Address, data Account, onDirty func(a
 		data:                   data,
 		cachedStorage:          make(Storage),
 		dirtyStorage:           make(Storage),
+		originStorage:          make(Storage),
 		cachedStorageByteArray: make(StorageByteArray),
 		dirtyStorageByteArray:  make(StorageByteArray),
 		onDirty:                onDirty,
@@ -201,6 +203,32 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 		return value
 	}
 	// Load from DB in case it is missing.
+	value = self.loadState(db, key)
+	if (value != common.Hash{}) {
+		self.cachedStorage[key] = value
+	}
+	return value
+}
+
+// GetCommittedState returns the value of account storage as committed by the
+// previous transaction, ignoring the modifications of the current one.
+func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.Hash {
+	if _, dirty := self.dirtyStorage[key]; !dirty {
+		return self.GetState(db, key)
+	}
+	value, exists := self.originStorage[key]
+	if exists {
+		return value
+	}
+	// The storage trie is only updated between transactions.
+	value = self.loadState(db, key)
+	self.originStorage[key] = value
+	return value
+}
+
+// loadState reads a value of account storage from the storage trie.
+func (self *stateObject) loadState(db Database, key common.Hash) common.Hash {
+	var value common.Hash
 	enc, err := self.getTrie(db).TryGet(key[:])
 	if err != nil {
 		self.setError(err)
@@ -213,9 +241,6 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 		}
 		value.SetBytes(content)
 	}
-	if (value != common.Hash{}) {
-		self.cachedStorage[key] = value
-	}
 	return value
 }
 
@@ -277,6 +302,7 @@ func (self *stateObject) updateTrie(db Database) Trie {
 	tr := self.getTrie(db)
 	for key, value := range self.dirtyStorage {
 		delete(self.dirtyStorage, key)
+		delete(self.originStorage, key)
 		if (value == common.Hash{}) {
 			self.setError(tr.TryDelete(key[:]))
 			continue
@@ -380,6 +406,7 @@ func (self *stateObject) deepCopy(db *StateDB, onDirty func(addr common.Address)
 	stateObject.code = self.code
 	stateObject.dirtyStorage = self.dirtyStorage.Copy()
 	stateObject.cachedStorage = self.dirtyStorage.Copy()
+	stateObject.originStorage = self.originStorage.Copy()
 	stateObject.dirtyStorageByteArray = self.dirtyStorageByteArray.Copy()
 	stateObject.cachedStorageByteArray = self.cachedStorageByteArray.Copy()
 	stateObject.suicided = self.suicided
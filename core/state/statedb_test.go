@@ -393,3 +393,42 @@ func (s *StateSuite) TestTouchDelete(c *check.C) {
 		c.Fatal("expected no dirty state object")
 	}
 }
+
+// Tests that the committed storage values are the ones of the previous
+// transaction, whatever the modifications and reverts of the current one.
+func TestCommittedState(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+
+	addr := common.BytesToAddress([]byte{1})
+	key := common.BytesToHash([]byte{1})
+	one, two := common.BytesToHash([]byte{1}), common.BytesToHash([]byte{2})
+
+	state.SetState(addr, key, one)
+	if have := state.GetCommittedState(addr, key); have != (common.Hash{}) {
+		t.Fatalf("committed value before finalise: have %x, want empty", have)
+	}
+	state.Finalise(true)
+	if have := state.GetCommittedState(addr, key); have != one {
+		t.Fatalf("committed value after finalise: have %x, want %x", have, one)
+	}
+
+	id := state.Snapshot()
+	state.SetState(addr, key, two)
+	if have := state.GetCommittedState(addr, key); have != one {
+		t.Fatalf("committed value of dirty slot: have %x, want %x", have, one)
+	}
+	state.RevertToSnapshot(id)
+	if have := state.GetState(addr, key); have != one {
+		t.Fatalf("current value after revert: have %x, want %x", have, one)
+	}
+
+	state.SetState(addr, key, two)
+	state.Finalise(true)
+	if have := state.GetCommittedState(addr, key); have != two {
+		t.Fatalf("committed value of next transaction: have %x, want %x", have, two)
+	}
+	if have := state.Copy().GetCommittedState(addr, key); have != two {
+		t.Fatalf("committed value of copy: have %x, want %x", have, two)
+	}
+}
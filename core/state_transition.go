@@ -87,10 +87,10 @@ type Message interface {
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message
-// with the given data.
+// with the given data. Istanbul reprices the non zero data bytes (EIP-2028).
 //
 // TODO convert to uint64
-func IntrinsicGas(data []byte, to *common.Address, homestead bool) *big.Int {
+func IntrinsicGas(data []byte, to *common.Address, homestead, istanbul bool) *big.Int {
 	contractCreation := to == nil
 
 	igas := new(big.Int)
@@ -106,8 +106,12 @@ func IntrinsicGas(data []byte, to *common.Address, homestead bool) *big.Int {
 				nz++
 			}
 		}
+		nonZeroGas := params.TxDataNonZeroGas
+		if istanbul {
+			nonZeroGas = params.TxDataNonZeroGasEIP2028
+		}
 		m := big.NewInt(nz)
-		m.Mul(m, new(big.Int).SetUint64(params.TxDataNonZeroGas))
+		m.Mul(m, new(big.Int).SetUint64(nonZeroGas))
 		igas.Add(igas, m)
 		m.SetInt64(int64(len(data)) - nz)
 		m.Mul(m, new(big.Int).SetUint64(params.TxDataZeroGas))
@@ -247,7 +251,8 @@ func (st *StateTransition) TransitionDb() (ret []byte, requiredGas, usedGas *big
 
 	// Pay intrinsic gas
 	// TODO convert to uint64
-	intrinsicGas := IntrinsicGas(st.data, msg.To(), true /*homestead*/)
+	istanbul := st.evm.ChainConfig().IsIstanbul(st.evm.BlockNumber)
+	intrinsicGas := IntrinsicGas(st.data, msg.To(), true /*homestead*/, istanbul)
 	//log.Trace("get intrinsic gas", "gas", intrinsicGas.String())
 	if intrinsicGas.BitLen() > 64 {
 		return nil, nil, nil, false, vm.ErrOutOfGas
@@ -375,6 +380,23 @@ type PrivacyTxInfo struct {
 	GasLeftSubRingSign uint64
 }
 
+// PrivacyTxKeyImage returns the key image of the stamp spent by the privacy
+// transaction payload in, without verifying its ring signature.
+func PrivacyTxKeyImage(in []byte) ([]byte, error) {
+	if len(in) < 4 {
+		return nil, vm.ErrInvalidRingSigned
+	}
+
+	var TxDataWithRing struct {
+		RingSignedData string
+		CxtCallParams  []byte
+	}
+	if err := utilAbi.Unpack(&TxDataWithRing, "combine", in[4:]); err != nil {
+		return nil, err
+	}
+	return vm.RingSignKeyImage(TxDataWithRing.RingSignedData)
+}
+
 func FetchPrivacyTxInfo(stateDB vm.StateDB, hashInput []byte, in []byte, gasPrice *big.Int) (info *PrivacyTxInfo, err error) {
 	if len(in) < 4 {
 		return nil, vm.ErrInvalidRingSigned
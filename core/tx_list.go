@@ -317,7 +317,7 @@ func (l *txList) Filter(costLimit, gasLimit *big.Int) (types.Transactions, types
 }
 
 // InvalidPrivacyTx remove invalidate privacy transactions
-func (l *txList) InvalidPrivacyTx(stateDB vm.StateDB, signer types.Signer, gasLimit *big.Int) types.Transactions {
+func (l *txList) InvalidPrivacyTx(stateDB vm.StateDB, signer types.Signer, gasLimit *big.Int, istanbul bool) types.Transactions {
 	removed := l.txs.Filter(func(tx *types.Transaction) bool {
 		if !types.IsPrivacyTransaction(tx.Txtype()) {
 			return false
@@ -328,7 +328,7 @@ func (l *txList) InvalidPrivacyTx(stateDB vm.StateDB, signer types.Signer, gasLi
 			return true
 		}
 
-		intrGas := IntrinsicGas(tx.Data(), tx.To(), true)
+		intrGas := IntrinsicGas(tx.Data(), tx.To(), true, istanbul)
 		err = ValidPrivacyTx(stateDB, from.Bytes(), tx.Data(), tx.GasPrice(), intrGas, tx.Value(), gasLimit)
 
 		return err != nil
@@ -209,6 +209,7 @@ type TxPool struct {
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
+	istanbul  bool // Whether the pending block is past the Istanbul fork
 }
 
 // NewTxPool creates a new transaction pool to gather, sort and filter inbound
@@ -410,6 +411,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.istanbul = pool.chainconfig.IsIstanbul(new(big.Int).Add(newHead.Number, big.NewInt(1)))
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -600,7 +602,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) (*common.Addre
 		return nil, ErrInsufficientFunds
 	}
 
-	intrGas := IntrinsicGas(tx.Data(), tx.To(), pool.homestead)
+	intrGas := IntrinsicGas(tx.Data(), tx.To(), pool.homestead, pool.istanbul)
 	if isNormalType || isPosType {
 		if tx.Gas().Cmp(intrGas) < 0 {
 			return nil, ErrIntrinsicGas
@@ -951,7 +953,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		}
 
 		// Remove all invalid privacy transactions
-		invalidPrivacy := list.InvalidPrivacyTx(pool.currentState, pool.signer, pool.currentMaxGas)
+		invalidPrivacy := list.InvalidPrivacyTx(pool.currentState, pool.signer, pool.currentMaxGas, pool.istanbul)
 		for _, tx := range invalidPrivacy {
 			hash := tx.Hash()
 			log.Trace("Removed invalid privacy transaction", "hash", hash)
@@ -1149,7 +1151,7 @@ func (pool *TxPool) demoteUnexecutables() {
 		}
 
 		// Remove all invalid privacy transactions
-		invalidPrivacy := list.InvalidPrivacyTx(pool.currentState, pool.signer, pool.currentMaxGas)
+		invalidPrivacy := list.InvalidPrivacyTx(pool.currentState, pool.signer, pool.currentMaxGas, pool.istanbul)
 		for _, tx := range invalidPrivacy {
 			hash := tx.Hash()
 			log.Trace("Removed invalid privacy transaction", "hash", hash)
@@ -19,6 +19,7 @@ package vm
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"math/big"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/wanchain/go-wanchain/common/math"
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/crypto/blake2b"
 	"github.com/wanchain/go-wanchain/crypto/bn256"
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/params"
@@ -384,6 +386,102 @@ func (c *bn256Pairing) ValidTx(stateDB StateDB, signer types.Signer, tx *types.T
 	return nil
 }
 
+// bn256AddIstanbul implements the elliptic curve point addition at the gas price
+// of the Istanbul fork (EIP-1108).
+type bn256AddIstanbul struct {
+	bn256Add
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *bn256AddIstanbul) RequiredGas(input []byte) uint64 {
+	return params.Bn256AddGasIstanbul
+}
+
+// bn256ScalarMulIstanbul implements the elliptic curve scalar multiplication at
+// the gas price of the Istanbul fork (EIP-1108).
+type bn256ScalarMulIstanbul struct {
+	bn256ScalarMul
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *bn256ScalarMulIstanbul) RequiredGas(input []byte) uint64 {
+	return params.Bn256ScalarMulGasIstanbul
+}
+
+// bn256PairingIstanbul implements the pairing check at the gas price of the
+// Istanbul fork (EIP-1108).
+type bn256PairingIstanbul struct {
+	bn256Pairing
+}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *bn256PairingIstanbul) RequiredGas(input []byte) uint64 {
+	return params.Bn256PairingBaseGasIstanbul + uint64(len(input)/192)*params.Bn256PairingPerPointGasIstanbul
+}
+
+const blake2FInputLength = 213
+
+var (
+	// errBlake2FInvalidInputLength is returned if the BLAKE2F input isn't 213 bytes.
+	errBlake2FInvalidInputLength = errors.New("invalid input length")
+
+	// errBlake2FInvalidFinalFlag is returned if the final block flag isn't 0 or 1.
+	errBlake2FInvalidFinalFlag = errors.New("invalid final flag")
+)
+
+// blake2F implements the BLAKE2b compression function F (EIP-152).
+//
+// The input is the rounds (4 bytes big endian), the state h (8 words), the block
+// m (16 words), the offset counter t (2 words), all words 8 bytes little endian,
+// and the final block flag (1 byte). It returns the new state h.
+type blake2F struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract,
+// charged per round.
+func (c *blake2F) RequiredGas(input []byte) uint64 {
+	if len(input) != blake2FInputLength {
+		return 0
+	}
+	return uint64(binary.BigEndian.Uint32(input[0:4])) * params.Blake2FRoundGas
+}
+
+func (c *blake2F) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	if len(input) != blake2FInputLength {
+		return nil, errBlake2FInvalidInputLength
+	}
+	if input[212] != 0 && input[212] != 1 {
+		return nil, errBlake2FInvalidFinalFlag
+	}
+
+	var (
+		rounds = binary.BigEndian.Uint32(input[0:4])
+		final  = input[212] == 1
+		h      [8]uint64
+		m      [16]uint64
+		t      [2]uint64
+	)
+	for i := range h {
+		h[i] = binary.LittleEndian.Uint64(input[4+i*8:])
+	}
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(input[68+i*8:])
+	}
+	t[0] = binary.LittleEndian.Uint64(input[196:204])
+	t[1] = binary.LittleEndian.Uint64(input[204:212])
+
+	blake2b.F(&h, m, t, final, rounds)
+
+	output := make([]byte, 64)
+	for i := range h {
+		binary.LittleEndian.PutUint64(output[i*8:], h[i])
+	}
+	return output, nil
+}
+
+func (c *blake2F) ValidTx(stateDB StateDB, signer types.Signer, tx *types.Transaction) error {
+	return nil
+}
+
 ///////////////////////for wan privacy tx /////////////////////////////////////////////////////////
 
 var (
@@ -772,6 +870,117 @@ func (c *wanCoinSC) ValidBuyCoinReq(stateDB StateDB, payload []byte, txValue *bi
 	return wanAddr, nil
 }
 
+// GetWanCoinSCAddress returns the address of the privacy coin contract.
+func GetWanCoinSCAddress() common.Address {
+	return wanCoinPrecompileAddr
+}
+
+// DecodeBuyCoinInput returns the OTA wan address and the value of a buyCoinNote
+// call input of the privacy coin contract.
+func DecodeBuyCoinInput(in []byte) (otaWanAddr []byte, value *big.Int, err error) {
+	if len(in) < 4 {
+		return nil, nil, errParameters
+	}
+	var methodIdArr [4]byte
+	copy(methodIdArr[:], in[:4])
+	if methodIdArr != buyIdArr {
+		return nil, nil, errMethodId
+	}
+
+	var outStruct struct {
+		OtaAddr string
+		Value   *big.Int
+	}
+	err = coinAbi.Unpack(&outStruct, "buyCoinNote", in[4:])
+	if err != nil || outStruct.Value == nil {
+		return nil, nil, errBuyCoin
+	}
+
+	otaWanAddr, err = hexutil.Decode(outStruct.OtaAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(otaWanAddr) != common.WAddressLength {
+		return nil, nil, ErrInvalidOTAAddr
+	}
+	return otaWanAddr, outStruct.Value, nil
+}
+
+// GetWanStampSCAddress returns the address of the privacy stamp contract.
+func GetWanStampSCAddress() common.Address {
+	return wanStampPrecompileAddr
+}
+
+// DecodeBuyStampInput returns the OTA wan address and the value of a buyStamp
+// call input of the privacy stamp contract.
+func DecodeBuyStampInput(in []byte) (otaWanAddr []byte, value *big.Int, err error) {
+	if len(in) < 4 {
+		return nil, nil, errParameters
+	}
+	var methodId [4]byte
+	copy(methodId[:], in[:4])
+	if methodId != stBuyId {
+		return nil, nil, errMethodId
+	}
+
+	var StampInput struct {
+		OtaAddr string
+		Value   *big.Int
+	}
+	err = stampAbi.UnpackTmp(&StampInput, "buyStamp", in[4:])
+	if err != nil || StampInput.Value == nil {
+		return nil, nil, errBuyStamp
+	}
+
+	otaWanAddr, err = hexutil.Decode(StampInput.OtaAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(otaWanAddr) != common.WAddressLength {
+		return nil, nil, ErrInvalidOTAAddr
+	}
+	return otaWanAddr, StampInput.Value, nil
+}
+
+// PackRefundCoinInput returns the refundCoin call input of the privacy coin
+// contract, refunding the OTA of value with the ring signed data.
+func PackRefundCoinInput(ringSignedData string, value *big.Int) ([]byte, error) {
+	return coinAbi.Pack("refundCoin", ringSignedData, value)
+}
+
+// DecodeRefundCoinInput returns the ring signed data and the value of a refundCoin
+// call input of the privacy coin contract.
+func DecodeRefundCoinInput(in []byte) (ringSignedData string, value *big.Int, err error) {
+	if len(in) < 4 {
+		return "", nil, errParameters
+	}
+	var methodIdArr [4]byte
+	copy(methodIdArr[:], in[:4])
+	if methodIdArr != refundIdArr {
+		return "", nil, errMethodId
+	}
+
+	var RefundStruct struct {
+		RingSignedData string
+		Value          *big.Int
+	}
+	err = coinAbi.Unpack(&RefundStruct, "refundCoin", in[4:])
+	if err != nil || RefundStruct.Value == nil {
+		return "", nil, errRefundCoin
+	}
+	return RefundStruct.RingSignedData, RefundStruct.Value, nil
+}
+
+// RingSignKeyImage returns the key image of the ring signed data, without
+// verifying the signature.
+func RingSignKeyImage(ringSignedStr string) ([]byte, error) {
+	err, _, keyImage, _, _ := DecodeRingSignOut(ringSignedStr)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.FromECDSAPub(keyImage), nil
+}
+
 func (c *wanCoinSC) buyCoin(in []byte, contract *Contract, evm *EVM) ([]byte, error) {
 	otaAddr, err := c.ValidBuyCoinReq(evm.StateDB, in, contract.value)
 	if err != nil {
@@ -0,0 +1,67 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"errors"
+
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto/bls"
+	"github.com/wanchain/go-wanchain/params"
+)
+
+const blsVerifyPairLen = bls.PublicKeyLength + 32 // public key and message hash of one signer
+
+var errBlsVerifyInput = errors.New("invalid bls aggregate verify input")
+
+// blsAggVerify implements a native contract verifying the BLS aggregate signatures
+// of crypto/bls.
+//
+// The input is the signature (64 bytes) and, for every signer, its public key (128
+// bytes) and the 32 bytes hash it signed. It returns the 32 bytes word 1 for a
+// valid signature and 0 otherwise. The contract registering the keys of signers of
+// the same hash must have them prove the ownership of their keys.
+type blsAggVerify struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract,
+// charged per signer.
+func (c *blsAggVerify) RequiredGas(input []byte) uint64 {
+	if len(input) < bls.SignatureLength {
+		return params.BlsVerifyBaseGas
+	}
+	n := uint64((len(input) - bls.SignatureLength) / blsVerifyPairLen)
+	return params.BlsVerifyBaseGas + n*params.BlsVerifyPerKeyGas
+}
+
+func (c *blsAggVerify) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	if len(input) < bls.SignatureLength+blsVerifyPairLen || (len(input)-bls.SignatureLength)%blsVerifyPairLen != 0 {
+		return nil, errBlsVerifyInput
+	}
+
+	sig, err := bls.UnmarshalSignature(input[:bls.SignatureLength])
+	if err != nil {
+		return nil, err
+	}
+
+	n := (len(input) - bls.SignatureLength) / blsVerifyPairLen
+	var (
+		pks  = make([]*bls.PublicKey, n)
+		msgs = make([][]byte, n)
+	)
+	for i := 0; i < n; i++ {
+		pair := input[bls.SignatureLength+i*blsVerifyPairLen:]
+		if pks[i], err = bls.UnmarshalPublicKey(pair[:bls.PublicKeyLength]); err != nil {
+			return nil, err
+		}
+		msgs[i] = pair[bls.PublicKeyLength:blsVerifyPairLen]
+	}
+
+	if bls.AggregateVerify(pks, msgs, sig) {
+		return true32Byte, nil
+	}
+	return false32Byte, nil
+}
+
+func (c *blsAggVerify) ValidTx(stateDB StateDB, signer types.Signer, tx *types.Transaction) error {
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/crypto/bls"
+	"github.com/wanchain/go-wanchain/params"
+)
+
+func TestBlsAggVerify(t *testing.T) {
+	var (
+		hashes = make([][]byte, 3)
+		sigs   = make([]*bls.Signature, 3)
+		pairs  []byte
+	)
+	for i := range hashes {
+		sk, _ := bls.GenerateKey(rand.Reader)
+		hashes[i] = crypto.Keccak256([]byte{byte(i)})
+		sigs[i] = sk.Sign(hashes[i])
+		pairs = append(pairs, sk.PublicKey().Marshal()...)
+		pairs = append(pairs, hashes[i]...)
+	}
+	input := append(bls.Aggregate(sigs).Marshal(), pairs...)
+
+	c := &blsAggVerify{}
+	if gas := c.RequiredGas(input); gas != params.BlsVerifyBaseGas+3*params.BlsVerifyPerKeyGas {
+		t.Fatal("wrong gas", gas)
+	}
+
+	ret, err := c.Run(input, nil, nil)
+	if err != nil || !bytes.Equal(ret, true32Byte) {
+		t.Fatal("valid aggregate signature rejected", ret, err)
+	}
+
+	input = append(bls.Aggregate(sigs[:2]).Marshal(), pairs...)
+	ret, err = c.Run(input, nil, nil)
+	if err != nil || !bytes.Equal(ret, false32Byte) {
+		t.Fatal("aggregate signature missing a signer accepted", ret, err)
+	}
+
+	if _, err := c.Run(input[:len(input)-1], nil, nil); err != errBlsVerifyInput {
+		t.Fatal("truncated input accepted", err)
+	}
+	if _, err := c.Run(input[:bls.SignatureLength], nil, nil); err != errBlsVerifyInput {
+		t.Fatal("input without signers accepted", err)
+	}
+}
+
+func TestBlsVerifyActivation(t *testing.T) {
+	config := &params.ChainConfig{RingSigVerifyBlock: big.NewInt(0), BlsVerifyBlock: big.NewInt(10)}
+	if _, ok := ActivePrecompiles(config, big.NewInt(9))[blsAggVerifyPrecompileAddr]; ok {
+		t.Fatal("bls aggregate verify active before the fork")
+	}
+	precompiles := ActivePrecompiles(config, big.NewInt(10))
+	if _, ok := precompiles[blsAggVerifyPrecompileAddr]; !ok {
+		t.Fatal("bls aggregate verify inactive at the fork")
+	}
+	if _, ok := precompiles[ringSigVerifyPrecompileAddr]; !ok {
+		t.Fatal("ring signature verify inactive after the bls fork")
+	}
+}
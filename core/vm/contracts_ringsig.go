@@ -0,0 +1,84 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/params"
+)
+
+const (
+	ringSigHeaderLen = 32 + 64      // message hash and key image
+	ringSigMemberLen = 64 + 32 + 32 // public key, c and r of one ring member
+)
+
+var errRingSigInput = errors.New("invalid ring signature input")
+
+// ringSigVerify implements a native contract verifying the ring signatures made
+// by crypto.RingSign, so contracts can check them.
+//
+// The input is the 32 bytes message hash, the key image (X || Y, 32 bytes each)
+// and, for every ring member, its public key (X || Y), c and r, all of them 32
+// bytes big endian words. It returns the 32 bytes word 1 for a valid signature
+// and 0 otherwise.
+type ringSigVerify struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract,
+// charged per ring member.
+func (c *ringSigVerify) RequiredGas(input []byte) uint64 {
+	if len(input) < ringSigHeaderLen {
+		return params.RingSigVerifyBaseGas
+	}
+	n := uint64((len(input) - ringSigHeaderLen) / ringSigMemberLen)
+	return params.RingSigVerifyBaseGas + n*params.RingSigVerifyPerPubGas
+}
+
+func (c *ringSigVerify) Run(input []byte, contract *Contract, evm *EVM) ([]byte, error) {
+	if len(input) < ringSigHeaderLen+ringSigMemberLen || (len(input)-ringSigHeaderLen)%ringSigMemberLen != 0 {
+		return nil, errRingSigInput
+	}
+
+	msg := input[:32]
+	image, err := ringSigPoint(input[32:96])
+	if err != nil {
+		return nil, err
+	}
+
+	n := (len(input) - ringSigHeaderLen) / ringSigMemberLen
+	var (
+		pubs = make([]*ecdsa.PublicKey, n)
+		cs   = make([]*big.Int, n)
+		rs   = make([]*big.Int, n)
+	)
+	for i := 0; i < n; i++ {
+		member := input[ringSigHeaderLen+i*ringSigMemberLen:]
+		if pubs[i], err = ringSigPoint(member[:64]); err != nil {
+			return nil, err
+		}
+		cs[i] = new(big.Int).SetBytes(member[64:96])
+		rs[i] = new(big.Int).SetBytes(member[96:128])
+	}
+
+	if crypto.VerifyRingSign(msg, pubs, image, cs, rs) {
+		return true32Byte, nil
+	}
+	return false32Byte, nil
+}
+
+func (c *ringSigVerify) ValidTx(stateDB StateDB, signer types.Signer, tx *types.Transaction) error {
+	return nil
+}
+
+// ringSigPoint returns the secp256k1 point X || Y, which must be on the curve.
+func ringSigPoint(in []byte) (*ecdsa.PublicKey, error) {
+	x, y := new(big.Int).SetBytes(in[:32]), new(big.Int).SetBytes(in[32:64])
+	if !crypto.S256().IsOnCurve(x, y) {
+		return nil, errRingSigInput
+	}
+	return &ecdsa.PublicKey{Curve: crypto.S256(), X: x, Y: y}, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common/math"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/params"
+)
+
+func packRingSig(msg []byte, pubs []*ecdsa.PublicKey, image *ecdsa.PublicKey, w, q []*big.Int) []byte {
+	input := append([]byte{}, msg...)
+	input = append(input, math.PaddedBigBytes(image.X, 32)...)
+	input = append(input, math.PaddedBigBytes(image.Y, 32)...)
+	for i := range pubs {
+		input = append(input, math.PaddedBigBytes(pubs[i].X, 32)...)
+		input = append(input, math.PaddedBigBytes(pubs[i].Y, 32)...)
+		input = append(input, math.PaddedBigBytes(w[i], 32)...)
+		input = append(input, math.PaddedBigBytes(q[i], 32)...)
+	}
+	return input
+}
+
+func TestRingSigVerify(t *testing.T) {
+	pubs := make([]*ecdsa.PublicKey, 3)
+	var x *ecdsa.PrivateKey
+	for i := range pubs {
+		sk, _ := crypto.GenerateKey()
+		// the key image is computed from the first key, the signer's
+		if i == 0 {
+			x = sk
+		}
+		pubs[i] = &sk.PublicKey
+	}
+
+	msg := crypto.Keccak256([]byte("message"))
+	retPubs, image, w, q, err := crypto.RingSign(msg, x.D, pubs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := packRingSig(msg, retPubs, image, w, q)
+
+	c := &ringSigVerify{}
+	if gas := c.RequiredGas(input); gas != params.RingSigVerifyBaseGas+3*params.RingSigVerifyPerPubGas {
+		t.Fatal("wrong gas", gas)
+	}
+
+	ret, err := c.Run(input, nil, nil)
+	if err != nil || !bytes.Equal(ret, true32Byte) {
+		t.Fatal("valid ring signature rejected", ret, err)
+	}
+
+	other := crypto.Keccak256([]byte("other message"))
+	ret, err = c.Run(packRingSig(other, retPubs, image, w, q), nil, nil)
+	if err != nil || !bytes.Equal(ret, false32Byte) {
+		t.Fatal("ring signature of another message accepted", ret, err)
+	}
+
+	if _, err := c.Run(input[:len(input)-1], nil, nil); err != errRingSigInput {
+		t.Fatal("truncated input accepted", err)
+	}
+	if _, err := c.Run(input[:ringSigHeaderLen], nil, nil); err != errRingSigInput {
+		t.Fatal("empty ring accepted", err)
+	}
+
+	// a key image not on the curve
+	bad := append([]byte{}, input...)
+	bad[95] ^= 1
+	if _, err := c.Run(bad, nil, nil); err != errRingSigInput {
+		t.Fatal("key image off the curve accepted", err)
+	}
+}
+
+func TestActivePrecompiles(t *testing.T) {
+	config := &params.ChainConfig{RingSigVerifyBlock: big.NewInt(10)}
+	if _, ok := ActivePrecompiles(config, big.NewInt(9))[ringSigVerifyPrecompileAddr]; ok {
+		t.Fatal("ring signature verify active before the fork")
+	}
+	if _, ok := ActivePrecompiles(config, big.NewInt(10))[ringSigVerifyPrecompileAddr]; !ok {
+		t.Fatal("ring signature verify inactive at the fork")
+	}
+	if _, ok := PrecompiledContractsByzantium[ringSigVerifyPrecompileAddr]; ok {
+		t.Fatal("ring signature verify added to the byzantium set")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/params"
+)
+
+func TestBlake2F(t *testing.T) {
+	// vector 5 of EIP-152, BLAKE2b-512("abc") in 12 rounds
+	input := common.Hex2Bytes("0000000c48c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000001")
+	want := common.Hex2Bytes("ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923")
+
+	c := &blake2F{}
+	if gas := c.RequiredGas(input); gas != 12*params.Blake2FRoundGas {
+		t.Fatal("wrong gas", gas)
+	}
+	ret, err := c.Run(input, nil, nil)
+	if err != nil || !bytes.Equal(ret, want) {
+		t.Fatalf("wrong output: have %x, want %x, err %v", ret, want, err)
+	}
+
+	if _, err := c.Run(input[:len(input)-1], nil, nil); err != errBlake2FInvalidInputLength {
+		t.Fatal("truncated input accepted", err)
+	}
+	bad := append([]byte{}, input...)
+	bad[len(bad)-1] = 2
+	if _, err := c.Run(bad, nil, nil); err != errBlake2FInvalidFinalFlag {
+		t.Fatal("invalid final flag accepted", err)
+	}
+}
+
+func TestIstanbulPrecompiles(t *testing.T) {
+	config := &params.ChainConfig{BlsVerifyBlock: big.NewInt(0), IstanbulBlock: big.NewInt(10)}
+	before := ActivePrecompiles(config, big.NewInt(9))
+	if _, ok := before[blake2FPrecompileAddr]; ok {
+		t.Fatal("blake2f active before the istanbul fork")
+	}
+	if gas := before[bn256AddPrecompileAddr].RequiredGas(nil); gas != params.Bn256AddGas {
+		t.Fatal("bn256 add repriced before the istanbul fork", gas)
+	}
+
+	after := ActivePrecompiles(config, big.NewInt(10))
+	if _, ok := after[blake2FPrecompileAddr]; !ok {
+		t.Fatal("blake2f inactive at the istanbul fork")
+	}
+	if gas := after[bn256AddPrecompileAddr].RequiredGas(nil); gas != params.Bn256AddGasIstanbul {
+		t.Fatal("bn256 add not repriced at the istanbul fork", gas)
+	}
+	if gas := after[bn256PairingPrecompileAddr].RequiredGas(make([]byte, 2*192)); gas != params.Bn256PairingBaseGasIstanbul+2*params.Bn256PairingPerPointGasIstanbul {
+		t.Fatal("bn256 pairing not repriced at the istanbul fork", gas)
+	}
+
+	// the forks are independent of each other
+	if _, ok := after[blsAggVerifyPrecompileAddr]; !ok {
+		t.Fatal("bls aggregate verify inactive after the istanbul fork")
+	}
+	if _, ok := after[ringSigVerifyPrecompileAddr]; ok {
+		t.Fatal("ring signature verify active without its fork")
+	}
+	if _, ok := ActivePrecompiles(&params.ChainConfig{IstanbulBlock: big.NewInt(0)}, big.NewInt(0))[blsAggVerifyPrecompileAddr]; ok {
+		t.Fatal("bls aggregate verify active without its fork")
+	}
+	if _, ok := PrecompiledContractsByzantium[blake2FPrecompileAddr]; ok {
+		t.Fatal("byzantium set extended by the istanbul fork")
+	}
+}
@@ -29,7 +29,7 @@ import (
 
 // emptyCodeHash is used by create to ensure deployment is disallowed to already
 // deployed contract addresses (relevant after the account abstraction).
-//var emptyCodeHash = crypto.Keccak256Hash(nil)
+var emptyCodeHash = crypto.Keccak256Hash(nil)
 
 type (
 	CanTransferFunc func(StateDB, common.Address, *big.Int) bool
@@ -45,7 +45,7 @@ func run(evm *EVM, snapshot int, contract *Contract, input []byte) ([]byte, erro
 		//precompiles := PrecompiledContractsHomestead
 
 		//if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
-		precompiles := PrecompiledContractsByzantium
+		precompiles := ActivePrecompiles(evm.ChainConfig(), evm.BlockNumber)
 		//}
 
 		if p := precompiles[*contract.CodeAddr]; p != nil {
@@ -139,6 +139,10 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	if tracer, ok := evm.callTracer(); ok {
+		tracer.CaptureEnter(evm, CALL, caller.Address(), addr, input, gas, value)
+		defer func() { tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
@@ -160,7 +164,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		//precompiles = PrecompiledContractsHomestead
 		//if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
 
-		precompiles = PrecompiledContractsByzantium
+		precompiles = ActivePrecompiles(evm.ChainConfig(), evm.BlockNumber)
 
 		//}
 
@@ -205,6 +209,10 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	if tracer, ok := evm.callTracer(); ok {
+		tracer.CaptureEnter(evm, CALLCODE, caller.Address(), addr, input, gas, value)
+		defer func() { tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
@@ -244,6 +252,10 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	if tracer, ok := evm.callTracer(); ok {
+		tracer.CaptureEnter(evm, DELEGATECALL, caller.Address(), addr, input, gas, nil)
+		defer func() { tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -276,6 +288,10 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	if tracer, ok := evm.callTracer(); ok {
+		tracer.CaptureEnter(evm, STATICCALL, caller.Address(), addr, input, gas, nil)
+		defer func() { tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -313,7 +329,26 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 
 // Create creates a new contract using code as deployment code.
 func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	contractAddr = crypto.CreateAddress(caller.Address(), evm.StateDB.GetNonce(caller.Address()))
+	return evm.create(caller, code, gas, value, contractAddr, CREATE)
+}
+
+// Create2 creates a new contract using code as deployment code.
+//
+// The different between Create2 with Create is Create2 uses sha3(0xff ++ msg.sender ++ salt ++ sha3(init_code))[12:]
+// instead of the usual sender-and-nonce-hash as the address where the contract is initialized at.
+func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *big.Int, salt *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), crypto.Keccak256(code))
+	return evm.create(caller, code, gas, endowment, contractAddr, CREATE2)
+}
 
+// create creates a new contract at the address using code as deployment code, typ
+// is the CREATE or CREATE2 op code of the creation.
+func (evm *EVM) create(caller ContractRef, code []byte, gas uint64, value *big.Int, contractAddr common.Address, typ OpCode) (ret []byte, addr common.Address, leftOverGas uint64, err error) {
+	if tracer, ok := evm.callTracer(); ok {
+		tracer.CaptureEnter(evm, typ, caller.Address(), contractAddr, code, gas, value)
+		defer func() { tracer.CaptureExit(evm, ret, gas-leftOverGas, err) }()
+	}
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
 	if evm.depth > int(params.CallCreateDepth) {
@@ -326,8 +361,7 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	nonce := evm.StateDB.GetNonce(caller.Address())
 	evm.StateDB.SetNonce(caller.Address(), nonce+1)
 
-	contractAddr = crypto.CreateAddress(caller.Address(), nonce)
-	if !evm.StateDB.Empty(contractAddr) {
+	if evm.collides(contractAddr, typ) {
 		return nil, common.Address{}, 0, ErrContractAddressCollision
 	}
 	// Create a new account on the state
@@ -381,6 +415,26 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	return ret, contractAddr, contract.Gas, err
 }
 
+// collides reports whether a contract can't be created at the address. The
+// address of CREATE2 is known before the deployment and may be funded already,
+// so only a nonce or a code makes it collide, as in EIP-684.
+func (evm *EVM) collides(contractAddr common.Address, typ OpCode) bool {
+	if typ != CREATE2 {
+		return !evm.StateDB.Empty(contractAddr)
+	}
+	codeHash := evm.StateDB.GetCodeHash(contractAddr)
+	return evm.StateDB.GetNonce(contractAddr) != 0 || (codeHash != (common.Hash{}) && codeHash != emptyCodeHash)
+}
+
+// callTracer returns the tracer of the vm config if it follows the call frames.
+func (evm *EVM) callTracer() (CallTracer, bool) {
+	if !evm.vmConfig.Debug {
+		return nil, false
+	}
+	tracer, ok := evm.vmConfig.Tracer.(CallTracer)
+	return tracer, ok
+}
+
 // ChainConfig returns the evmironment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
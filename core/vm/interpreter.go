@@ -43,6 +43,9 @@ type Config struct {
 	DisableGasMetering bool
 	// Enable recording of SHA3/keccak preimages
 	EnablePreimageRecording bool
+	// RecordRBFailures keeps the rejected random beacon payloads, it's only
+	// enabled when processing the blocks, not by the calls and gas estimations
+	RecordRBFailures bool
 	// JumpTable contains the EVM instruction table. This
 	// may be left uninitialised and will be set to the default
 	// table.
@@ -70,6 +73,11 @@ func NewInterpreter(evm *EVM, cfg Config) *Interpreter {
 	// we'll set the default jump table.
 	if !cfg.JumpTable[STOP].valid {
 		switch {
+		case evm.ChainConfig().IsIstanbul(evm.BlockNumber):
+			cfg.JumpTable = istanbulInstructionSet
+
+		case evm.ChainConfig().IsPetersburg(evm.BlockNumber):
+			cfg.JumpTable = petersburgInstructionSet
 
 		//case evm.ChainConfig().IsByzantium(evm.BlockNumber):
 		//	cfg.JumpTable = byzantiumInstructionSet
@@ -89,6 +89,18 @@ type Tracer interface {
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error
 }
 
+// CallTracer is a Tracer also following the call frames of the execution, the
+// frames of the pre-compiled contracts included, which don't run any op code.
+type CallTracer interface {
+	Tracer
+	// CaptureEnter is called when a frame of the type CALL, CALLCODE, DELEGATECALL,
+	// STATICCALL, CREATE, CREATE2 or SELFDESTRUCT is entered, before any value
+	// is transferred.
+	CaptureEnter(env *EVM, typ OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int)
+	// CaptureExit is called when the last entered frame returns.
+	CaptureExit(env *EVM, output []byte, gasUsed uint64, err error)
+}
+
 // StructLogger is an EVM state logger and implements Tracer.
 //
 // StructLogger can capture state based on the given Log configuration and also keeps
@@ -12,8 +12,10 @@ import (
 	"strconv"
 
 	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/state"
 	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/trie"
 
 )
 
@@ -223,6 +225,72 @@ func GetUnspendOTATotalBalance(statedb StateDB) (*big.Int, error) {
 	return totalOTABalance.Sub(totalOTABalance, totalSpendedOTABalance), nil
 }
 
+// ForEachOTA calls fn with the wan address and the balance of every stored OTA,
+// spent or not, until fn returns false.
+func ForEachOTA(statedb StateDB, fn func(otaWanAddr []byte, balance *big.Int) bool) error {
+	if statedb == nil || fn == nil {
+		return ErrUnknown
+	}
+
+	statedb.ForEachStorageByteArray(otaBalanceStorageAddr, func(key common.Hash, value []byte) bool {
+		if len(value) == 0 {
+			return true
+		}
+
+		balance := new(big.Int).SetBytes(value)
+		otaWanAddr := statedb.GetStateByteArray(OTABalance2ContractAddr(balance), key)
+		if len(otaWanAddr) != common.WAddressLength {
+			log.Warn("ota traversal. wan address not found!", "key", key.String(), "balance", balance.String())
+			return true
+		}
+
+		return fn(otaWanAddr, balance)
+	})
+
+	return nil
+}
+
+// ForEachNewOTA calls fn with the wan address and the balance of every OTA stored
+// in statedb but not in parent, until fn returns false. With the states of a
+// block and of its parent, these are the OTAs bought in the block, whether by a
+// transaction or by a contract calling the privacy coin or stamp contract.
+func ForEachNewOTA(parent, statedb *state.StateDB, fn func(otaWanAddr []byte, balance *big.Int) bool) error {
+	if parent == nil || statedb == nil || fn == nil {
+		return ErrUnknown
+	}
+
+	tr := statedb.StorageTrie(otaBalanceStorageAddr)
+	if tr == nil {
+		return nil
+	}
+	nodes := tr.NodeIterator(nil)
+	if parentTr := parent.StorageTrie(otaBalanceStorageAddr); parentTr != nil {
+		nodes, _ = trie.NewDifferenceIterator(parentTr.NodeIterator(nil), nodes)
+	}
+
+	it := trie.NewIterator(nodes)
+	for it.Next() {
+		otaAX := tr.GetKey(it.Key)
+		if len(otaAX) != common.HashLength || len(it.Value) == 0 {
+			continue
+		}
+		if exist, _, err := CheckOTAAXExist(parent, otaAX); err != nil || exist {
+			continue
+		}
+
+		balance := new(big.Int).SetBytes(it.Value)
+		otaWanAddr := statedb.GetStateByteArray(OTABalance2ContractAddr(balance), common.BytesToHash(otaAX))
+		if len(otaWanAddr) != common.WAddressLength {
+			log.Warn("new ota traversal. wan address not found!", "ax", common.ToHex(otaAX), "balance", balance.String())
+			continue
+		}
+		if !fn(otaWanAddr, balance) {
+			return nil
+		}
+	}
+	return it.Err
+}
+
 // setOTA storage ota info, include balance and WanAddr. Overwrite if ota exist already.
 func setOTA(statedb StateDB, balance *big.Int, otaWanAddr []byte) error {
 	if statedb == nil || balance == nil {
@@ -424,6 +424,56 @@ func TestAddOTAIfNotExist(t *testing.T) {
 	}
 }
 
+func TestForEachNewOTA(t *testing.T) {
+	var (
+		db, _      = ethdb.NewMemDatabase()
+		parent, _  = state.New(common.Hash{}, state.NewDatabase(db))
+		otaAddr    = func(i int) []byte { return common.FromHex(otaShortAddrs[i]) }
+		balanceSet = big.NewInt(10)
+	)
+	for i := 0; i < 2; i++ {
+		if _, err := AddOTAIfNotExist(parent, balanceSet, otaAddr(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, err := parent.CommitTo(db, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, _ = state.New(root, state.NewDatabase(db))
+
+	statedb, _ := state.New(root, state.NewDatabase(db))
+	AddOTAIfNotExist(statedb, balanceSet, otaAddr(0))
+	AddOTAIfNotExist(statedb, balanceSet, otaAddr(2))
+	AddOTAIfNotExist(statedb, big.NewInt(20), otaAddr(3))
+	root, err = statedb.CommitTo(db, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb, _ = state.New(root, state.NewDatabase(db))
+
+	found := make(map[string]*big.Int)
+	err = ForEachNewOTA(parent, statedb, func(ota []byte, balance *big.Int) bool {
+		found[common.ToHex(ota)] = balance
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[otaShortAddrs[2]].Cmp(balanceSet) != 0 || found[otaShortAddrs[3]].Cmp(big.NewInt(20)) != 0 {
+		t.Fatal("wrong new OTAs", found)
+	}
+
+	count := 0
+	ForEachNewOTA(statedb, statedb, func(ota []byte, balance *big.Int) bool {
+		count++
+		return true
+	})
+	if count != 0 {
+		t.Fatal("OTAs of the parent found as new", count)
+	}
+}
+
 func TestSetOtaBalanceToAX(t *testing.T) {
 	{
 		err := SetOtaBalanceToAX(nil, make([]byte, common.HashLength), big1)
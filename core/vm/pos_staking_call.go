@@ -0,0 +1,103 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+// StakingCall describes one call of the pos staking contract. Only the fields
+// used by Method need to be set.
+type StakingCall struct {
+	Method     string
+	Validator  common.Address // the validator (pos address) the call operates on
+	SecPk      []byte
+	Bn256Pk    []byte
+	LockEpochs uint64
+	FeeRate    uint64
+	MaxFeeRate uint64
+	Renewal    bool
+}
+
+// PackStakingCall encodes the call data of a staking contract call.
+func PackStakingCall(call *StakingCall) ([]byte, error) {
+	lockEpochs := new(big.Int).SetUint64(call.LockEpochs)
+	feeRate := new(big.Int).SetUint64(call.FeeRate)
+
+	switch call.Method {
+	case "stakeIn":
+		return cscAbi.Pack(call.Method, call.SecPk, call.Bn256Pk, lockEpochs, feeRate)
+	case "stakeRegister":
+		return cscAbi.Pack(call.Method, call.SecPk, call.Bn256Pk, lockEpochs, feeRate, new(big.Int).SetUint64(call.MaxFeeRate))
+	case "stakeAppend", "delegateIn", "delegateOut":
+		return cscAbi.Pack(call.Method, call.Validator)
+	case "stakeUpdate":
+		return cscAbi.Pack(call.Method, call.Validator, lockEpochs)
+	case "stakeUpdateFeeRate":
+		return cscAbi.Pack(call.Method, call.Validator, feeRate)
+	case "partnerIn":
+		return cscAbi.Pack(call.Method, call.Validator, call.Renewal)
+	}
+	return nil, errMethodId
+}
+
+// StakingCallMethod returns the staking contract method called by input.
+func StakingCallMethod(input []byte) (string, error) {
+	if len(input) < 4 {
+		return "", errors.New("parameter is too short")
+	}
+	method, err := cscAbi.MethodById(input[:4])
+	if err != nil {
+		return "", errMethodId
+	}
+	return method.Name, nil
+}
+
+// StakingCallValidator returns the validator (pos address) operated on by input.
+// For stakeIn and stakeRegister it is the address of the secPk.
+func StakingCallValidator(input []byte) (common.Address, error) {
+	p := &PosStaking{}
+	if err := p.validInput(input); err != nil {
+		return common.Address{}, err
+	}
+
+	var methodId [4]byte
+	copy(methodId[:], input[:4])
+	payload := input[4:]
+
+	switch methodId {
+	case stakeInId:
+		info, _ := p.stakeInParseAndValid(payload)
+		return crypto.PubkeyToAddress(*info.pub), nil
+	case stakeRegisterId:
+		info, _ := p.stakeRegisterParseAndValid(payload)
+		return crypto.PubkeyToAddress(*info.pub), nil
+	case stakeAppendId:
+		return p.stakeAppendParseAndValid(payload)
+	case stakeUpdateId:
+		info, err := p.stakeUpdateParseAndValid(payload)
+		return info.Addr, err
+	case partnerInId:
+		info, err := p.partnerInParseAndValid(payload)
+		return info.Addr, err
+	case delegateInId:
+		return p.delegateInParseAndValid(payload)
+	case delegateOutId:
+		return p.delegateOutParseAndValid(payload)
+	case stakeUpdateFeeRateId:
+		info, err := p.updateFeeRateParseAndValid(payload)
+		if err != nil {
+			return common.Address{}, err
+		}
+		return info.Addr, nil
+	}
+	return common.Address{}, errParameters
+}
+
+// ValidStakingInput checks the call data of a staking contract call without
+// touching the state, the same way as ValidTx.
+func ValidStakingInput(input []byte) error {
+	return (&PosStaking{}).validInput(input)
+}
@@ -0,0 +1,39 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+)
+
+func TestPackStakingCall(t *testing.T) {
+	param := getStakeInParam()
+	validator := common.HexToAddress("0x2d0e7c0813a51d3bd1d08246af2a8a7a57d8922e")
+	calls := []*StakingCall{
+		{Method: "stakeIn", SecPk: param.SecPk, Bn256Pk: param.Bn256Pk, LockEpochs: 10, FeeRate: 100},
+		{Method: "stakeAppend", Validator: validator},
+		{Method: "stakeUpdate", Validator: validator, LockEpochs: 10},
+		{Method: "partnerIn", Validator: validator, Renewal: true},
+		{Method: "delegateIn", Validator: validator},
+		{Method: "delegateOut", Validator: validator},
+		{Method: "stakeUpdateFeeRate", Validator: validator, FeeRate: 200},
+	}
+	p := &PosStaking{}
+	for _, call := range calls {
+		input, err := PackStakingCall(call)
+		if err != nil {
+			t.Fatal(call.Method, err)
+		}
+		if err := p.validInput(input); err != nil {
+			t.Fatal(call.Method, err)
+		}
+	}
+
+	if _, err := PackStakingCall(&StakingCall{Method: "stakeOut"}); err == nil {
+		t.Fatal("unknown method should fail")
+	}
+	input, _ := PackStakingCall(&StakingCall{Method: "stakeIn", SecPk: param.SecPk, Bn256Pk: param.Bn256Pk, LockEpochs: 1, FeeRate: 100})
+	if err := p.validInput(input); err == nil {
+		t.Fatal("lock epochs too small should fail")
+	}
+}
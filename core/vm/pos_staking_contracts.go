@@ -614,7 +614,11 @@ func (p *PosStaking) Run(input []byte, contract *Contract, evm *EVM) ([]byte, er
 }
 
 func (p *PosStaking) ValidTx(stateDB StateDB, signer types.Signer, tx *types.Transaction) error {
-	input := tx.Data()
+	return p.validInput(tx.Data())
+}
+
+// validInput checks the call data of a staking transaction without touching the state.
+func (p *PosStaking) validInput(input []byte) error {
 	if len(input) < 4 {
 		return errors.New("parameter is too short")
 	}
@@ -699,8 +703,13 @@ func (p *PosStaking) saveStakeInfo(evm *EVM, stakerInfo *StakerInfo) error {
 	return nil
 }
 func (p *PosStaking) getStakeInfo(evm *EVM, addr common.Address) (*StakerInfo, error) {
+	return GetStakerInfo(evm.StateDB, addr)
+}
+
+// GetStakerInfo reads the staker info of the validator addr from the state.
+func GetStakerInfo(stateDB StateDB, addr common.Address) (*StakerInfo, error) {
 	key := GetStakeInKeyHash(addr)
-	stakerBytes, err := GetInfo(evm.StateDB, StakersInfoAddr, key)
+	stakerBytes, err := GetInfo(stateDB, StakersInfoAddr, key)
 	if stakerBytes == nil {
 		return nil, errors.New("item doesn't exist")
 	}
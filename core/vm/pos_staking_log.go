@@ -0,0 +1,168 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+// StakingLog is the decoded content of a log emitted by the pos staking contract.
+// Fields which are not carried by the event are left zero.
+type StakingLog struct {
+	Event      string
+	Sender     common.Address
+	Validator  common.Address
+	Value      *big.Int
+	FeeRate    uint64
+	MaxFeeRate uint64
+	LockEpochs uint64
+	Renewal    bool
+}
+
+var (
+	errStakingLogAddr   = errors.New("log is not emitted by the staking contract")
+	errStakingLogTopic  = errors.New("unknown staking log topic")
+	errStakingLogFormat = errors.New("wrong staking log format")
+
+	// event id -> event name, for logs emitted after ApolloEpochID
+	stakingEventIds = make(map[common.Hash]string)
+	// method signature hash -> method name, for logs emitted before ApolloEpochID
+	stakingMethodSigs = make(map[common.Hash]string)
+)
+
+func init() {
+	for name, event := range cscAbi.Events {
+		stakingEventIds[event.Id()] = name
+	}
+	for name, method := range cscAbi.Methods {
+		stakingMethodSigs[common.BytesToHash(crypto.Keccak256([]byte(method.Sig())))] = name
+	}
+}
+
+// StakingEventId returns the topic id of the named staking contract event.
+func StakingEventId(name string) common.Hash {
+	return cscAbi.Events[name].Id()
+}
+
+// ParseStakingLog decodes a log emitted by the pos staking contract. Both the
+// legacy layout (before ApolloEpochID) and the event layout are supported.
+func ParseStakingLog(l *types.Log) (*StakingLog, error) {
+	if l.Address != WanCscPrecompileAddr {
+		return nil, errStakingLogAddr
+	}
+	if len(l.Topics) == 0 {
+		return nil, errStakingLogFormat
+	}
+
+	if name, ok := stakingEventIds[l.Topics[0]]; ok {
+		return parseStakingEvent(name, l)
+	}
+	if name, ok := stakingMethodSigs[l.Topics[0]]; ok {
+		return parseLegacyStakingLog(name, l)
+	}
+	return nil, errStakingLogTopic
+}
+
+// parseStakingEvent decodes: topics [id, sender, posAddress, value], data [...]
+func parseStakingEvent(name string, l *types.Log) (*StakingLog, error) {
+	if len(l.Topics) < 3 {
+		return nil, errStakingLogFormat
+	}
+	info := &StakingLog{
+		Event:     name,
+		Sender:    common.BytesToAddress(l.Topics[1].Bytes()),
+		Validator: common.BytesToAddress(l.Topics[2].Bytes()),
+		Value:     big.NewInt(0),
+	}
+	if name == "delegateOut" {
+		return info, nil
+	}
+	if len(l.Topics) < 4 {
+		return nil, errStakingLogFormat
+	}
+
+	third := l.Topics[3].Big()
+	switch name {
+	case "stakeUpdate":
+		info.LockEpochs = third.Uint64()
+	case "stakeUpdateFeeRate":
+		info.FeeRate = third.Uint64()
+	default:
+		info.Value = third
+	}
+
+	words := splitLogData(l.Data)
+	switch name {
+	case "stakeIn":
+		if len(words) < 2 {
+			return nil, errStakingLogFormat
+		}
+		info.FeeRate, info.LockEpochs = words[0].Uint64(), words[1].Uint64()
+		info.MaxFeeRate = info.FeeRate
+	case "stakeRegister":
+		if len(words) < 3 {
+			return nil, errStakingLogFormat
+		}
+		info.FeeRate, info.LockEpochs, info.MaxFeeRate = words[0].Uint64(), words[1].Uint64(), words[2].Uint64()
+	case "partnerIn":
+		if len(words) < 1 {
+			return nil, errStakingLogFormat
+		}
+		info.Renewal = words[0].Sign() != 0
+	}
+	return info, nil
+}
+
+// parseLegacyStakingLog decodes the logs whose topic[0] is the method signature hash.
+func parseLegacyStakingLog(name string, l *types.Log) (*StakingLog, error) {
+	if len(l.Topics) < 3 {
+		return nil, errStakingLogFormat
+	}
+	info := &StakingLog{
+		Event:  name,
+		Sender: common.BytesToAddress(l.Topics[1].Bytes()),
+		Value:  big.NewInt(0),
+	}
+	last := common.BytesToAddress(l.Topics[len(l.Topics)-1].Bytes())
+
+	switch name {
+	case "stakeIn":
+		// [sig, sender, value, feeRate, lockEpochs, posAddress]
+		if len(l.Topics) < 6 {
+			return nil, errStakingLogFormat
+		}
+		info.Value = l.Topics[2].Big()
+		info.FeeRate = l.Topics[3].Big().Uint64()
+		info.MaxFeeRate = info.FeeRate
+		info.LockEpochs = l.Topics[4].Big().Uint64()
+	case "stakeAppend", "delegateIn":
+		// [sig, sender, value, posAddress]
+		if len(l.Topics) < 4 {
+			return nil, errStakingLogFormat
+		}
+		info.Value = l.Topics[2].Big()
+	case "stakeUpdate":
+		// [sig, sender, lockEpochs, posAddress]
+		if len(l.Topics) < 4 {
+			return nil, errStakingLogFormat
+		}
+		info.LockEpochs = l.Topics[2].Big().Uint64()
+	case "delegateOut":
+		// [sig, sender, posAddress]
+	default:
+		return nil, errStakingLogTopic
+	}
+	info.Validator = last
+	return info, nil
+}
+
+func splitLogData(data []byte) []*big.Int {
+	words := make([]*big.Int, 0, len(data)/common.HashLength)
+	for i := 0; i+common.HashLength <= len(data); i += common.HashLength {
+		words = append(words, new(big.Int).SetBytes(data[i:i+common.HashLength]))
+	}
+	return words
+}
@@ -0,0 +1,88 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+func TestParseStakingLogEvent(t *testing.T) {
+	sender := common.HexToAddress("0x2d0e7c0813a51d3bd1d08246af2a8a7a57d8922e")
+	validator := common.HexToAddress("0x8b157b3ffead48c8a4cdc6bddbe1c1d170049da4")
+	value := new(big.Int).Mul(big.NewInt(50000), ether)
+
+	data := make([]byte, 0)
+	data = append(data, common.BigToHash(big.NewInt(1500)).Bytes()...)
+	data = append(data, common.BigToHash(big.NewInt(30)).Bytes()...)
+	l := &types.Log{
+		Address: WanCscPrecompileAddr,
+		Topics: []common.Hash{
+			cscAbi.Events["stakeIn"].Id(),
+			common.BytesToHash(sender.Bytes()),
+			validator.Hash(),
+			common.BigToHash(value),
+		},
+		Data: data,
+	}
+	info, err := ParseStakingLog(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Event != "stakeIn" || info.Sender != sender || info.Validator != validator {
+		t.Fatalf("wrong parties: %+v", info)
+	}
+	if info.Value.Cmp(value) != 0 || info.FeeRate != 1500 || info.LockEpochs != 30 {
+		t.Fatalf("wrong values: %+v", info)
+	}
+
+	l.Topics = []common.Hash{
+		cscAbi.Events["stakeUpdateFeeRate"].Id(),
+		common.BytesToHash(sender.Bytes()),
+		validator.Hash(),
+		common.BigToHash(big.NewInt(900)),
+	}
+	l.Data = nil
+	info, err = ParseStakingLog(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Event != "stakeUpdateFeeRate" || info.FeeRate != 900 || info.Value.Sign() != 0 {
+		t.Fatalf("wrong fee rate log: %+v", info)
+	}
+}
+
+func TestParseStakingLogLegacy(t *testing.T) {
+	sender := common.HexToAddress("0x2d0e7c0813a51d3bd1d08246af2a8a7a57d8922e")
+	validator := common.HexToAddress("0x8b157b3ffead48c8a4cdc6bddbe1c1d170049da4")
+	value := new(big.Int).Mul(big.NewInt(100), ether)
+
+	l := &types.Log{
+		Address: WanCscPrecompileAddr,
+		Topics: []common.Hash{
+			common.BytesToHash(crypto.Keccak256([]byte(cscAbi.Methods["delegateIn"].Sig()))),
+			common.BytesToHash(sender.Bytes()),
+			common.BigToHash(value),
+			validator.Hash(),
+		},
+	}
+	info, err := ParseStakingLog(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Event != "delegateIn" || info.Sender != sender || info.Validator != validator || info.Value.Cmp(value) != 0 {
+		t.Fatalf("wrong legacy log: %+v", info)
+	}
+
+	l.Address = StakersInfoAddr
+	if _, err := ParseStakingLog(l); err != errStakingLogAddr {
+		t.Fatalf("expect %v, got %v", errStakingLogAddr, err)
+	}
+	l.Address = WanCscPrecompileAddr
+	l.Topics[0] = common.Hash{}
+	if _, err := ParseStakingLog(l); err != errStakingLogTopic {
+		t.Fatalf("expect %v, got %v", errStakingLogTopic, err)
+	}
+}
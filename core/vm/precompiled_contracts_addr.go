@@ -7,6 +7,7 @@ import (
 
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/params"
 )
 
 // Precompiled contracts address or
@@ -21,6 +22,7 @@ var (
 	bn256AddPrecompileAddr       = common.BytesToAddress([]byte{6})
 	bn256ScalarMulPrecompileAddr = common.BytesToAddress([]byte{7})
 	bn256PairingPrecompileAddr   = common.BytesToAddress([]byte{8})
+	blake2FPrecompileAddr        = common.BytesToAddress([]byte{9})
 
 	wanCoinPrecompileAddr  = common.BytesToAddress([]byte{100})
 	wanStampPrecompileAddr = common.BytesToAddress([]byte{200})
@@ -67,6 +69,9 @@ var (
 	randomBeaconPrecompileAddr = common.BytesToAddress(big.NewInt(610).Bytes())
 	PosControlPrecompileAddr   = common.BytesToAddress(big.NewInt(612).Bytes())
 
+	ringSigVerifyPrecompileAddr = common.BytesToAddress(big.NewInt(620).Bytes())
+	blsAggVerifyPrecompileAddr  = common.BytesToAddress(big.NewInt(621).Bytes())
+
 	// TODO: remove one?
 	RandomBeaconPrecompileAddr = randomBeaconPrecompileAddr
 	SlotLeaderPrecompileAddr   = slotLeaderPrecompileAddr
@@ -115,6 +120,81 @@ var PrecompiledContractsByzantium = map[common.Address]PrecompiledContract{
 	randomBeaconPrecompileAddr: &RandomBeaconContract{},
 }
 
+// PrecompiledContractsRingSig contains the pre-compiled contracts added to the
+// active set since the ring signature verify fork.
+var PrecompiledContractsRingSig = map[common.Address]PrecompiledContract{
+	ringSigVerifyPrecompileAddr: &ringSigVerify{},
+}
+
+// PrecompiledContractsBls contains the pre-compiled contracts added to the active
+// set since the BLS verify fork.
+var PrecompiledContractsBls = map[common.Address]PrecompiledContract{
+	blsAggVerifyPrecompileAddr: &blsAggVerify{},
+}
+
+// PrecompiledContractsIstanbul contains the pre-compiled contracts added to, or
+// repriced in, the active set since the Istanbul fork.
+var PrecompiledContractsIstanbul = map[common.Address]PrecompiledContract{
+	bn256AddPrecompileAddr:       &bn256AddIstanbul{},
+	bn256ScalarMulPrecompileAddr: &bn256ScalarMulIstanbul{},
+	bn256PairingPrecompileAddr:   &bn256PairingIstanbul{},
+	blake2FPrecompileAddr:        &blake2F{},
+}
+
+// Bits of the forks in the index of activePrecompiles.
+const (
+	ringSigVerifyFork = 1 << iota
+	blsVerifyFork
+	istanbulFork
+)
+
+// activePrecompiles are the active sets of pre-compiled contracts, indexed by the
+// bits of the enabled forks. Each set is the Byzantium one extended with the
+// contracts of its forks only.
+var activePrecompiles [1 << 3]map[common.Address]PrecompiledContract
+
+func init() {
+	for forks := range activePrecompiles {
+		set := make(map[common.Address]PrecompiledContract)
+		for _, group := range []struct {
+			fork      int
+			contracts map[common.Address]PrecompiledContract
+		}{
+			{0, PrecompiledContractsByzantium},
+			{ringSigVerifyFork, PrecompiledContractsRingSig},
+			{blsVerifyFork, PrecompiledContractsBls},
+			{istanbulFork, PrecompiledContractsIstanbul},
+		} {
+			if forks&group.fork != group.fork {
+				continue
+			}
+			for addr, contract := range group.contracts {
+				set[addr] = contract
+			}
+		}
+		activePrecompiles[forks] = set
+	}
+}
+
+// ActivePrecompiles returns the pre-compiled contracts active at the block number
+// under the chain config: the Byzantium ones and those of each enabled fork.
+func ActivePrecompiles(config *params.ChainConfig, number *big.Int) map[common.Address]PrecompiledContract {
+	if config == nil {
+		return PrecompiledContractsByzantium
+	}
+	forks := 0
+	if config.IsRingSigVerify(number) {
+		forks |= ringSigVerifyFork
+	}
+	if config.IsBlsVerify(number) {
+		forks |= blsVerifyFork
+	}
+	if config.IsIstanbul(number) {
+		forks |= istanbulFork
+	}
+	return activePrecompiles[forks]
+}
+
 func IsPosPrecompiledAddr(addr *common.Address) bool {
 	if addr == nil {
 		return false
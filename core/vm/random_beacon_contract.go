@@ -328,14 +328,18 @@ func ValidPosRBTx(stateDB StateDB, from common.Address, payload []byte) error {
 	var methodId [4]byte
 	copy(methodId[:], payload[:4])
 
+	now := uint64(time.Now().Unix())
 	if methodId == dkg1Id {
-		_, err := validDkg1(stateDB, uint64(time.Now().Unix()), from, payload[4:])
+		_, err := validDkg1(stateDB, now, from, payload[4:])
+		recordRBFailure(RBStageDkg1, now, from, payload[4:], err)
 		return err
 	} else if methodId == dkg2Id {
-		_, err := validDkg2(stateDB, uint64(time.Now().Unix()), from, payload[4:])
+		_, err := validDkg2(stateDB, now, from, payload[4:])
+		recordRBFailure(RBStageDkg2, now, from, payload[4:], err)
 		return err
 	} else if methodId == sigShareId {
-		_, _, _, err := validSigShare(stateDB, uint64(time.Now().Unix()), from, payload[4:])
+		_, _, _, err := validSigShare(stateDB, now, from, payload[4:])
+		recordRBFailure(RBStageSig, now, from, payload[4:], err)
 		return err
 	} else {
 		return errParameters
@@ -994,6 +998,9 @@ func (c *RandomBeaconContract) dkg1(payload []byte, contract *Contract, evm *EVM
 	log.Debug("dkg1")
 	dkg1FlatParam, err := validDkg1(evm.StateDB, evm.Time.Uint64(), contract.CallerAddress, payload)
 	if err != nil {
+		if evm.vmConfig.RecordRBFailures {
+			recordRBFailure(RBStageDkg1, evm.Time.Uint64(), contract.CallerAddress, payload, err)
+		}
 		return nil, err
 	}
 
@@ -1017,6 +1024,9 @@ func (c *RandomBeaconContract) dkg2(payload []byte, contract *Contract, evm *EVM
 	log.Debug("dkg2")
 	dkg2FlatParam, err := validDkg2(evm.StateDB, evm.Time.Uint64(), contract.CallerAddress, payload)
 	if err != nil {
+		if evm.vmConfig.RecordRBFailures {
+			recordRBFailure(RBStageDkg2, evm.Time.Uint64(), contract.CallerAddress, payload, err)
+		}
 		return nil, err
 	}
 
@@ -1040,6 +1050,9 @@ func (c *RandomBeaconContract) sigShare(payload []byte, contract *Contract, evm
 	log.Debug("sigShare")
 	sigShareParam, pks, dkgData, err := validSigShare(evm.StateDB, evm.Time.Uint64(), contract.CallerAddress, payload)
 	if err != nil {
+		if evm.vmConfig.RecordRBFailures {
+			recordRBFailure(RBStageSig, evm.Time.Uint64(), contract.CallerAddress, payload, err)
+		}
 		return nil, err
 	}
 
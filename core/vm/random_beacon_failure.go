@@ -0,0 +1,116 @@
+package vm
+
+import (
+	"sync"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/pos/util"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+const (
+	// maxRBFailureEpochs is the number of epochs the failures are kept for
+	maxRBFailureEpochs = 16
+	// maxRBFailuresPerEpoch limits the failures kept for one epoch
+	maxRBFailuresPerEpoch = 1024
+
+	RBStageDkg1 = "dkg1"
+	RBStageDkg2 = "dkg2"
+	RBStageSig  = "sig"
+)
+
+// RBFailure is a random beacon transaction rejected by validDkg1, validDkg2 or
+// validSigShare, either by the tx pool or when executed in a block.
+type RBFailure struct {
+	Stage      string
+	EpochId    uint64
+	ProposerId uint32
+	Sender     common.Address
+	Time       uint64 // time of the last rejection
+	Error      string
+	Count      uint64 // times the same failure happened
+}
+
+// rbPayloadHead is the common head of the dkg1, dkg2 and sigShare payloads
+type rbPayloadHead struct {
+	EpochId    uint64
+	ProposerId uint32
+	Rest       []rlp.RawValue `rlp:"tail"`
+}
+
+var rbFailures = struct {
+	sync.Mutex
+	epochs map[uint64][]RBFailure
+}{epochs: make(map[uint64][]RBFailure)}
+
+// recordRBFailure keeps the validation error of a random beacon payload in memory,
+// so that the participation of the proposers can be diagnosed later. Payloads
+// that can't be decoded are dropped, because their epoch is unknown, and so are
+// the ones of an epoch other than the current or the previous one of the time,
+// as the epoch of the payload is chosen by the sender.
+func recordRBFailure(stage string, time uint64, caller common.Address, payload []byte, err error) {
+	if err == nil {
+		return
+	}
+	eid, pid, derr := DecodeRBPayloadHead(payload)
+	if derr != nil {
+		return
+	}
+	cur, _ := util.CalEpochSlotID(time)
+	if eid > cur || cur-eid > 1 {
+		return
+	}
+
+	rbFailures.Lock()
+	defer rbFailures.Unlock()
+
+	failures := rbFailures.epochs[eid]
+	for i := range failures {
+		f := &failures[i]
+		if f.Stage == stage && f.ProposerId == pid && f.Sender == caller && f.Error == err.Error() {
+			f.Count++
+			f.Time = time
+			return
+		}
+	}
+	if len(failures) >= maxRBFailuresPerEpoch {
+		return
+	}
+	rbFailures.epochs[eid] = append(failures, RBFailure{
+		Stage:      stage,
+		EpochId:    eid,
+		ProposerId: pid,
+		Sender:     caller,
+		Time:       time,
+		Error:      err.Error(),
+		Count:      1,
+	})
+
+	for old := range rbFailures.epochs {
+		if old < cur && cur-old >= maxRBFailureEpochs {
+			delete(rbFailures.epochs, old)
+		}
+	}
+}
+
+// GetRBFailures returns the random beacon validation failures seen by this node
+// for the epoch.
+func GetRBFailures(epochId uint64) []RBFailure {
+	rbFailures.Lock()
+	defer rbFailures.Unlock()
+
+	failures := rbFailures.epochs[epochId]
+	ret := make([]RBFailure, len(failures))
+	copy(ret, failures)
+	return ret
+}
+
+// DecodeRBPayloadHead returns the epoch and proposer of a dkg1, dkg2 or sigShare
+// transaction payload, without the 4 bytes method id.
+func DecodeRBPayloadHead(payload []byte) (uint64, uint32, error) {
+	var head rbPayloadHead
+	if err := rlp.DecodeBytes(payload, &head); err != nil {
+		return 0, 0, err
+	}
+	return head.EpochId, head.ProposerId, nil
+}
@@ -0,0 +1,68 @@
+package vm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+func TestRecordRBFailure(t *testing.T) {
+	sender := common.HexToAddress("0x2d0e7c0813a51d3bd1d08246af2a8a7a57d8922e")
+	payload, err := rlp.EncodeToBytes(&RbDKG1FlatTxPayload{EpochId: 1000, ProposerId: 3, Commit: [][]byte{{1, 2, 3}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eid, pid, err := DecodeRBPayloadHead(payload)
+	if err != nil || eid != 1000 || pid != 3 {
+		t.Fatal("decode payload head fail", eid, pid, err)
+	}
+
+	epochTime := uint64(posconfig.SlotTime * posconfig.SlotCount)
+	at := func(eid uint64) uint64 { return eid*epochTime + 1 }
+	dkg1 := func(eid uint64) []byte {
+		payload, _ := rlp.EncodeToBytes(&RbDKG1FlatTxPayload{EpochId: eid, ProposerId: 3})
+		return payload
+	}
+
+	recordRBFailure(RBStageDkg1, at(1000), sender, payload, nil)
+	if len(GetRBFailures(1000)) != 0 {
+		t.Fatal("nil error should not be recorded")
+	}
+
+	recordRBFailure(RBStageDkg1, at(1000), sender, payload, errors.New("invalid rb stage"))
+	recordRBFailure(RBStageDkg1, at(1000)+1, sender, payload, errors.New("invalid rb stage"))
+	recordRBFailure(RBStageDkg1, at(1000)+2, sender, []byte{0x01}, errors.New("bad payload"))
+	failures := GetRBFailures(1000)
+	if len(failures) != 1 {
+		t.Fatal("wrong failure count", len(failures))
+	}
+	if failures[0].ProposerId != 3 || failures[0].Count != 2 || failures[0].Time != at(1000)+1 || failures[0].Sender != sender {
+		t.Fatal("wrong failure", failures[0])
+	}
+
+	// The payloads of the previous epoch are kept, but not the older or later ones
+	recordRBFailure(RBStageDkg1, at(1001), sender, payload, errors.New("invalid rb stage"))
+	if failures := GetRBFailures(1000); len(failures) != 1 || failures[0].Count != 3 {
+		t.Fatal("previous epoch failure not recorded", failures)
+	}
+	recordRBFailure(RBStageDkg1, at(1002), sender, payload, errors.New("invalid rb stage"))
+	recordRBFailure(RBStageDkg1, at(1000), sender, dkg1(1001), errors.New("invalid rb stage"))
+	recordRBFailure(RBStageDkg1, at(1000), sender, dkg1(^uint64(0)), errors.New("invalid rb stage"))
+	if GetRBFailures(1000)[0].Count != 3 || len(GetRBFailures(1001)) != 0 || len(GetRBFailures(^uint64(0))) != 0 {
+		t.Fatal("failure of another epoch recorded")
+	}
+
+	// A far epoch in a payload doesn't drop the others, the time does
+	recordRBFailure(RBStageDkg1, at(2000), sender, dkg1(1000+maxRBFailureEpochs), errors.New("invalid rb stage"))
+	if len(GetRBFailures(1000)) != 1 {
+		t.Fatal("failures dropped by the epoch of a payload")
+	}
+	recordRBFailure(RBStageDkg1, at(1000+maxRBFailureEpochs), sender, dkg1(1000+maxRBFailureEpochs), errors.New("invalid rb stage"))
+	if len(GetRBFailures(1000)) != 0 || len(GetRBFailures(1000+maxRBFailureEpochs)) != 1 {
+		t.Fatal("old epoch failures should be dropped")
+	}
+}
@@ -60,6 +60,8 @@ func setDefaults(cfg *Config) {
 			/////////////EIP150Block:    new(big.Int),
 			//EIP155Block:    new(big.Int),
 			//EIP158Block:    new(big.Int),
+			PetersburgBlock: new(big.Int),
+			IstanbulBlock:   new(big.Int),
 		}
 	}
 
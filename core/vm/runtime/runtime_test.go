@@ -25,7 +25,9 @@ import (
 	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/core/state"
 	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/params"
 )
 
 func TestDefaults(t *testing.T) {
@@ -117,6 +119,168 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// create2Code creates with CREATE2 a contract of empty init code and salt 0, and
+// returns its address.
+var create2Code = []byte{
+	byte(vm.PUSH1), 0, // salt
+	byte(vm.PUSH1), 0, // size
+	byte(vm.PUSH1), 0, // offset
+	byte(vm.PUSH1), 0, // value
+	byte(vm.CREATE2),
+	byte(vm.PUSH1), 0,
+	byte(vm.MSTORE),
+	byte(vm.PUSH1), 32,
+	byte(vm.PUSH1), 0,
+	byte(vm.RETURN),
+}
+
+func TestCreate2FundedAddress(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	factory := common.HexToAddress("0x0a")
+	statedb.SetCode(factory, create2Code)
+
+	// fund the counterfactual address before the deployment
+	target := crypto.CreateAddress2(factory, common.Hash{}, crypto.Keccak256(nil))
+	statedb.AddBalance(target, big.NewInt(1000))
+
+	ret, _, err := Call(factory, nil, &Config{State: statedb})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if addr := common.BytesToAddress(ret); addr != target {
+		t.Fatalf("contract not created at the funded address: have %x, want %x", addr, target)
+	}
+	if statedb.GetNonce(target) != 1 || statedb.GetBalance(target).Cmp(big.NewInt(1000)) != 0 {
+		t.Fatal("wrong created account", statedb.GetNonce(target), statedb.GetBalance(target))
+	}
+
+	// a second creation at the same address collides with the created contract
+	ret, _, err = Call(factory, nil, &Config{State: statedb})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if addr := common.BytesToAddress(ret); addr != (common.Address{}) {
+		t.Fatalf("contract created twice at %x", addr)
+	}
+}
+
+// returnTop is the code returning the word on top of the stack.
+var returnTop = []byte{
+	byte(vm.PUSH1), 0,
+	byte(vm.MSTORE),
+	byte(vm.PUSH1), 32,
+	byte(vm.PUSH1), 0,
+	byte(vm.RETURN),
+}
+
+func TestPetersburgShifts(t *testing.T) {
+	tests := []struct {
+		op       vm.OpCode
+		value    string
+		shift    byte
+		expected string
+	}{
+		{vm.SHL, "0000000000000000000000000000000000000000000000000000000000000001", 0x01, "0000000000000000000000000000000000000000000000000000000000000002"},
+		{vm.SHL, "0000000000000000000000000000000000000000000000000000000000000001", 0xff, "8000000000000000000000000000000000000000000000000000000000000000"},
+		{vm.SHL, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 0x01, "fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe"},
+		{vm.SHR, "8000000000000000000000000000000000000000000000000000000000000000", 0x01, "4000000000000000000000000000000000000000000000000000000000000000"},
+		{vm.SHR, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 0xff, "0000000000000000000000000000000000000000000000000000000000000001"},
+		{vm.SAR, "8000000000000000000000000000000000000000000000000000000000000000", 0x01, "c000000000000000000000000000000000000000000000000000000000000000"},
+		{vm.SAR, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 0xff, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+		{vm.SAR, "4000000000000000000000000000000000000000000000000000000000000000", 0xfe, "0000000000000000000000000000000000000000000000000000000000000001"},
+	}
+	for i, test := range tests {
+		code := append([]byte{byte(vm.PUSH32)}, common.Hex2Bytes(test.value)...)
+		code = append(code, byte(vm.PUSH1), test.shift, byte(test.op))
+		code = append(code, returnTop...)
+
+		ret, _, err := Execute(code, nil, nil)
+		if err != nil {
+			t.Fatalf("test %d: %v failed: %v", i, test.op, err)
+		}
+		if have := common.Bytes2Hex(ret); have != test.expected {
+			t.Errorf("test %d: %v mismatch: have %s, want %s", i, test.op, have, test.expected)
+		}
+
+		// the shifts are invalid opcodes before the fork
+		byzantium := &params.ChainConfig{ChainId: big.NewInt(1), ByzantiumBlock: new(big.Int)}
+		if _, _, err := Execute(code, nil, &Config{ChainConfig: byzantium}); err == nil {
+			t.Errorf("test %d: %v executed before the petersburg fork", i, test.op)
+		}
+	}
+}
+
+func TestCreate2Address(t *testing.T) {
+	// the init code deploys the single byte runtime code 0xfe
+	initCode := []byte{
+		byte(vm.PUSH1), 0xfe,
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE8),
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	code := append([]byte{byte(vm.PUSH10)}, initCode...)
+	code = append(code,
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 0x2a, // salt
+		byte(vm.PUSH1), byte(len(initCode)), // size
+		byte(vm.PUSH1), byte(32-len(initCode)), // offset
+		byte(vm.PUSH1), 0, // value
+		byte(vm.CREATE2),
+	)
+	code = append(code, returnTop...)
+
+	ret, statedb, err := Execute(code, nil, nil)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	salt := common.BigToHash(big.NewInt(0x2a))
+	want := crypto.CreateAddress2(common.StringToAddress("contract"), salt, crypto.Keccak256(initCode))
+	if addr := common.BytesToAddress(ret); addr != want {
+		t.Fatalf("contract created at %x, want %x", addr, want)
+	}
+	if code := statedb.GetCode(want); len(code) != 1 || code[0] != 0xfe {
+		t.Fatalf("wrong created code %x", code)
+	}
+}
+
+func TestExtCodeHash(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0x0a")
+		funded   = common.HexToAddress("0x0b")
+		missing  = common.HexToAddress("0x0c")
+	)
+	tests := []struct {
+		addr     common.Address
+		expected common.Hash
+	}{
+		{contract, crypto.Keccak256Hash([]byte{0xfe})},
+		{funded, crypto.Keccak256Hash(nil)},
+		{missing, common.Hash{}},
+	}
+	for i, test := range tests {
+		db, _ := ethdb.NewMemDatabase()
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+		statedb.SetCode(contract, []byte{0xfe})
+		statedb.AddBalance(funded, big.NewInt(1))
+
+		code := append([]byte{byte(vm.PUSH20)}, test.addr.Bytes()...)
+		code = append(code, byte(vm.EXTCODEHASH))
+		code = append(code, returnTop...)
+
+		ret, _, err := Execute(code, nil, &Config{State: statedb})
+		if err != nil {
+			t.Fatalf("test %d: didn't expect error: %v", i, err)
+		}
+		if have := common.BytesToHash(ret); have != test.expected {
+			t.Errorf("test %d: hash mismatch: have %x, want %x", i, have, test.expected)
+		}
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
@@ -0,0 +1,31 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package blake2b
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func TestF(t *testing.T) {
+	// BLAKE2b-512("abc") of RFC 7693 appendix A, a single final block
+	h := iv
+	h[0] ^= 0x01010040
+	var m [16]uint64
+	var block [128]byte
+	copy(block[:], "abc")
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint64(block[i*8:])
+	}
+	F(&h, m, [2]uint64{3, 0}, true, 12)
+
+	out := make([]byte, 64)
+	for i := range h {
+		binary.LittleEndian.PutUint64(out[i*8:], h[i])
+	}
+	want := "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"
+	if got := hex.EncodeToString(out); got != want {
+		t.Fatalf("wrong digest: have %s, want %s", got, want)
+	}
+}
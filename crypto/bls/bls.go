@@ -0,0 +1,238 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package bls implements BLS signatures over the bn256 pairing groups, with
+// signatures in G1 and public keys in G2.
+//
+// Signatures of several keys can be aggregated into one signature of the size of
+// a single one. An aggregate of signatures of the same message is only secure if
+// every public key was proven to be owned by its signer, e.g. by a signature of
+// the key itself checked when the key was registered, otherwise a rogue key can
+// cancel the others out.
+package bls
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common/math"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/crypto/bn256"
+)
+
+const (
+	// SignatureLength is the length of a marshalled signature, a G1 point.
+	SignatureLength = 64
+
+	// PublicKeyLength is the length of a marshalled public key, a G2 point.
+	PublicKeyLength = 128
+)
+
+// keySuffix separates the BLS key derived from a keystore key from the other
+// keys derived from it.
+const keySuffix = "bls"
+
+var (
+	ErrInvalidSecretKey = errors.New("invalid bls secret key")
+	ErrInvalidPublicKey = errors.New("invalid bls public key")
+	ErrInvalidSignature = errors.New("invalid bls signature")
+
+	big1 = big.NewInt(1)
+	big3 = big.NewInt(3)
+
+	// sqrtExp is (P+1)/4, P = 3 mod 4 so a^sqrtExp is a square root of a square a
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(bn256.P, big1), 2)
+
+	g2 = new(bn256.G2).ScalarBaseMult(big1)
+)
+
+// SecretKey is a BLS secret key, a scalar in [1, Order).
+type SecretKey struct {
+	x *big.Int
+}
+
+// PublicKey is a BLS public key, the G2 point x*g2.
+type PublicKey struct {
+	p *bn256.G2
+}
+
+// Signature is a BLS signature, the G1 point x*H(msg), or an aggregate of them.
+type Signature struct {
+	p *bn256.G1
+}
+
+// GenerateKey returns a random secret key read from rand.
+func GenerateKey(rand io.Reader) (*SecretKey, error) {
+	x, err := randScalar(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretKey{x: x}, nil
+}
+
+func randScalar(rand io.Reader) (*big.Int, error) {
+	n := new(big.Int).Sub(bn256.Order, big1)
+	buf := make([]byte, 40) // 64 bits more than the order, the bias is negligible
+	if _, err := io.ReadFull(rand, buf); err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(buf)
+	return x.Mod(x, n).Add(x, big1), nil
+}
+
+// KeyFromECDSA derives the secret key of a keystore key from its PrivateKey2, the
+// same keystore key always gives the same BLS key. The key is independent from
+// the other keys derived from PrivateKey2, as the random beacon one.
+func KeyFromECDSA(sk2 *ecdsa.PrivateKey) (*SecretKey, error) {
+	if sk2 == nil || sk2.D == nil || sk2.D.Sign() <= 0 {
+		return nil, ErrInvalidSecretKey
+	}
+	h := crypto.Keccak256(math.PaddedBigBytes(sk2.D, 32), []byte(keySuffix))
+	x := new(big.Int).SetBytes(h)
+	x.Mod(x, new(big.Int).Sub(bn256.Order, big1)).Add(x, big1)
+	return &SecretKey{x: x}, nil
+}
+
+// SecretKeyFromBytes returns the secret key of the 32 bytes big endian scalar.
+func SecretKeyFromBytes(b []byte) (*SecretKey, error) {
+	x := new(big.Int).SetBytes(b)
+	if len(b) != 32 || x.Sign() == 0 || x.Cmp(bn256.Order) >= 0 {
+		return nil, ErrInvalidSecretKey
+	}
+	return &SecretKey{x: x}, nil
+}
+
+// Bytes returns the secret key as a 32 bytes big endian scalar.
+func (sk *SecretKey) Bytes() []byte {
+	return math.PaddedBigBytes(sk.x, 32)
+}
+
+// PublicKey returns the public key of the secret key.
+func (sk *SecretKey) PublicKey() *PublicKey {
+	return &PublicKey{p: new(bn256.G2).ScalarBaseMult(sk.x)}
+}
+
+// Sign returns the signature of msg.
+func (sk *SecretKey) Sign(msg []byte) *Signature {
+	return &Signature{p: new(bn256.G1).ScalarMult(HashToG1(msg), sk.x)}
+}
+
+// Marshal returns the public key as the 128 bytes X.x || X.y || Y.x || Y.y.
+func (pk *PublicKey) Marshal() []byte {
+	return pk.p.Marshal()
+}
+
+// UnmarshalPublicKey returns the public key of the output of Marshal. The point
+// at infinity is rejected.
+func UnmarshalPublicKey(b []byte) (*PublicKey, error) {
+	if len(b) != PublicKeyLength || isZero(b) {
+		return nil, ErrInvalidPublicKey
+	}
+	p := new(bn256.G2)
+	if _, err := p.Unmarshal(b); err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	return &PublicKey{p: p}, nil
+}
+
+// Marshal returns the signature as the 64 bytes X || Y.
+func (sig *Signature) Marshal() []byte {
+	return sig.p.Marshal()
+}
+
+// UnmarshalSignature returns the signature of the output of Marshal.
+func UnmarshalSignature(b []byte) (*Signature, error) {
+	if len(b) != SignatureLength {
+		return nil, ErrInvalidSignature
+	}
+	p := new(bn256.G1)
+	if _, err := p.Unmarshal(b); err != nil {
+		return nil, ErrInvalidSignature
+	}
+	return &Signature{p: p}, nil
+}
+
+// HashToG1 maps msg to a G1 point of unknown discrete logarithm. The X coordinate
+// starts from Keccak256(msg) and is incremented until X^3+3 is a square, G1 is
+// the whole curve so the point needs no cofactor clearing.
+func HashToG1(msg []byte) *bn256.G1 {
+	x := new(big.Int).SetBytes(crypto.Keccak256(msg))
+	x.Mod(x, bn256.P)
+	for {
+		rhs := new(big.Int).Exp(x, big3, bn256.P)
+		rhs.Add(rhs, big3).Mod(rhs, bn256.P)
+
+		y := new(big.Int).Exp(rhs, sqrtExp, bn256.P)
+		y2 := new(big.Int).Mul(y, y)
+		if y2.Mod(y2, bn256.P).Cmp(rhs) == 0 {
+			p := new(bn256.G1)
+			if _, err := p.Unmarshal(append(math.PaddedBigBytes(x, 32), math.PaddedBigBytes(y, 32)...)); err == nil {
+				return p
+			}
+		}
+		x.Add(x, big1).Mod(x, bn256.P)
+	}
+}
+
+// Verify reports whether sig is the signature of msg by pk.
+func Verify(pk *PublicKey, msg []byte, sig *Signature) bool {
+	return AggregateVerify([]*PublicKey{pk}, [][]byte{msg}, sig)
+}
+
+// Aggregate returns the aggregate of the signatures, or nil if there are none.
+func Aggregate(sigs []*Signature) *Signature {
+	if len(sigs) == 0 {
+		return nil
+	}
+	p := new(bn256.G1).ScalarBaseMult(new(big.Int))
+	for _, sig := range sigs {
+		p.Add(p, sig.p)
+	}
+	return &Signature{p: p}
+}
+
+// AggregatePublicKeys returns the aggregate of the public keys, which verifies
+// the aggregate of their signatures of the same message. It returns nil if there
+// are no keys.
+func AggregatePublicKeys(pks []*PublicKey) *PublicKey {
+	if len(pks) == 0 {
+		return nil
+	}
+	p := new(bn256.G2).ScalarBaseMult(new(big.Int))
+	for _, pk := range pks {
+		p.Add(p, pk.p)
+	}
+	return &PublicKey{p: p}
+}
+
+// AggregateVerify reports whether sig is the aggregate of the signatures of
+// msgs[i] by pks[i]. See the package doc for signatures of the same message.
+func AggregateVerify(pks []*PublicKey, msgs [][]byte, sig *Signature) bool {
+	if len(pks) == 0 || len(pks) != len(msgs) || sig == nil {
+		return false
+	}
+
+	// e(-sig, g2) * e(H(msg0), pk0) * ... * e(H(msgn), pkn) == 1
+	a := make([]*bn256.G1, 0, len(pks)+1)
+	b := make([]*bn256.G2, 0, len(pks)+1)
+	a = append(a, new(bn256.G1).Neg(sig.p))
+	b = append(b, g2)
+	for i, pk := range pks {
+		if pk == nil || isZero(pk.p.Marshal()) {
+			return false
+		}
+		a = append(a, HashToG1(msgs[i]))
+		b = append(b, pk.p)
+	}
+	return bn256.PairingCheck(a, b)
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
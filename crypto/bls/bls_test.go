@@ -0,0 +1,100 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package bls
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+func TestSignVerify(t *testing.T) {
+	sk, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := sk.PublicKey()
+	msg := []byte("message")
+
+	sig := sk.Sign(msg)
+	if !Verify(pk, msg, sig) {
+		t.Fatal("valid signature rejected")
+	}
+	if Verify(pk, []byte("other message"), sig) {
+		t.Fatal("signature of another message accepted")
+	}
+	other, _ := GenerateKey(rand.Reader)
+	if Verify(other.PublicKey(), msg, sig) {
+		t.Fatal("signature of another key accepted")
+	}
+
+	// round trip of the encodings
+	pk2, err := UnmarshalPublicKey(pk.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig2, err := UnmarshalSignature(sig.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(pk2, msg, sig2) {
+		t.Fatal("unmarshalled signature rejected")
+	}
+	if _, err := UnmarshalPublicKey(make([]byte, PublicKeyLength)); err != ErrInvalidPublicKey {
+		t.Fatal("public key at infinity accepted", err)
+	}
+}
+
+func TestAggregateVerify(t *testing.T) {
+	var (
+		pks  = make([]*PublicKey, 4)
+		msgs = make([][]byte, 4)
+		sigs = make([]*Signature, 4)
+	)
+	for i := range pks {
+		sk, _ := GenerateKey(rand.Reader)
+		pks[i] = sk.PublicKey()
+		msgs[i] = []byte{byte(i)}
+		sigs[i] = sk.Sign(msgs[i])
+	}
+
+	agg := Aggregate(sigs)
+	if !AggregateVerify(pks, msgs, agg) {
+		t.Fatal("valid aggregate signature rejected")
+	}
+	if AggregateVerify(pks[:3], msgs[:3], agg) {
+		t.Fatal("aggregate signature verified without a signer")
+	}
+	if AggregateVerify(pks, append([][]byte{msgs[1], msgs[0]}, msgs[2:]...), agg) {
+		t.Fatal("aggregate signature of swapped messages accepted")
+	}
+
+	// signatures of the same message are checked with the aggregate key
+	msg := []byte("attestation")
+	for i := range sigs {
+		sk, _ := GenerateKey(rand.Reader)
+		pks[i] = sk.PublicKey()
+		sigs[i] = sk.Sign(msg)
+	}
+	if !Verify(AggregatePublicKeys(pks), msg, Aggregate(sigs)) {
+		t.Fatal("aggregate signature of the same message rejected")
+	}
+}
+
+func TestKeyFromECDSA(t *testing.T) {
+	sk2, _ := crypto.GenerateKey()
+	a, err := KeyFromECDSA(sk2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := KeyFromECDSA(sk2)
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Fatal("key derivation isn't deterministic")
+	}
+	c, err := SecretKeyFromBytes(a.Bytes())
+	if err != nil || !bytes.Equal(c.PublicKey().Marshal(), a.PublicKey().Marshal()) {
+		t.Fatal("secret key round trip failed", err)
+	}
+}
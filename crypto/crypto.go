@@ -83,6 +83,12 @@ func CreateAddress(b common.Address, nonce uint64) common.Address {
 	return common.BytesToAddress(Keccak256(data)[12:])
 }
 
+// CreateAddress2 creates an ethereum address given the address bytes, initial
+// contract code hash and a salt.
+func CreateAddress2(b common.Address, salt [32]byte, inithash []byte) common.Address {
+	return common.BytesToAddress(Keccak256([]byte{0xff}, b.Bytes(), salt[:], inithash)[12:])
+}
+
 // ToECDSA creates a private key with the given D value.
 func ToECDSA(d []byte) (*ecdsa.PrivateKey, error) {
 	return toECDSA(d, true)
@@ -398,6 +404,12 @@ func xScalarHashP(x []byte, pub *ecdsa.PublicKey) (I *ecdsa.PublicKey) {
 	return
 }
 
+// KeyImage returns the key image [x]Hash(P) a ring signature with the OTA private
+// key x of P reveals, it's recorded on chain when the OTA is spent.
+func KeyImage(x *big.Int, pub *ecdsa.PublicKey) *ecdsa.PublicKey {
+	return xScalarHashP(x.Bytes(), pub)
+}
+
 var (
 	ErrInvalidRingSignParams = errors.New("invalid ring sign params")
 	ErrRingSignFail          = errors.New("ring sign fail")
@@ -35,6 +35,7 @@ import (
 	"github.com/wanchain/go-wanchain/core/state"
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/eth/tracers"
 	"github.com/wanchain/go-wanchain/internal/ethapi"
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/miner"
@@ -362,6 +363,7 @@ type TraceArgs struct {
 	*vm.LogConfig
 	Tracer  *string
 	Timeout *string
+	Reexec  *uint64 // blocks re-executed at most to regenerate a missing state
 }
 
 // TraceBlock processes the given block'api RLP but does not import the block in to
@@ -391,8 +393,9 @@ func (api *PrivateDebugAPI) TraceBlockFromFile(file string, config *vm.LogConfig
 	return api.TraceBlock(blockRlp, config)
 }
 
-// TraceBlockByNumber processes the block by canonical block number.
-func (api *PrivateDebugAPI) TraceBlockByNumber(blockNr rpc.BlockNumber, config *vm.LogConfig) BlockTraceResult {
+// TraceBlockByNumber returns the traces of the transactions of the canonical block
+// of the number, re-executed one by one on the state of its parent.
+func (api *PrivateDebugAPI) TraceBlockByNumber(ctx context.Context, blockNr rpc.BlockNumber, config *TraceArgs) ([]*txTraceResult, error) {
 	// Fetch the block that we aim to reprocess
 	var block *types.Block
 	switch blockNr {
@@ -406,31 +409,20 @@ func (api *PrivateDebugAPI) TraceBlockByNumber(blockNr rpc.BlockNumber, config *
 	}
 
 	if block == nil {
-		return BlockTraceResult{Error: fmt.Sprintf("block #%d not found", blockNr)}
-	}
-
-	validated, logs, err := api.traceBlock(block, config)
-	return BlockTraceResult{
-		Validated:  validated,
-		StructLogs: ethapi.FormatLogs(logs),
-		Error:      formatError(err),
+		return nil, fmt.Errorf("block #%d not found", blockNr)
 	}
+	return api.traceBlockTransactions(ctx, block, config)
 }
 
-// TraceBlockByHash processes the block by hash.
-func (api *PrivateDebugAPI) TraceBlockByHash(hash common.Hash, config *vm.LogConfig) BlockTraceResult {
+// TraceBlockByHash returns the traces of the transactions of the block of the
+// hash, re-executed one by one on the state of its parent.
+func (api *PrivateDebugAPI) TraceBlockByHash(ctx context.Context, hash common.Hash, config *TraceArgs) ([]*txTraceResult, error) {
 	// Fetch the block that we aim to reprocess
 	block := api.eth.BlockChain().GetBlockByHash(hash)
 	if block == nil {
-		return BlockTraceResult{Error: fmt.Sprintf("block #%x not found", hash)}
-	}
-
-	validated, logs, err := api.traceBlock(block, config)
-	return BlockTraceResult{
-		Validated:  validated,
-		StructLogs: ethapi.FormatLogs(logs),
-		Error:      formatError(err),
+		return nil, fmt.Errorf("block #%x not found", hash)
 	}
+	return api.traceBlockTransactions(ctx, block, config)
 }
 
 // traceBlock processes the given block but does not save the state.
@@ -482,49 +474,65 @@ func (t *timeoutError) Error() string {
 }
 
 // TraceTransaction returns the structured logs created during the execution of EVM
-// and returns them as a JSON object.
+// and returns them as a JSON object. The tracer of the config is either the name
+// of a native tracer, callTracer or prestateTracer, or JavaScript code.
 func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, txHash common.Hash, config *TraceArgs) (interface{}, error) {
-	var tracer vm.Tracer
-	if config != nil && config.Tracer != nil {
-		timeout := defaultTraceTimeout
-		if config.Timeout != nil {
-			var err error
-			if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+	// Retrieve the tx from the chain and the containing block
+	tx, blockHash, _, txIndex := core.GetTransaction(api.eth.ChainDb(), txHash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %x not found", txHash)
+	}
+	msg, context, statedb, err := api.computeTxEnv(blockHash, int(txIndex), traceReexec(config))
+	if err != nil {
+		return nil, err
+	}
+	return api.traceTx(ctx, msg, context, statedb, tx.Gas(), config)
+}
+
+// traceTx runs the message in the environment with the tracer of the config and
+// returns its trace.
+func (api *PrivateDebugAPI) traceTx(ctx context.Context, msg core.Message, vmctx vm.Context, statedb *state.StateDB, gasLimit *big.Int, config *TraceArgs) (interface{}, error) {
+	var (
+		tracer  vm.Tracer
+		timeout = defaultTraceTimeout
+		err     error
+	)
+	if config != nil && config.Timeout != nil {
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case config != nil && config.Tracer != nil:
+		var ok bool
+		if tracer, ok = tracers.New(*config.Tracer, statedb.Copy()); !ok {
+			if tracer, err = ethapi.NewJavascriptTracer(*config.Tracer); err != nil {
 				return nil, err
 			}
 		}
+	case config == nil:
+		tracer = vm.NewStructLogger(nil)
+	default:
+		tracer = vm.NewStructLogger(config.LogConfig)
+	}
 
-		var err error
-		if tracer, err = ethapi.NewJavascriptTracer(*config.Tracer); err != nil {
-			return nil, err
-		}
+	// Run the transaction with tracing enabled.
+	vmenv := vm.NewEVM(vmctx, statedb, api.config, vm.Config{Debug: true, Tracer: tracer})
 
-		// Handle timeouts and RPC cancellations
+	// Handle timeouts and RPC cancellations
+	if _, ok := tracer.(*vm.StructLogger); !ok {
 		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
 		go func() {
 			<-deadlineCtx.Done()
-			tracer.(*ethapi.JavascriptTracer).Stop(&timeoutError{})
+			if jst, ok := tracer.(*ethapi.JavascriptTracer); ok {
+				jst.Stop(&timeoutError{})
+			}
+			vmenv.Cancel()
 		}()
 		defer cancel()
-	} else if config == nil {
-		tracer = vm.NewStructLogger(nil)
-	} else {
-		tracer = vm.NewStructLogger(config.LogConfig)
-	}
-
-	// Retrieve the tx from the chain and the containing block
-	tx, blockHash, _, txIndex := core.GetTransaction(api.eth.ChainDb(), txHash)
-	if tx == nil {
-		return nil, fmt.Errorf("transaction %x not found", txHash)
-	}
-	msg, context, statedb, err := api.computeTxEnv(blockHash, int(txIndex))
-	if err != nil {
-		return nil, err
 	}
 
-	// Run the transaction with tracing enabled.
-	vmenv := vm.NewEVM(context, statedb, api.config, vm.Config{Debug: true, Tracer: tracer})
-	ret, gas, failed, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas()))
+	ret, gas, failed, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(gasLimit))
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %v", err)
 	}
@@ -538,13 +546,17 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, txHash common.
 		}, nil
 	case *ethapi.JavascriptTracer:
 		return tracer.GetResult()
+	case tracers.Tracer:
+		return tracer.GetResult()
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
 	}
 }
 
-// computeTxEnv returns the execution environment of a certain transaction.
-func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int) (core.Message, vm.Context, *state.StateDB, error) {
+// computeTxEnv returns the execution environment of a certain transaction,
+// regenerating the state of the parent block by re-executing at most reexec
+// blocks if it is missing.
+func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, *state.StateDB, error) {
 	// Create the parent state.
 	block := api.eth.BlockChain().GetBlockByHash(blockHash)
 	if block == nil {
@@ -554,7 +566,7 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int) (co
 	if parent == nil {
 		return nil, vm.Context{}, nil, fmt.Errorf("block parent %x not found", block.ParentHash())
 	}
-	statedb, err := api.eth.BlockChain().StateAt(parent.Root())
+	statedb, err := api.stateAtBlock(parent, reexec)
 	if err != nil {
 		return nil, vm.Context{}, nil, err
 	}
@@ -608,7 +620,7 @@ type storageEntry struct {
 
 // StorageRangeAt returns the storage at the given block height and transaction index.
 func (api *PrivateDebugAPI) StorageRangeAt(ctx context.Context, blockHash common.Hash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
-	_, _, statedb, err := api.computeTxEnv(blockHash, txIndex)
+	_, _, statedb, err := api.computeTxEnv(blockHash, txIndex, defaultTraceReexec)
 	if err != nil {
 		return StorageRangeResult{}, err
 	}
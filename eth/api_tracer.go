@@ -0,0 +1,281 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// defaultTraceReexec is the number of blocks re-executed at most to regenerate
+// a missing state to trace from, unless set by the Reexec of the TraceArgs.
+const defaultTraceReexec = uint64(128)
+
+var errTraceGenesis = errors.New("genesis is not traceable")
+
+// txTraceResult is the trace of a transaction of a traced block.
+type txTraceResult struct {
+	TxHash common.Hash `json:"txHash"`
+	Result interface{} `json:"result,omitempty"` // trace returned by the tracer
+	Error  string      `json:"error,omitempty"`  // error of the tracing
+}
+
+// blockTraceResult is a notification of debug_traceChain, the traces of the
+// transactions of a block.
+type blockTraceResult struct {
+	Block  hexutil.Uint64   `json:"block"`
+	Hash   common.Hash      `json:"hash"`
+	Traces []*txTraceResult `json:"traces,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// blockTraceTask is a block traced by a worker of traceChain.
+type blockTraceTask struct {
+	index   int              // order of the task in the traced range
+	block   *types.Block     // block to trace
+	statedb *state.StateDB   // state of the parent of the block, owned by the task
+	results []*txTraceResult // traces of the transactions
+	err     error            // error of the tracing
+}
+
+// TraceChain traces the transactions of the blocks from start, excluded, to end
+// and streams their traces block by block, in order. Blocks without transaction
+// are skipped. The blocks are re-executed in sequence to get their states and
+// traced in parallel workers.
+func (api *PrivateDebugAPI) TraceChain(ctx context.Context, start, end rpc.BlockNumber, config *TraceArgs) (*rpc.Subscription, error) {
+	blockchain := api.eth.BlockChain()
+	from := blockchain.GetBlockByNumber(uint64(start))
+	if start == rpc.LatestBlockNumber {
+		from = blockchain.CurrentBlock()
+	}
+	if from == nil {
+		return nil, fmt.Errorf("block #%d not found", start)
+	}
+	to := blockchain.GetBlockByNumber(uint64(end))
+	if end == rpc.LatestBlockNumber {
+		to = blockchain.CurrentBlock()
+	}
+	if to == nil {
+		return nil, fmt.Errorf("block #%d not found", end)
+	}
+	if from.NumberU64() >= to.NumberU64() {
+		return nil, fmt.Errorf("end block #%d not after start block #%d", to.NumberU64(), from.NumberU64())
+	}
+	return api.traceChain(ctx, from, to, config)
+}
+
+// traceChain streams the traces of the blocks after start up to end to the
+// subscription of the context.
+func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Block, config *TraceArgs) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	statedb, err := api.stateAtBlock(start, traceReexec(config))
+	if err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	threads := runtime.NumCPU()
+	if blocks := int(end.NumberU64() - start.NumberU64()); blocks < threads {
+		threads = blocks
+	}
+	var (
+		tasks   = make(chan *blockTraceTask, threads)
+		results = make(chan *blockTraceTask, threads)
+		workers sync.WaitGroup
+		failed  *blockTraceResult // set by the feeder before closing the tasks
+	)
+	for i := 0; i < threads; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for task := range tasks {
+				task.results, task.err = api.traceBlockTxs(context.Background(), task.block, task.statedb, config)
+				results <- task
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// Feed the workers with the blocks, advancing the state block by block
+	go func() {
+		defer close(tasks)
+
+		blockchain := api.eth.BlockChain()
+		index := 0
+		for number := start.NumberU64() + 1; number <= end.NumberU64(); number++ {
+			select {
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			default:
+			}
+			block := blockchain.GetBlockByNumber(number)
+			if block == nil {
+				failed = &blockTraceResult{Block: hexutil.Uint64(number), Error: "block not found"}
+				return
+			}
+			if len(block.Transactions()) > 0 {
+				tasks <- &blockTraceTask{index: index, block: block, statedb: statedb.Copy()}
+				index++
+			}
+			if err := api.processBlock(block, statedb); err != nil {
+				log.Warn("Chain tracing failed", "block", number, "err", err)
+				failed = &blockTraceResult{Block: hexutil.Uint64(number), Hash: block.Hash(), Error: err.Error()}
+				return
+			}
+		}
+	}()
+
+	// Stream the traces in the order of the blocks, once the subscription is
+	// activated as its notifications are dropped until then
+	go func() {
+		select {
+		case <-rpcSub.Activated():
+		case <-notifier.Closed():
+		}
+		var (
+			next = 0
+			done = make(map[int]*blockTraceTask)
+		)
+		for task := range results {
+			done[task.index] = task
+			for ready, ok := done[next]; ok; ready, ok = done[next] {
+				delete(done, next)
+				next++
+
+				result := &blockTraceResult{
+					Block:  hexutil.Uint64(ready.block.NumberU64()),
+					Hash:   ready.block.Hash(),
+					Traces: ready.results,
+				}
+				if ready.err != nil {
+					result.Error = ready.err.Error()
+				}
+				notifier.Notify(rpcSub.ID, result)
+			}
+		}
+		if failed != nil {
+			notifier.Notify(rpcSub.ID, failed)
+		}
+	}()
+	return rpcSub, nil
+}
+
+// traceBlockTransactions traces the transactions of the block on the state of its
+// parent.
+func (api *PrivateDebugAPI) traceBlockTransactions(ctx context.Context, block *types.Block, config *TraceArgs) ([]*txTraceResult, error) {
+	if block.NumberU64() == 0 {
+		return nil, errTraceGenesis
+	}
+	parent := api.eth.BlockChain().GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("block parent %x not found", block.ParentHash())
+	}
+	statedb, err := api.stateAtBlock(parent, traceReexec(config))
+	if err != nil {
+		return nil, err
+	}
+	return api.traceBlockTxs(ctx, block, statedb, config)
+}
+
+// traceBlockTxs traces the transactions of the block one by one, statedb is the
+// state of its parent and is modified by the transactions.
+func (api *PrivateDebugAPI) traceBlockTxs(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceArgs) ([]*txTraceResult, error) {
+	var (
+		signer  = types.MakeSigner(api.config, block.Number())
+		results = make([]*txTraceResult, len(block.Transactions()))
+	)
+	for i, tx := range block.Transactions() {
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			return nil, err
+		}
+		vmctx := core.NewEVMContext(msg, block.Header(), api.eth.BlockChain(), nil)
+
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		result, err := api.traceTx(ctx, msg, vmctx, statedb, tx.Gas(), config)
+		results[i] = &txTraceResult{TxHash: tx.Hash(), Result: result}
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+		statedb.Finalise(true)
+	}
+	return results, nil
+}
+
+// traceReexec returns the number of blocks re-executed at most to regenerate a
+// missing state for the config.
+func traceReexec(config *TraceArgs) uint64 {
+	if config != nil && config.Reexec != nil {
+		return *config.Reexec
+	}
+	return defaultTraceReexec
+}
+
+// stateAtBlock returns the state after the block. A missing state is regenerated
+// by re-executing at most reexec blocks from the nearest ancestor whose state is
+// available, without storing the regenerated states.
+func (api *PrivateDebugAPI) stateAtBlock(block *types.Block, reexec uint64) (*state.StateDB, error) {
+	blockchain := api.eth.BlockChain()
+	statedb, err := blockchain.StateAt(block.Root())
+	if err == nil {
+		return statedb, nil
+	}
+
+	// Look for the nearest ancestor with a state, keeping the blocks to re-execute
+	var (
+		origin = block
+		blocks []*types.Block
+	)
+	for i := uint64(0); i < reexec && err != nil; i++ {
+		if origin.NumberU64() == 0 {
+			break
+		}
+		blocks = append(blocks, origin)
+		if origin = blockchain.GetBlock(origin.ParentHash(), origin.NumberU64()-1); origin == nil {
+			return nil, fmt.Errorf("block parent %x not found", blocks[len(blocks)-1].ParentHash())
+		}
+		statedb, err = blockchain.StateAt(origin.Root())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("state of block #%d not found within %d blocks", block.NumberU64(), reexec)
+	}
+	log.Info("Regenerating state to trace", "block", block.NumberU64(), "from", origin.NumberU64())
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if err := api.processBlock(blocks[i], statedb); err != nil {
+			return nil, err
+		}
+	}
+	return statedb, nil
+}
+
+// processBlock applies the block to the state of its parent without tracing and
+// checks the resulting state root.
+func (api *PrivateDebugAPI) processBlock(block *types.Block, statedb *state.StateDB) error {
+	if _, _, _, err := api.eth.BlockChain().Processor().Process(block, statedb, vm.Config{}); err != nil {
+		return fmt.Errorf("processing block #%d failed: %v", block.NumberU64(), err)
+	}
+	if root := statedb.IntermediateRoot(true); root != block.Root() {
+		return fmt.Errorf("state root mismatch at block #%d: have %x, want %x", block.NumberU64(), root, block.Root())
+	}
+	return nil
+}
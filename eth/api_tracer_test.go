@@ -0,0 +1,207 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/consensus/ethash"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/internal/ethapi"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// newTestTracerChain generates a chain of the blocks, where the test bank sends
+// some wan in every block but the third, and returns its database and blocks.
+func newTestTracerChain(t *testing.T, blocks int) (ethdb.Database, []*types.Block) {
+	var (
+		db, _         = ethdb.NewMemDatabase()
+		engine        = ethash.NewFaker(db)
+		gspec         = core.DefaultPPOWTestingGenesisBlock()
+		genesis       = gspec.MustCommit(db)
+		blockchain, _ = core.NewBlockChain(db, gspec.Config, engine, vm.Config{})
+		to            = common.Address{0x01}
+	)
+	generator := func(i int, block *core.BlockGen) {
+		if i == 2 {
+			return
+		}
+		signer := types.MakeSigner(gspec.Config, block.Number())
+		for j := 0; j <= i%2; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), to, big.NewInt(1000), bigTxGas, nil, nil), signer, testBankKey)
+			block.AddTx(tx)
+		}
+	}
+	env := core.NewChainEnv(gspec.Config, gspec, engine, blockchain, db)
+	chain, _ := env.GenerateChain(genesis, blocks, generator)
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return db, chain
+}
+
+// newTestTracerAPI returns a debug API over a fresh blockchain of the database,
+// without any state cached from the import of the blocks.
+func newTestTracerAPI(t *testing.T, db ethdb.Database) *PrivateDebugAPI {
+	config := core.DefaultPPOWTestingGenesisBlock().Config
+	blockchain, err := core.NewBlockChain(db, config, ethash.NewFaker(db), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	return NewPrivateDebugAPI(config, &Ethereum{chainConfig: config, blockchain: blockchain, chainDb: db})
+}
+
+// checkTxTraces checks that the traces are the plain transfers of the block.
+func checkTxTraces(t *testing.T, block *types.Block, traces []*txTraceResult) {
+	if len(traces) != len(block.Transactions()) {
+		t.Fatalf("block #%d: trace count mismatch: have %d, want %d", block.NumberU64(), len(traces), len(block.Transactions()))
+	}
+	for i, tx := range block.Transactions() {
+		if traces[i].TxHash != tx.Hash() {
+			t.Errorf("block #%d tx %d: hash mismatch: have %x, want %x", block.NumberU64(), i, traces[i].TxHash, tx.Hash())
+		}
+		if traces[i].Error != "" {
+			t.Errorf("block #%d tx %d: tracing failed: %v", block.NumberU64(), i, traces[i].Error)
+			continue
+		}
+		result, ok := traces[i].Result.(*ethapi.ExecutionResult)
+		if !ok {
+			t.Fatalf("block #%d tx %d: result type mismatch: have %T", block.NumberU64(), i, traces[i].Result)
+		}
+		if result.Failed || result.Gas.Cmp(bigTxGas) != 0 {
+			t.Errorf("block #%d tx %d: result mismatch: failed %v, gas %v", block.NumberU64(), i, result.Failed, result.Gas)
+		}
+	}
+}
+
+func TestTraceBlockByNumberAndHash(t *testing.T) {
+	db, chain := newTestTracerChain(t, 5)
+	api := newTestTracerAPI(t, db)
+
+	for _, block := range chain {
+		byNumber, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(block.NumberU64()), nil)
+		if err != nil {
+			t.Fatalf("block #%d: failed to trace by number: %v", block.NumberU64(), err)
+		}
+		checkTxTraces(t, block, byNumber)
+
+		byHash, err := api.TraceBlockByHash(context.Background(), block.Hash(), nil)
+		if err != nil {
+			t.Fatalf("block #%d: failed to trace by hash: %v", block.NumberU64(), err)
+		}
+		if !reflect.DeepEqual(byNumber, byHash) {
+			t.Errorf("block #%d: traces by number and by hash differ", block.NumberU64())
+		}
+	}
+	latest, err := api.TraceBlockByNumber(context.Background(), rpc.LatestBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("failed to trace the latest block: %v", err)
+	}
+	checkTxTraces(t, chain[len(chain)-1], latest)
+
+	if _, err := api.TraceBlockByNumber(context.Background(), 0, nil); err != errTraceGenesis {
+		t.Errorf("genesis tracing error mismatch: have %v, want %v", err, errTraceGenesis)
+	}
+	if _, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(len(chain)+1), nil); err == nil {
+		t.Errorf("missing block traced")
+	}
+}
+
+func TestTraceBlockRegeneratesState(t *testing.T) {
+	db, chain := newTestTracerChain(t, 5)
+	want, err := newTestTracerAPI(t, db).TraceBlockByNumber(context.Background(), 5, nil)
+	if err != nil {
+		t.Fatalf("failed to trace: %v", err)
+	}
+	// Drop the states of the blocks before the head, down to the genesis one
+	for _, block := range chain[:len(chain)-1] {
+		db.Delete(block.Root().Bytes())
+	}
+	api := newTestTracerAPI(t, db)
+	if _, err := api.eth.BlockChain().StateAt(chain[3].Root()); err == nil {
+		t.Fatalf("state of block #4 not dropped")
+	}
+	have, err := api.TraceBlockByNumber(context.Background(), 5, nil)
+	if err != nil {
+		t.Fatalf("failed to trace with regenerated state: %v", err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("traces with regenerated state differ")
+	}
+	// Regenerating the state of block #4 re-executes the 4 blocks after genesis
+	reexec := uint64(3)
+	if _, err := api.TraceBlockByNumber(context.Background(), 5, &TraceArgs{Reexec: &reexec}); err == nil || !strings.Contains(err.Error(), "not found within") {
+		t.Errorf("error mismatch with too few re-executed blocks: have %v", err)
+	}
+	reexec = 4
+	if _, err := api.TraceBlockByNumber(context.Background(), 5, &TraceArgs{Reexec: &reexec}); err != nil {
+		t.Errorf("failed to trace with enough re-executed blocks: %v", err)
+	}
+}
+
+func TestTraceChain(t *testing.T) {
+	db, chain := newTestTracerChain(t, 12)
+	// Drop the state of the start block to have it regenerated
+	db.Delete(chain[0].Root().Bytes())
+	api := newTestTracerAPI(t, db)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("debug", api); err != nil {
+		t.Fatalf("failed to register the API: %v", err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	results := make(chan *blockTraceResult)
+	sub, err := client.Subscribe(context.Background(), "debug", results, "traceChain", hexutil.Uint64(1), hexutil.Uint64(len(chain)), nil)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Blocks are streamed in order, skipping block #3 without transactions
+	for _, block := range chain[1:] {
+		if len(block.Transactions()) == 0 {
+			continue
+		}
+		traces, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(block.NumberU64()), nil)
+		if err != nil {
+			t.Fatalf("block #%d: failed to trace: %v", block.NumberU64(), err)
+		}
+		// Round trip the expected traces through JSON as the streamed ones
+		blob, _ := json.Marshal(&blockTraceResult{Block: hexutil.Uint64(block.NumberU64()), Hash: block.Hash(), Traces: traces})
+		want := new(blockTraceResult)
+		if err := json.Unmarshal(blob, want); err != nil {
+			t.Fatalf("block #%d: failed to decode traces: %v", block.NumberU64(), err)
+		}
+		select {
+		case have := <-results:
+			if !reflect.DeepEqual(have, want) {
+				t.Fatalf("block #%d: streamed traces mismatch: have %+v, want %+v", block.NumberU64(), have, want)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("block #%d: traces not streamed", block.NumberU64())
+		}
+	}
+	select {
+	case have := <-results:
+		t.Fatalf("unexpected traces streamed: %+v", have)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := api.TraceChain(context.Background(), 5, 5, nil); err == nil {
+		t.Errorf("empty range traced")
+	}
+}
@@ -20,8 +20,9 @@ package eth
 import (
 	"errors"
 	"fmt"
-	"github.com/wanchain/go-wanchain/accounts/keystore"
 	"github.com/wanchain/go-wanchain/pos/posapi"
+	"github.com/wanchain/go-wanchain/pos/possigner"
+	"github.com/wanchain/go-wanchain/pos/stakehistory"
 	"math/big"
 	"runtime"
 	"sync"
@@ -36,6 +37,7 @@ import (
 	"github.com/wanchain/go-wanchain/consensus/ethash"
 	"github.com/wanchain/go-wanchain/core"
 	"github.com/wanchain/go-wanchain/core/bloombits"
+	"github.com/wanchain/go-wanchain/core/otaindex"
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/core/vm"
 	"github.com/wanchain/go-wanchain/eth/downloader"
@@ -83,6 +85,9 @@ type Ethereum struct {
 
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
+	stakeIndexer  *core.ChainIndexer             // Staking history indexer operating during block imports
+	otaMixIndexer *core.ChainIndexer             // OTA mix set indexer operating during block imports
+	otaImgIndexer *core.ChainIndexer             // OTA key image indexer operating during block imports
 
 	ApiBackend *EthApiBackend
 
@@ -135,6 +140,9 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		etherbase:      config.Etherbase,
 		bloomRequests:  make(chan chan *bloombits.Retrieval),
 		bloomIndexer:   NewBloomIndexer(chainDb, params.BloomBitsBlocks),
+		stakeIndexer:   stakehistory.NewIndexer(chainDb),
+		otaMixIndexer:  otaindex.NewIndexer(chainDb),
+		otaImgIndexer:  otaindex.NewImageIndexer(chainDb),
 	}
 
 	inPosStage := false
@@ -170,6 +178,9 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		core.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
 	eth.bloomIndexer.Start(eth.blockchain.CurrentHeader(), eth.blockchain.SubscribeChainEvent)
+	eth.stakeIndexer.Start(eth.blockchain.CurrentHeader(), eth.blockchain.SubscribeChainEvent)
+	eth.otaMixIndexer.Start(eth.blockchain.CurrentHeader(), eth.blockchain.SubscribeChainEvent)
+	eth.otaImgIndexer.Start(eth.blockchain.CurrentHeader(), eth.blockchain.SubscribeChainEvent)
 
 	// TODO:ppow2pos
 	//if chainConfig.Pluto != nil {
@@ -361,21 +372,13 @@ func (s *Ethereum) StartMining(local bool) error {
 		clique.Authorize(eb, wallet.SignHash)
 	}
 	if pluto, ok := s.engine.(*pluto.Pluto); ok {
-		wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
-		if wallet == nil || err != nil {
-			log.Error("Etherbase account unavailable locally", "err", err)
-			return fmt.Errorf("signer missing: %v", err)
-		}
-		//------------Get local unlock publicKey
-		type getKey interface {
-			GetUnlockedKey(address common.Address) (*keystore.Key, error)
-		}
-		key, err := wallet.(getKey).GetUnlockedKey(eb)
-		if key == nil || err != nil {
-			panic(err)
+		// the key is address only if the validator keys are in a remote signer
+		key, signFn, err := possigner.ValidatorKey(s.accountManager, eb)
+		if err != nil {
+			log.Error("Etherbase validator key unavailable", "err", err)
+			return err
 		}
-		//------------
-		pluto.Authorize(eb, wallet.SignHash, key)
+		pluto.Authorize(eb, signFn, key)
 	}
 
 	if ethash, ok := s.engine.(*ethash.Ethash); ok {
@@ -453,6 +456,9 @@ func (s *Ethereum) Stop() error {
 		s.stopDbUpgrade()
 	}
 	s.bloomIndexer.Close()
+	s.stakeIndexer.Close()
+	s.otaMixIndexer.Close()
+	s.otaImgIndexer.Close()
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
 	if s.lesServer != nil {
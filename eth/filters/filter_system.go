@@ -29,6 +29,8 @@ import (
 	"github.com/wanchain/go-wanchain/core"
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/cfm"
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
@@ -50,6 +52,10 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// StableBlocksSubscription queries headers of blocks once they are stable
+	StableBlocksSubscription
+	// StableLogsSubscription queries for logs of blocks once they are stable
+	StableLogsSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -69,8 +75,19 @@ const (
 
 var (
 	ErrInvalidSubscriptionID = errors.New("invalid id")
+	ErrStableUnavailable     = errors.New("stable block number not available")
 )
 
+// stableBlockNumber returns the highest irreversible block number computed by
+// the CFM, which isn't available before the pos consensus is initialized.
+var stableBlockNumber = func() (uint64, bool) {
+	c := cfm.GetCFM()
+	if c == nil {
+		return 0, false
+	}
+	return c.GetMaxStableBlkNumber(), true
+}
+
 type subscription struct {
 	id        rpc.ID
 	typ       Type
@@ -90,6 +107,7 @@ type EventSystem struct {
 	backend   Backend
 	lightMode bool
 	lastHead  *types.Header
+	stable    *types.Header      // last stable header delivered, nil without stable subscriptions
 	install   chan *subscription // install filter for event notification
 	uninstall chan *subscription // remove filter for event notification
 }
@@ -265,6 +283,46 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 	return es.subscribe(sub)
 }
 
+// SubscribeStableHeads creates a subscription that writes the header of every
+// canonical block once it is stable, in block number order.
+func (es *EventSystem) SubscribeStableHeads(headers chan *types.Header) (*Subscription, error) {
+	if _, ok := stableBlockNumber(); !ok {
+		return nil, ErrStableUnavailable
+	}
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       StableBlocksSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan common.Hash),
+		headers:   headers,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub), nil
+}
+
+// SubscribeStableLogs creates a subscription that writes the logs matching the
+// given criteria of every canonical block once it is stable. Stable logs are
+// never removed, so no log has the removed flag set.
+func (es *EventSystem) SubscribeStableLogs(crit FilterCriteria, logs chan []*types.Log) (*Subscription, error) {
+	if _, ok := stableBlockNumber(); !ok {
+		return nil, ErrStableUnavailable
+	}
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       StableLogsSubscription,
+		logsCrit:  crit,
+		created:   time.Now(),
+		logs:      logs,
+		hashes:    make(chan common.Hash),
+		headers:   make(chan *types.Header),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub), nil
+}
+
 // SubscribePendingTxEvents creates a subscription that writes transaction hashes for
 // transactions that enter the transaction pool.
 func (es *EventSystem) SubscribePendingTxEvents(hashes chan common.Hash) *Subscription {
@@ -392,6 +450,55 @@ func (es *EventSystem) lightFilterLogs(header *types.Header, addresses []common.
 	return nil
 }
 
+// stableHeader returns the canonical header at the stable block number.
+func (es *EventSystem) stableHeader() *types.Header {
+	number, ok := stableBlockNumber()
+	if !ok {
+		return nil
+	}
+	db := es.backend.ChainDb()
+	return core.GetHeader(db, core.GetCanonicalHash(db, number), number)
+}
+
+// broadcastStable delivers the blocks which became stable since the last call to
+// the stable subscriptions. The blocks are read from the canonical chain by
+// number, below the stable block number they can't be reorganized anymore.
+func (es *EventSystem) broadcastStable(filters filterIndex) {
+	if len(filters[StableBlocksSubscription]) == 0 && len(filters[StableLogsSubscription]) == 0 {
+		return
+	}
+	if es.stable == nil {
+		es.stable = es.stableHeader()
+		return
+	}
+	number, ok := stableBlockNumber()
+	if !ok {
+		return
+	}
+
+	db := es.backend.ChainDb()
+	for n := es.stable.Number.Uint64() + 1; n <= number; n++ {
+		header := core.GetHeader(db, core.GetCanonicalHash(db, n), n)
+		if header == nil {
+			return
+		}
+		if header.ParentHash != es.stable.Hash() {
+			log.Error("Stable block reorganized", "number", n-1, "delivered", es.stable.Hash(), "canonical", header.ParentHash)
+		}
+
+		for _, f := range filters[StableBlocksSubscription] {
+			f.headers <- header
+		}
+		for _, f := range filters[StableLogsSubscription] {
+			logs := es.lightFilterLogs(header, f.logsCrit.Addresses, f.logsCrit.Topics, false)
+			if matchedLogs := filterLogs(logs, f.logsCrit.FromBlock, f.logsCrit.ToBlock, nil, nil); len(matchedLogs) > 0 {
+				f.logs <- matchedLogs
+			}
+		}
+		es.stable = header
+	}
+}
+
 // eventLoop (un)installs filters and processes mux events.
 func (es *EventSystem) eventLoop() {
 	var (
@@ -439,6 +546,7 @@ func (es *EventSystem) eventLoop() {
 			es.broadcast(index, ev)
 		case ev := <-chainEvCh:
 			es.broadcast(index, ev)
+			es.broadcastStable(index)
 
 		case f := <-es.install:
 			if f.typ == MinedAndPendingLogsSubscription {
@@ -448,6 +556,10 @@ func (es *EventSystem) eventLoop() {
 			} else {
 				index[f.typ][f.id] = f
 			}
+			if (f.typ == StableBlocksSubscription || f.typ == StableLogsSubscription) && es.stable == nil {
+				// blocks already stable aren't delivered
+				es.stable = es.stableHeader()
+			}
 			close(f.installed)
 		case f := <-es.uninstall:
 			if f.typ == MinedAndPendingLogsSubscription {
@@ -457,6 +569,9 @@ func (es *EventSystem) eventLoop() {
 			} else {
 				delete(index[f.typ], f.id)
 			}
+			if len(index[StableBlocksSubscription]) == 0 && len(index[StableLogsSubscription]) == 0 {
+				es.stable = nil
+			}
 			close(f.err)
 
 		// System stopped
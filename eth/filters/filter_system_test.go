@@ -571,3 +571,90 @@ func TestPendingLogsSubscription(t *testing.T) {
 		}
 	}
 }
+
+// TestStableSubscription tests that the stable subscriptions deliver the blocks
+// and logs below the stable block number once, in block number order.
+func TestStableSubscription(t *testing.T) {
+	var (
+		mux        = new(event.TypeMux)
+		db, _      = ethdb.NewMemDatabase()
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		api        = NewPublicFilterAPI(backend, false)
+
+		addr    = common.HexToAddress("0x1111111111111111111111111111111111111111")
+		headers []*types.Header
+		stable  = make(chan uint64, 1)
+	)
+
+	// every block has a log of addr
+	var parent common.Hash
+	for i := 0; i < 10; i++ {
+		receipt := types.NewReceipt(nil, false, new(big.Int))
+		receipt.Logs = []*types.Log{{Address: addr, BlockNumber: uint64(i)}}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		header := &types.Header{Number: big.NewInt(int64(i)), ParentHash: parent, Bloom: types.CreateBloom(types.Receipts{receipt})}
+		parent = header.Hash()
+		core.WriteHeader(db, header)
+		core.WriteCanonicalHash(db, header.Hash(), uint64(i))
+		core.WriteBlockReceipts(db, header.Hash(), uint64(i), types.Receipts{receipt})
+		headers = append(headers, header)
+	}
+
+	defer func(f func() (uint64, bool)) { stableBlockNumber = f }(stableBlockNumber)
+	var current uint64 = 2
+	stableBlockNumber = func() (uint64, bool) {
+		select {
+		case current = <-stable:
+		default:
+		}
+		return current, true
+	}
+
+	headersCh := make(chan *types.Header)
+	headersSub, err := api.events.SubscribeStableHeads(headersCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logsCh := make(chan []*types.Log)
+	logsSub, err := api.events.SubscribeStableLogs(FilterCriteria{Addresses: []common.Address{addr}, ToBlock: big.NewInt(6)}, logsCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() { // simulate client
+		defer close(done)
+		next, nextLog := uint64(3), uint64(3)
+		for next <= 8 || nextLog <= 6 {
+			select {
+			case header := <-headersCh:
+				if header.Hash() != headers[next].Hash() {
+					t.Errorf("received invalid stable header %d, want %d", header.Number, next)
+				}
+				next++
+			case logs := <-logsCh:
+				if len(logs) != 1 || logs[0].BlockNumber != nextLog || logs[0].Removed {
+					t.Errorf("received invalid stable logs %v, want block %d", logs, nextLog)
+				}
+				nextLog++
+			case <-time.After(5 * time.Second):
+				t.Errorf("timeout waiting for stable block %d", next)
+				return
+			}
+		}
+	}()
+
+	stable <- 5
+	chainFeed.Send(core.ChainEvent{Hash: headers[9].Hash(), Block: types.NewBlockWithHeader(headers[9])})
+	time.Sleep(100 * time.Millisecond)
+	stable <- 8
+	chainFeed.Send(core.ChainEvent{Hash: headers[9].Hash(), Block: types.NewBlockWithHeader(headers[9])})
+	<-done
+
+	headersSub.Unsubscribe()
+	logsSub.Unsubscribe()
+}
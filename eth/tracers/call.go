@@ -0,0 +1,125 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package tracers
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/vm"
+)
+
+var errNoCallFrame = errors.New("no call frame traced")
+
+// callFrame is a call frame of the tree returned by the callTracer.
+type callFrame struct {
+	Type      string         `json:"type"`
+	From      common.Address `json:"from"`
+	To        common.Address `json:"to"`
+	Value     *hexutil.Big   `json:"value,omitempty"`
+	Gas       hexutil.Uint64 `json:"gas"`
+	GasUsed   hexutil.Uint64 `json:"gasUsed"`
+	Input     hexutil.Bytes  `json:"input"`
+	Output    hexutil.Bytes  `json:"output,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Transfers []transfer     `json:"transfers,omitempty"`
+	Calls     []*callFrame   `json:"calls,omitempty"`
+
+	callerBalance *big.Int // balance of the caller entering a pre-compiled contract
+}
+
+// transfer is a balance move made by a pre-compiled contract besides the value of
+// its call, e.g. the refund of a WAN coin or stamp to the caller.
+type transfer struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+}
+
+// callTracer builds the tree of the call frames of the execution. The frames of
+// the pre-compiled contracts have no sub calls, but the balance they moved out
+// of their call value is reported as transfers.
+type callTracer struct {
+	root  *callFrame
+	stack []*callFrame // frames being executed, the outermost first
+}
+
+func newCallTracer(statedb vm.StateDB) Tracer {
+	return &callTracer{}
+}
+
+// CaptureEnter pushes a new frame on the stack.
+func (t *callTracer) CaptureEnter(env *vm.EVM, typ vm.OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) {
+	frame := &callFrame{
+		Type:  typ.String(),
+		From:  from,
+		To:    to,
+		Gas:   hexutil.Uint64(gas),
+		Input: common.CopyBytes(input),
+	}
+	if value != nil {
+		frame.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	if _, ok := vm.ActivePrecompiles(env.ChainConfig(), env.BlockNumber)[to]; ok && typ != vm.SELFDESTRUCT {
+		frame.callerBalance = new(big.Int).Set(env.StateDB.GetBalance(from))
+	}
+	t.stack = append(t.stack, frame)
+}
+
+// CaptureExit pops the last frame of the stack and adds it to its parent.
+func (t *callTracer) CaptureExit(env *vm.EVM, output []byte, gasUsed uint64, err error) {
+	if len(t.stack) == 0 {
+		return
+	}
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+
+	frame.GasUsed = hexutil.Uint64(gasUsed)
+	frame.Output = common.CopyBytes(output)
+	if err != nil {
+		frame.Error = err.Error()
+	} else if frame.callerBalance != nil {
+		t.captureTransfer(env, frame)
+	}
+
+	if len(t.stack) == 0 {
+		t.root = frame
+		return
+	}
+	parent := t.stack[len(t.stack)-1]
+	parent.Calls = append(parent.Calls, frame)
+}
+
+// captureTransfer adds to the frame of a pre-compiled contract the change of the
+// balance of the caller not explained by the call value.
+func (t *callTracer) captureTransfer(env *vm.EVM, frame *callFrame) {
+	moved := new(big.Int).Sub(env.StateDB.GetBalance(frame.From), frame.callerBalance)
+	if frame.Type == vm.CALL.String() && frame.Value != nil {
+		moved.Add(moved, frame.Value.ToInt())
+	}
+	switch moved.Sign() {
+	case 1:
+		frame.Transfers = append(frame.Transfers, transfer{From: frame.To, To: frame.From, Value: (*hexutil.Big)(moved)})
+	case -1:
+		frame.Transfers = append(frame.Transfers, transfer{From: frame.From, To: frame.To, Value: (*hexutil.Big)(moved.Neg(moved))})
+	}
+}
+
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the outermost call frame.
+func (t *callTracer) GetResult() (interface{}, error) {
+	if t.root == nil {
+		return nil, errNoCallFrame
+	}
+	return t.root, nil
+}
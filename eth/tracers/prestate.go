@@ -0,0 +1,104 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package tracers
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/vm"
+)
+
+// account is an account touched by the execution, as it was before.
+type account struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// prestateTracer collects the accounts and the storage slots touched by the
+// execution and returns them as they were before it. The accounts created by
+// the execution are left out. The byte array storage of the WAN pre-compiled
+// contracts isn't read by op codes and so isn't collected.
+type prestateTracer struct {
+	pre      vm.StateDB                  // state before the execution
+	accounts map[common.Address]*account // touched accounts, nil if they didn't exist
+}
+
+func newPrestateTracer(statedb vm.StateDB) Tracer {
+	return &prestateTracer{
+		pre:      statedb,
+		accounts: make(map[common.Address]*account),
+	}
+}
+
+// lookupAccount collects the account if it wasn't yet.
+func (t *prestateTracer) lookupAccount(addr common.Address) *account {
+	if acc, ok := t.accounts[addr]; ok {
+		return acc
+	}
+	var acc *account
+	if t.pre.Exist(addr) {
+		acc = &account{
+			Balance: (*hexutil.Big)(new(big.Int).Set(t.pre.GetBalance(addr))),
+			Nonce:   t.pre.GetNonce(addr),
+			Code:    common.CopyBytes(t.pre.GetCode(addr)),
+			Storage: make(map[common.Hash]common.Hash),
+		}
+	}
+	t.accounts[addr] = acc
+	return acc
+}
+
+// lookupStorage collects the storage slot of the account if it wasn't yet.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	acc := t.lookupAccount(addr)
+	if acc == nil {
+		return
+	}
+	if _, ok := acc.Storage[key]; !ok {
+		acc.Storage[key] = t.pre.GetState(addr, key)
+	}
+}
+
+// CaptureEnter collects the accounts of the frame, and the coinbase paid for the
+// gas of the transaction.
+func (t *prestateTracer) CaptureEnter(env *vm.EVM, typ vm.OpCode, from, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.lookupAccount(env.Coinbase)
+	t.lookupAccount(from)
+	t.lookupAccount(to)
+}
+
+func (t *prestateTracer) CaptureExit(env *vm.EVM, output []byte, gasUsed uint64, err error) {}
+
+// CaptureState collects the storage slots and the accounts read by op codes.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if err != nil || len(stack.Data()) == 0 {
+		return nil
+	}
+	switch op {
+	case vm.SLOAD, vm.SSTORE:
+		t.lookupStorage(contract.Address(), common.BigToHash(stack.Back(0)))
+	case vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODECOPY, vm.EXTCODEHASH:
+		t.lookupAccount(common.BigToAddress(stack.Back(0)))
+	}
+	return nil
+}
+
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the touched accounts which existed before the execution.
+func (t *prestateTracer) GetResult() (interface{}, error) {
+	result := make(map[common.Address]*account, len(t.accounts))
+	for addr, acc := range t.accounts {
+		if acc != nil {
+			result[addr] = acc
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+// Package tracers implements the native tracers of the debug trace API, selected
+// by name instead of JavaScript code.
+//
+// The callTracer returns the tree of the call frames of a transaction and the
+// prestateTracer the accounts it touched, as they were before it ran.
+package tracers
+
+import (
+	"github.com/wanchain/go-wanchain/core/vm"
+)
+
+// Tracer is a native tracer, its trace is returned once the execution ended.
+type Tracer interface {
+	vm.Tracer
+	GetResult() (interface{}, error)
+}
+
+// constructors are the native tracers by name, statedb is the state before the
+// traced transaction, which must not be modified during the execution.
+var constructors = map[string]func(statedb vm.StateDB) Tracer{
+	"callTracer":     newCallTracer,
+	"prestateTracer": newPrestateTracer,
+}
+
+// New returns the native tracer of the name, and false if there is none. The
+// tracer reads the accounts from statedb, a copy of the state before the traced
+// transaction.
+func New(name string, statedb vm.StateDB) (Tracer, bool) {
+	constructor, ok := constructors[name]
+	if !ok {
+		return nil, false
+	}
+	return constructor(statedb), true
+}
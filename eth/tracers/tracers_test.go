@@ -0,0 +1,199 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package tracers
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/accounts/abi"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/core/vm/runtime"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/ethdb"
+)
+
+var (
+	origin = common.HexToAddress("0x1000")
+	caller = common.HexToAddress("0xaa")
+	callee = common.HexToAddress("0xbb")
+)
+
+// traceCall runs a call from origin to the caller contract, which calls the callee
+// contract storing 1 at slot 0, with the tracer of the name.
+func traceCall(t *testing.T, name string) interface{} {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	statedb.AddBalance(origin, big.NewInt(1000))
+	// CALL(gas, 0xbb, 0, 0, 0, 0, 0)
+	statedb.SetCode(caller, common.Hex2Bytes("6000600060006000600060bb5af100"))
+	// SSTORE(0, 1)
+	statedb.SetCode(callee, common.Hex2Bytes("600160005500"))
+
+	tracer, ok := New(name, statedb.Copy())
+	if !ok {
+		t.Fatalf("no native tracer %s", name)
+	}
+	cfg := &runtime.Config{
+		Origin:    origin,
+		GasLimit:  100000,
+		State:     statedb,
+		EVMConfig: vm.Config{Debug: true, Tracer: tracer},
+	}
+	if _, _, err := runtime.Call(caller, nil, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if statedb.GetState(callee, common.Hash{}) != common.BigToHash(big.NewInt(1)) {
+		t.Fatal("callee storage not set")
+	}
+	result, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestCallTracer(t *testing.T) {
+	root := traceCall(t, "callTracer").(*callFrame)
+	if root.Type != "CALL" || root.From != origin || root.To != caller || root.Error != "" {
+		t.Fatalf("wrong root frame %+v", root)
+	}
+	if len(root.Calls) != 1 {
+		t.Fatalf("root frame has %d calls, want 1", len(root.Calls))
+	}
+	call := root.Calls[0]
+	if call.Type != "CALL" || call.From != caller || call.To != callee || call.GasUsed == 0 {
+		t.Fatalf("wrong sub call frame %+v", call)
+	}
+	if root.GasUsed <= call.GasUsed {
+		t.Fatal("root gas used doesn't include the sub call", root.GasUsed, call.GasUsed)
+	}
+}
+
+// buyCoinDefinition is the ABI of the buyCoinNote method of the privacy coin
+// contract.
+const buyCoinDefinition = `[{"type": "function","name": "buyCoinNote","inputs": [{"name": "OtaAddr","type": "string"},{"name": "Value","type": "uint256"}],"outputs": []}]`
+
+// traceWanCoinCall runs a call of the value from origin to the caller contract,
+// which forwards the input and the value to the privacy coin contract, with the
+// callTracer and returns the call frame of the privacy coin contract.
+func traceWanCoinCall(t *testing.T, statedb *state.StateDB, input []byte, value *big.Int) *callFrame {
+	tracer, _ := New("callTracer", statedb.Copy())
+	cfg := &runtime.Config{
+		Origin:    origin,
+		GasLimit:  1000000,
+		Value:     value,
+		State:     statedb,
+		EVMConfig: vm.Config{Debug: true, Tracer: tracer},
+	}
+	if _, _, err := runtime.Call(caller, input, cfg); err != nil {
+		t.Fatal(err)
+	}
+	result, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := result.(*callFrame)
+	if len(root.Calls) != 1 {
+		t.Fatalf("root frame has %d calls, want 1", len(root.Calls))
+	}
+	return root.Calls[0]
+}
+
+func TestCallTracerWanCoin(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	// CALLDATACOPY(0, 0, CALLDATASIZE)
+	// CALL(GAS, 0x64, CALLVALUE, 0, CALLDATASIZE, 0, 0)
+	statedb.SetCode(caller, common.Hex2Bytes("36600060003760006000366000347300000000000000000000000000000000000000645af100"))
+
+	value, _ := new(big.Int).SetString(vm.Wancoin10, 10)
+	statedb.AddBalance(origin, value)
+
+	// Buy a coin note of the value for an OTA
+	otaKey, _ := crypto.GenerateKey()
+	viewKey, _ := crypto.GenerateKey()
+	ota := keystore.GenerateWaddressFromPK(&otaKey.PublicKey, &viewKey.PublicKey)
+	coinABI, _ := abi.JSON(strings.NewReader(buyCoinDefinition))
+	input, err := coinABI.Pack("buyCoinNote", hexutil.Encode(ota[:]), value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buy := traceWanCoinCall(t, statedb, input, value)
+	if buy.Type != "CALL" || buy.From != caller || buy.To != vm.GetWanCoinSCAddress() || buy.Error != "" {
+		t.Fatalf("wrong buy frame %+v", buy)
+	}
+	if buy.Value == nil || buy.Value.ToInt().Cmp(value) != 0 || buy.GasUsed == 0 {
+		t.Fatalf("wrong buy frame value or gas %+v", buy)
+	}
+	if len(buy.Transfers) != 0 {
+		t.Fatalf("buy frame has transfers %+v", buy.Transfers)
+	}
+	balance, err := vm.GetOtaBalanceFromAX(statedb, ota[1:1+common.HashLength])
+	if err != nil || balance.Cmp(value) != 0 {
+		t.Fatalf("wrong OTA balance %v: %v", balance, err)
+	}
+	if statedb.GetBalance(caller).Sign() != 0 {
+		t.Fatalf("caller keeps the bought value %v", statedb.GetBalance(caller))
+	}
+
+	// Refund the coin note to the caller contract, signing its address
+	publicKeys, keyImage, ws, qs, err := crypto.RingSign(caller.Bytes(), otaKey.D, []*ecdsa.PublicKey{&otaKey.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ringSigned := strings.Join([]string{
+		common.ToHex(crypto.FromECDSAPub(publicKeys[0])),
+		common.ToHex(crypto.FromECDSAPub(keyImage)),
+		hexutil.EncodeBig(ws[0]),
+		hexutil.EncodeBig(qs[0]),
+	}, "+")
+	if input, err = vm.PackRefundCoinInput(ringSigned, value); err != nil {
+		t.Fatal(err)
+	}
+	refund := traceWanCoinCall(t, statedb, input, nil)
+	if refund.To != vm.GetWanCoinSCAddress() || refund.Error != "" || refund.Value.ToInt().Sign() != 0 {
+		t.Fatalf("wrong refund frame %+v", refund)
+	}
+	if len(refund.Transfers) != 1 {
+		t.Fatalf("refund frame has %d transfers, want 1", len(refund.Transfers))
+	}
+	if tr := refund.Transfers[0]; tr.From != vm.GetWanCoinSCAddress() || tr.To != caller || tr.Value.ToInt().Cmp(value) != 0 {
+		t.Fatalf("wrong refund transfer %+v", tr)
+	}
+	if statedb.GetBalance(caller).Cmp(value) != 0 {
+		t.Fatalf("refund not paid to the caller, balance %v", statedb.GetBalance(caller))
+	}
+}
+
+func TestPrestateTracer(t *testing.T) {
+	accounts := traceCall(t, "prestateTracer").(map[common.Address]*account)
+	if acc := accounts[origin]; acc == nil || acc.Balance.ToInt().Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("wrong origin account %+v", acc)
+	}
+	if acc := accounts[caller]; acc == nil || len(acc.Code) == 0 {
+		t.Fatalf("wrong caller account %+v", acc)
+	}
+	acc := accounts[callee]
+	if acc == nil {
+		t.Fatal("callee account not collected")
+	}
+	if value, ok := acc.Storage[common.Hash{}]; !ok || value != (common.Hash{}) {
+		t.Fatalf("wrong callee storage %v", acc.Storage)
+	}
+	if _, ok := accounts[common.Address{}]; ok {
+		t.Fatal("missing coinbase collected")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, ok := New("4byteTracer", nil); ok {
+		t.Fatal("unknown tracer found")
+	}
+}
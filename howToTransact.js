@@ -59,6 +59,9 @@ var ringSignData = wan.genRingSignData(eth.accounts[2], privateKey, mixSetWith0x
 var txRefundData = coinContract.refundCoin.getData(ringSignData, web3.toWei(1))
 eth.sendTransaction({from:eth.accounts[2], to:coinContractAddr, value:0, data:txRefundData, gas: 2000000});
 
+// the same refund in one call, the mix set and the ring signature are built by the node
+//personal.refundOTA(otaAddr, eth.accounts[2], 2, "wanglu");
+
 oldValue1 = web3.fromWei(eth.getBalance(eth.accounts[1]));
 oldValue2 = web3.fromWei(eth.getBalance(eth.accounts[2]));
 
@@ -28,6 +28,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/wanchain/go-wanchain/pos/cfm"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 	posutil "github.com/wanchain/go-wanchain/pos/util"
 
@@ -42,6 +43,8 @@ import (
 	"github.com/wanchain/go-wanchain/common/math"
 	"github.com/wanchain/go-wanchain/consensus/ethash"
 	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/otaindex"
+	"github.com/wanchain/go-wanchain/core/state"
 	"github.com/wanchain/go-wanchain/core/types"
 	"github.com/wanchain/go-wanchain/core/vm"
 	"github.com/wanchain/go-wanchain/crypto"
@@ -233,17 +236,19 @@ func (s *PublicAccountAPI) Accounts() []common.Address {
 // It offers methods to create, (un)lock en list accounts. Some methods accept
 // passwords and are therefore considered private by default.
 type PrivateAccountAPI struct {
-	am        *accounts.Manager
-	nonceLock *AddrLocker
-	b         Backend
+	am         *accounts.Manager
+	nonceLock  *AddrLocker
+	b          Backend
+	otaScanner *otaScanner
 }
 
 // NewPrivateAccountAPI create a new PrivateAccountAPI.
-func NewPrivateAccountAPI(b Backend, nonceLock *AddrLocker) *PrivateAccountAPI {
+func NewPrivateAccountAPI(b Backend, nonceLock *AddrLocker, otaScanner *otaScanner) *PrivateAccountAPI {
 	return &PrivateAccountAPI{
-		am:        b.AccountManager(),
-		nonceLock: nonceLock,
-		b:         b,
+		am:         b.AccountManager(),
+		nonceLock:  nonceLock,
+		b:          b,
+		otaScanner: otaScanner,
 	}
 }
 
@@ -302,21 +307,37 @@ func (s *PrivateAccountAPI) OpenWallet(url string, passphrase *string) error {
 	return wallet.Open(pass)
 }
 
+// DerivedAccount is an account derived by a HD wallet, along with its WAN address
+// made of the public keys of its spend key and of its view key.
+type DerivedAccount struct {
+	accounts.Account
+	WanAddress hexutil.Bytes `json:"wanAddress"`
+}
+
 // DeriveAccount requests a HD wallet to derive a new account, optionally pinning
-// it for later reuse.
-func (s *PrivateAccountAPI) DeriveAccount(url string, path string, pin *bool) (accounts.Account, error) {
+// it for later reuse. The view key of the account is derived too, at the view key
+// path of the requested one, to return the WAN address of the account.
+func (s *PrivateAccountAPI) DeriveAccount(url string, path string, pin *bool) (DerivedAccount, error) {
 	wallet, err := s.am.Wallet(url)
 	if err != nil {
-		return accounts.Account{}, err
+		return DerivedAccount{}, err
 	}
 	derivPath, err := accounts.ParseDerivationPath(path)
 	if err != nil {
-		return accounts.Account{}, err
+		return DerivedAccount{}, err
 	}
 	if pin == nil {
 		pin = new(bool)
 	}
-	return wallet.Derive(derivPath, *pin)
+	account, err := wallet.Derive(derivPath, *pin)
+	if err != nil {
+		return DerivedAccount{}, err
+	}
+	wanAddr, err := wallet.GetWanAddress(account)
+	if err != nil {
+		return DerivedAccount{}, err
+	}
+	return DerivedAccount{Account: account, WanAddress: wanAddr[:]}, nil
 }
 
 // NewAccount will create a new account and returns the address for the new account.
@@ -367,6 +388,63 @@ func (s *PrivateAccountAPI) ImportRawKey(privkey0, privkey1 string, password str
 	return acc.Address, err
 }
 
+// NewMnemonic returns a new random mnemonic. It's not stored, the accounts derived
+// from it with ImportMnemonic can be restored from it.
+func (s *PrivateAccountAPI) NewMnemonic() (string, error) {
+	return keystore.NewMnemonic()
+}
+
+// ImportMnemonic derives the spend and view keys of a WAN account from the mnemonic
+// at path (default m/44'/5718350'/0'/0/0, relative paths are appended to
+// m/44'/5718350'/0'/0) and stores them into the key directory, encrypting them
+// with the passphrase.
+func (s *PrivateAccountAPI) ImportMnemonic(mnemonic string, password string, path *string) (common.Address, error) {
+	derivPath := accounts.DefaultBaseDerivationPath
+	if path != nil {
+		var err error
+		if derivPath, err = accounts.ParseDerivationPath(*path); err != nil {
+			return common.Address{}, err
+		}
+	}
+	acc, err := fetchKeystore(s.am).ImportMnemonic(mnemonic, derivPath, password)
+	return acc.Address, err
+}
+
+// ImportWatchAccount stores the view-only account of the wan address and its hex
+// encoded view key into the key directory, encrypting it with the passphrase. It
+// detects the OTAs sent to the wan address but can't spend them.
+func (s *PrivateAccountAPI) ImportWatchAccount(waddr hexutil.Bytes, viewKey string, password string) (common.Address, error) {
+	sk2, err := crypto.HexToECDSA(strings.TrimPrefix(viewKey, "0x"))
+	if err != nil {
+		return common.Address{}, err
+	}
+	acc, err := fetchKeystore(s.am).ImportWatch(waddr, sk2, password)
+	return acc.Address, err
+}
+
+// ExportWatchAccount returns the view-only key of the account as a JSON key
+// encrypted with newPassword, to be imported with personal_importWatchKey.
+func (s *PrivateAccountAPI) ExportWatchAccount(addr common.Address, password, newPassword string) (string, error) {
+	keyJSON, err := fetchKeystore(s.am).ExportWatch(accounts.Account{Address: addr}, password, newPassword)
+	if err != nil {
+		return "", err
+	}
+	return string(keyJSON), nil
+}
+
+// ImportWatchKey stores the JSON view-only key exported by personal_exportWatchAccount
+// into the key directory, encrypting it with newPassword.
+func (s *PrivateAccountAPI) ImportWatchKey(keyJSON string, password, newPassword string) (common.Address, error) {
+	acc, err := fetchKeystore(s.am).Import([]byte(keyJSON), password, newPassword)
+	return acc.Address, err
+}
+
+// CheckOTA reports whether the OTA was sent to the unlocked account addr, it only
+// needs the view key and works for view-only accounts too.
+func (s *PrivateAccountAPI) CheckOTA(addr common.Address, ota hexutil.Bytes) (bool, error) {
+	return fetchKeystore(s.am).CheckOTAOwner(accounts.Account{Address: addr}, ota)
+}
+
 // UnlockAccount will unlock the account associated with the given address with
 // the given password for duration seconds. If duration is nil it will use a
 // default of 300 seconds. It returns an indication if the account was unlocked.
@@ -819,13 +897,108 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config) ([]byte, *big.Int, bool, error) {
+// OverrideAccount is the set of fields of an account replaced for a call. State
+// replaces the whole storage of the account while StateDiff replaces only the
+// given slots, they are mutually exclusive.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	Balance   **hexutil.Big                `json:"balance"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// StateOverride is the set of accounts replaced in the state of a call.
+type StateOverride map[common.Address]OverrideAccount
+
+// Apply replaces the accounts in the state.
+func (diff *StateOverride) Apply(statedb *state.StateDB) error {
+	if diff == nil {
+		return nil
+	}
+	for addr, account := range *diff {
+		if account.Nonce != nil {
+			statedb.SetNonce(addr, uint64(*account.Nonce))
+		}
+		if account.Code != nil {
+			statedb.SetCode(addr, *account.Code)
+		}
+		if account.Balance != nil {
+			statedb.SetBalance(addr, (*big.Int)(*account.Balance))
+		}
+		if account.State != nil && account.StateDiff != nil {
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+		}
+		if account.State != nil {
+			// Clear the slots not in the replacing storage
+			var keys []common.Hash
+			statedb.ForEachStorage(addr, func(key, value common.Hash) bool {
+				if _, ok := (*account.State)[key]; !ok && value != (common.Hash{}) {
+					keys = append(keys, key)
+				}
+				return true
+			})
+			for _, key := range keys {
+				statedb.SetState(addr, key, common.Hash{})
+			}
+			for key, value := range *account.State {
+				statedb.SetState(addr, key, value)
+			}
+		}
+		if account.StateDiff != nil {
+			for key, value := range *account.StateDiff {
+				statedb.SetState(addr, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// BlockOverrides is the set of header fields replaced for a call. The time also
+// sets the epoch and the slot seen by the WAN pre-compiled contracts.
+type BlockOverrides struct {
+	Number     *hexutil.Big    `json:"number"`
+	Time       *hexutil.Big    `json:"time"`
+	GasLimit   *hexutil.Big    `json:"gasLimit"`
+	Coinbase   *common.Address `json:"coinbase"`
+	Difficulty *hexutil.Big    `json:"difficulty"`
+}
+
+// Apply returns a copy of the header with the fields replaced.
+func (diff *BlockOverrides) Apply(header *types.Header) *types.Header {
+	if diff == nil {
+		return header
+	}
+	header = types.CopyHeader(header)
+	if diff.Number != nil {
+		header.Number = new(big.Int).Set(diff.Number.ToInt())
+	}
+	if diff.Time != nil {
+		header.Time = new(big.Int).Set(diff.Time.ToInt())
+	}
+	if diff.GasLimit != nil {
+		header.GasLimit = new(big.Int).Set(diff.GasLimit.ToInt())
+	}
+	if diff.Coinbase != nil {
+		header.Coinbase = *diff.Coinbase
+	}
+	if diff.Difficulty != nil {
+		header.Difficulty = new(big.Int).Set(diff.Difficulty.ToInt())
+	}
+	return header
+}
+
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, overrides *StateOverride, blockOverrides *BlockOverrides) ([]byte, *big.Int, bool, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
 		return nil, common.Big0, false, err
 	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, common.Big0, false, err
+	}
+	header = blockOverrides.Apply(header)
 	// Set sender address or use a default if none specified
 	addr := args.From
 	if addr == (common.Address{}) {
@@ -884,13 +1057,15 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{DisableGasMetering: true})
+// The optional overrides replace accounts of the state and fields of the header.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides) (hexutil.Bytes, error) {
+	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{DisableGasMetering: true}, overrides, blockOverrides)
 	return (hexutil.Bytes)(result), err
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the given transaction.
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (*hexutil.Big, error) {
+// The optional overrides replace accounts of the state and fields of the header.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, overrides *StateOverride, blockOverrides *BlockOverrides) (*hexutil.Big, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
 		lo uint64 = params.TxGas - 1
@@ -905,13 +1080,16 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (*
 			return nil, err
 		}
 		hi = block.GasLimit().Uint64()
+		if blockOverrides != nil && blockOverrides.GasLimit != nil {
+			hi = blockOverrides.GasLimit.ToInt().Uint64()
+		}
 	}
 	for lo+1 < hi {
 		// Take a guess at the gas, and check transaction validity
 		mid := (hi + lo) / 2
 		(*big.Int)(&args.Gas).SetUint64(mid)
 
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, vm.Config{})
+		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, vm.Config{}, overrides, blockOverrides)
 
 		// If the transaction became invalid or execution failed, raise the gas limit
 		if err != nil || failed {
@@ -1456,7 +1634,7 @@ func (s *PublicTransactionPoolAPI) GetOTAMixSet(ctx context.Context, otaAddr str
 		otaAX, _ = vm.GetAXFromWanAddr(orgOtaAddr)
 	}
 
-	otaByteSet, _, err := vm.GetOTASet(state, otaAX, setLen)
+	otaByteSet, _, err := getOTAMixSet(state, otaAX, setLen)
 	if err != nil {
 		return nil, err
 	}
@@ -1470,6 +1648,16 @@ func (s *PublicTransactionPoolAPI) GetOTAMixSet(ctx context.Context, otaAddr str
 	return ret, nil
 }
 
+// getOTAMixSet picks setLen OTAs of the same value as the OTA of otaAX from the
+// OTA mix set index. The OTA storage is traversed instead while the index is
+// being built or lacks OTAs of the value.
+func getOTAMixSet(state vm.StateDB, otaAX []byte, setLen int) ([][]byte, *big.Int, error) {
+	if set, balance, err := otaindex.GetOTASet(state, otaAX, setLen); err == nil {
+		return set, balance, nil
+	}
+	return vm.GetOTASet(state, otaAX, setLen)
+}
+
 func (s *PublicTransactionPoolAPI) CheckOTAUsed(ctx context.Context, OTAImage string) (bool, error) {
 	if !hexutil.Has0xPrefix(OTAImage) {
 		return false, ErrInvalidOTAImage
@@ -1489,6 +1677,47 @@ func (s *PublicTransactionPoolAPI) CheckOTAUsed(ctx context.Context, OTAImage st
 	return exist, err
 }
 
+// OTAImageSpend is the transaction which revealed an OTA key image.
+type OTAImageSpend struct {
+	TxHash      common.Hash    `json:"txHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxIndex     hexutil.Uint64 `json:"transactionIndex"`
+	Refund      bool           `json:"refund"`
+	Value       *hexutil.Big   `json:"value,omitempty"`
+	Stable      bool           `json:"stable"`
+}
+
+// GetOTAImageSpend returns the refund or privacy transaction which revealed the
+// key image, nil if it isn't spent. Stable tells whether its block is not above
+// the max stable block number of the pos confirmation. It fails while the key
+// image index is catching up with the chain.
+func (s *PublicTransactionPoolAPI) GetOTAImageSpend(ctx context.Context, image hexutil.Bytes) (*OTAImageSpend, error) {
+	if len(image) == 0 {
+		return nil, ErrInvalidOTAImage
+	}
+
+	spend, err := otaindex.GetImageSpend(image)
+	if err != nil || spend == nil {
+		return nil, err
+	}
+
+	result := &OTAImageSpend{
+		TxHash:      spend.TxHash,
+		BlockNumber: hexutil.Uint64(spend.BlockNumber),
+		BlockHash:   spend.BlockHash,
+		TxIndex:     hexutil.Uint64(spend.TxIndex),
+		Refund:      spend.Refund,
+	}
+	if spend.Refund {
+		result.Value = (*hexutil.Big)(spend.Value)
+	}
+	if c := cfm.GetCFM(); c != nil {
+		result.Stable = spend.BlockNumber <= c.GetMaxStableBlkNumber()
+	}
+	return result, nil
+}
+
 // ComputeOTAPPKeys compute ota private key, public key and short address
 // from account address and ota full address.
 func (s *PublicTransactionPoolAPI) ComputeOTAPPKeys(ctx context.Context, address common.Address, inOtaAddr string) (string, error) {
@@ -1865,6 +2094,48 @@ func (s *PrivateAccountAPI) GetOTABalance(ctx context.Context, blockNr rpc.Block
 
 }
 
+// ListOTAs returns the unspent OTAs received by the wan address of addr. The
+// account must be unlocked the first time, its OTAs are then followed in new
+// blocks and kept in a local index. View-only accounts can't compute the key
+// images of their OTAs, so their spent OTAs are listed too.
+func (s *PrivateAccountAPI) ListOTAs(ctx context.Context, addr common.Address) ([]OwnedOTA, error) {
+	return s.otaScanner.list(ctx, addr)
+}
+
+// NewOTAs creates a subscription fired for each OTA received by the wan address
+// of addr in a new block. The account must be unlocked the first time.
+func (s *PrivateAccountAPI) NewOTAs(ctx context.Context, addr common.Address) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	otas := make(chan NewOTAEvent, 16)
+	sub, err := s.otaScanner.subscribe(ctx, addr, otas)
+	if err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-otas:
+				if ev.Account == addr {
+					notifier.Notify(rpcSub.ID, ev.OTA)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 func (s *PrivateAccountAPI) ShowPublicKey(addr common.Address, passwd string) ([]string, error) {
 
 	if len(addr) == 0 {
@@ -4,10 +4,15 @@ package ethapi
 
 import (
 	"context"
+	"math/big"
 	"testing"
 	"time"
 
 	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/ethdb"
 )
 
 func TestGenerateOneTimeAddress(t *testing.T) {
@@ -56,3 +61,43 @@ func TestGenerateOneTimeAddress(t *testing.T) {
 		}
 	}
 }
+
+func TestStateOverride(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	addr := common.HexToAddress("0xaa")
+	one, two := common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))
+	statedb.SetState(addr, one, one)
+	statedb.SetState(addr, two, two)
+
+	nonce := hexutil.Uint64(5)
+	balance := (*hexutil.Big)(big.NewInt(100))
+	storage := map[common.Hash]common.Hash{two: one}
+	overrides := StateOverride{addr: {Nonce: &nonce, Balance: &balance, State: &storage}}
+	if err := overrides.Apply(statedb); err != nil {
+		t.Fatal(err)
+	}
+	if statedb.GetNonce(addr) != 5 || statedb.GetBalance(addr).Cmp(big.NewInt(100)) != 0 {
+		t.Fatal("account not replaced")
+	}
+	if statedb.GetState(addr, one) != (common.Hash{}) || statedb.GetState(addr, two) != one {
+		t.Fatal("storage not replaced")
+	}
+
+	overrides = StateOverride{addr: {State: &storage, StateDiff: &storage}}
+	if err := overrides.Apply(statedb); err == nil {
+		t.Fatal("both state and stateDiff accepted")
+	}
+}
+
+func TestBlockOverrides(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(1), Time: big.NewInt(10), GasLimit: big.NewInt(100), Difficulty: big.NewInt(1)}
+	overrides := &BlockOverrides{Time: (*hexutil.Big)(big.NewInt(20))}
+	cpy := overrides.Apply(header)
+	if cpy.Time.Cmp(big.NewInt(20)) != 0 || cpy.Number.Cmp(big.NewInt(1)) != 0 {
+		t.Fatal("header not overridden", cpy.Time, cpy.Number)
+	}
+	if header.Time.Cmp(big.NewInt(10)) != 0 {
+		t.Fatal("original header modified")
+	}
+}
@@ -74,6 +74,7 @@ type Backend interface {
 
 func GetAPIs(apiBackend Backend) []rpc.API {
 	nonceLock := new(AddrLocker)
+	otaScanner := newOTAScanner(apiBackend)
 	return []rpc.API{
 		{
 			Namespace: "eth",
@@ -122,8 +123,13 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 		}, {
 			Namespace: "personal",
 			Version:   "1.0",
-			Service:   NewPrivateAccountAPI(apiBackend, nonceLock),
+			Service:   NewPrivateAccountAPI(apiBackend, nonceLock, otaScanner),
 			Public:    false,
+		}, {
+			Namespace: "pos",
+			Version:   "1.0",
+			Service:   NewPublicStakingAPI(apiBackend),
+			Public:    true,
 		},
 	}
 }
@@ -0,0 +1,445 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/rlp"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+const (
+	otaIndexVersion = 1
+
+	// maxOTACatchUp is the most blocks scanned to bring a stored index up to
+	// date, a stored index further behind is rebuilt from the state.
+	maxOTACatchUp = 10000
+)
+
+var (
+	otaIndexPrefix = []byte("otaIndex-") // otaIndexPrefix + address -> rlp(otaIndex)
+
+	errNoKeyStore   = errors.New("no keystore backend")
+	errNoBlockState = errors.New("block state not found")
+	errNonCanonical = errors.New("block not canonical")
+)
+
+// otaEntry is an OTA owned by a watched account. KeyImage is empty until the
+// account is unlocked while the OTA is known.
+type otaEntry struct {
+	OTA         []byte
+	Value       *big.Int
+	BlockNumber uint64
+	TxHash      common.Hash
+	KeyImage    []byte
+	Spent       bool
+}
+
+// otaIndex is the stored index of the OTAs of an account, up to date at Number.
+type otaIndex struct {
+	Version uint64
+	Number  uint64
+	Entries []otaEntry
+}
+
+// OwnedOTA is an unspent OTA of a local account. BlockNumber and TxHash are zero
+// if the OTA was found by a state scan, TxHash is also zero if the OTA was bought
+// by a contract calling the privacy coin or stamp contract.
+type OwnedOTA struct {
+	OTA         hexutil.Bytes  `json:"ota"`
+	Value       *hexutil.Big   `json:"value"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	TxHash      common.Hash    `json:"txHash"`
+}
+
+// NewOTAEvent is posted when an OTA of a watched account is found in a new block.
+type NewOTAEvent struct {
+	Account common.Address
+	OTA     OwnedOTA
+}
+
+type otaWatch struct {
+	account accounts.Account
+	pub     *ecdsa.PublicKey
+	viewKey *big.Int
+	index   otaIndex
+}
+
+// otaScanner maintains the OTAs owned by the local accounts that were listed once.
+// A watched account is recognized with its view key, it's rescanned from the
+// state on first use and then follows the OTAs added to the state by new blocks.
+type otaScanner struct {
+	b       Backend
+	mu      sync.Mutex
+	watches map[common.Address]*otaWatch
+	running bool
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+}
+
+func newOTAScanner(b Backend) *otaScanner {
+	return &otaScanner{b: b, watches: make(map[common.Address]*otaWatch)}
+}
+
+func (s *otaScanner) keyStore() (*keystore.KeyStore, error) {
+	backends := s.b.AccountManager().Backends(keystore.KeyStoreType)
+	if len(backends) == 0 {
+		return nil, errNoKeyStore
+	}
+	return backends[0].(*keystore.KeyStore), nil
+}
+
+func otaIndexKey(addr common.Address) []byte {
+	return append(append([]byte{}, otaIndexPrefix...), addr.Bytes()...)
+}
+
+func (s *otaScanner) loadIndex(addr common.Address) *otaIndex {
+	buf, err := s.b.ChainDb().Get(otaIndexKey(addr))
+	if err != nil || len(buf) == 0 {
+		return nil
+	}
+	var index otaIndex
+	if err := rlp.DecodeBytes(buf, &index); err != nil || index.Version != otaIndexVersion {
+		return nil
+	}
+	return &index
+}
+
+func (s *otaScanner) storeIndex(w *otaWatch) {
+	buf, err := rlp.EncodeToBytes(&w.index)
+	if err == nil {
+		err = s.b.ChainDb().Put(otaIndexKey(w.account.Address), buf)
+	}
+	if err != nil {
+		log.Error("Failed to store OTA index", "address", w.account.Address, "err", err)
+	}
+}
+
+// watch returns the watch of the address, the account must be unlocked the first
+// time. s.mu must be held.
+func (s *otaScanner) watch(ctx context.Context, addr common.Address) (*otaWatch, error) {
+	if w, ok := s.watches[addr]; ok {
+		return w, nil
+	}
+
+	ks, err := s.keyStore()
+	if err != nil {
+		return nil, err
+	}
+	account, err := ks.Find(accounts.Account{Address: addr})
+	if err != nil {
+		return nil, err
+	}
+	pub, viewKey, err := ks.GetOTAViewKey(account)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &otaWatch{account: account, pub: pub, viewKey: viewKey}
+	head := s.b.CurrentBlock().NumberU64()
+	if index := s.loadIndex(addr); index != nil && index.Number <= head && head-index.Number <= maxOTACatchUp {
+		w.index = *index
+		if err := s.catchUp(ctx, w, head); err != nil {
+			log.Warn("Failed to scan the OTAs of new blocks", "address", addr, "err", err)
+			w.index = otaIndex{}
+		}
+	}
+	if w.index.Version != otaIndexVersion {
+		if err := s.scanState(ctx, w); err != nil {
+			return nil, err
+		}
+	}
+	s.storeIndex(w)
+
+	s.watches[addr] = w
+	if !s.running {
+		s.running = true
+		go s.loop()
+	}
+	return w, nil
+}
+
+// scanState rebuilds the index of the watch from all the OTAs in the head state.
+func (s *otaScanner) scanState(ctx context.Context, w *otaWatch) error {
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if statedb == nil || err != nil {
+		return err
+	}
+
+	entries := make([]otaEntry, 0)
+	err = vm.ForEachOTA(statedb, func(ota []byte, balance *big.Int) bool {
+		if keystore.IsOTAOwner(w.pub, w.viewKey, ota) {
+			entries = append(entries, otaEntry{OTA: common.CopyBytes(ota), Value: balance})
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	w.index = otaIndex{Version: otaIndexVersion, Number: header.Number.Uint64(), Entries: entries}
+	log.Info("Scanned OTAs from state", "address", w.account.Address, "number", w.index.Number, "count", len(entries))
+	return nil
+}
+
+// catchUp scans the blocks after the index of the watch up to head.
+func (s *otaScanner) catchUp(ctx context.Context, w *otaWatch, head uint64) error {
+	for n := w.index.Number + 1; n <= head; n++ {
+		block, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(n))
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			return errNonCanonical
+		}
+		if _, err := s.scanBlock(ctx, w, block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blockStates returns the states of the block and of its parent, the block must
+// be canonical.
+func (s *otaScanner) blockStates(ctx context.Context, block *types.Block) (*state.StateDB, *state.StateDB, error) {
+	number := block.NumberU64()
+	if number == 0 {
+		return nil, nil, errNoBlockState
+	}
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, rpc.BlockNumber(number))
+	if err != nil {
+		return nil, nil, err
+	}
+	if statedb == nil || header == nil {
+		return nil, nil, errNoBlockState
+	}
+	if header.Hash() != block.Hash() {
+		return nil, nil, errNonCanonical
+	}
+	parent, parentHeader, err := s.b.StateAndHeaderByNumber(ctx, rpc.BlockNumber(number-1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent == nil || parentHeader == nil {
+		return nil, nil, errNoBlockState
+	}
+	if parentHeader.Hash() != block.ParentHash() {
+		return nil, nil, errNonCanonical
+	}
+	return parent, statedb, nil
+}
+
+// scanBlock adds the OTAs of the watch added to the state by the block, and returns
+// them. The transaction of an OTA is known if it called the privacy coin or stamp
+// contract directly.
+func (s *otaScanner) scanBlock(ctx context.Context, w *otaWatch, block *types.Block) ([]otaEntry, error) {
+	parent, statedb, err := s.blockStates(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []otaEntry
+	err = vm.ForEachNewOTA(parent, statedb, func(ota []byte, balance *big.Int) bool {
+		if keystore.IsOTAOwner(w.pub, w.viewKey, ota) && !w.index.has(ota) {
+			found = append(found, otaEntry{
+				OTA:         common.CopyBytes(ota),
+				Value:       balance,
+				BlockNumber: block.NumberU64(),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != 0 {
+		s.findBuyTxs(ctx, block, found)
+	}
+
+	w.index.Entries = append(w.index.Entries, found...)
+	w.index.Number = block.NumberU64()
+	return found, nil
+}
+
+// findBuyTxs sets the hash of the successful transaction of the block buying
+// each OTA by a direct call of the privacy coin or stamp contract.
+func (s *otaScanner) findBuyTxs(ctx context.Context, block *types.Block, entries []otaEntry) {
+	var receipts types.Receipts
+	for i, tx := range block.Transactions() {
+		if tx.To() == nil {
+			continue
+		}
+
+		var (
+			ota []byte
+			err error
+		)
+		switch *tx.To() {
+		case vm.GetWanCoinSCAddress():
+			ota, _, err = vm.DecodeBuyCoinInput(tx.Data())
+		case vm.GetWanStampSCAddress():
+			ota, _, err = vm.DecodeBuyStampInput(tx.Data())
+		default:
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		for j := range entries {
+			e := &entries[j]
+			if e.TxHash != (common.Hash{}) || !bytes.Equal(e.OTA, ota) {
+				continue
+			}
+			if receipts == nil {
+				receipts, _ = s.b.GetReceipts(ctx, block.Hash())
+			}
+			if i < len(receipts) && receipts[i].Status == types.ReceiptStatusSuccessful {
+				e.TxHash = tx.Hash()
+			}
+		}
+	}
+}
+
+func (index *otaIndex) has(ota []byte) bool {
+	for i := range index.Entries {
+		if common.Bytes2Hex(index.Entries[i].OTA) == common.Bytes2Hex(ota) {
+			return true
+		}
+	}
+	return false
+}
+
+// refresh computes the missing key images if the account is unlocked and marks
+// the spent OTAs, then returns the unspent OTAs existing in the state.
+func (s *otaScanner) refresh(w *otaWatch, statedb *state.StateDB) []OwnedOTA {
+	ks, _ := s.keyStore()
+	ret := make([]OwnedOTA, 0)
+	changed := false
+
+	for i := range w.index.Entries {
+		e := &w.index.Entries[i]
+		if e.Spent {
+			continue
+		}
+
+		if len(e.KeyImage) == 0 && ks != nil {
+			if image, err := ks.ComputeOTAKeyImage(w.account, e.OTA); err == nil {
+				e.KeyImage, changed = image, true
+			}
+		}
+		if len(e.KeyImage) != 0 {
+			if spent, _, err := vm.CheckOTAImageExist(statedb, e.KeyImage); err == nil && spent {
+				e.Spent, changed = true, true
+				continue
+			}
+		}
+
+		// an OTA of a block reorganized away isn't in the state
+		ax, _ := vm.GetAXFromWanAddr(e.OTA)
+		if exist, _, err := vm.CheckOTAAXExist(statedb, ax); err != nil || !exist {
+			continue
+		}
+		ret = append(ret, e.toOwnedOTA())
+	}
+
+	if changed {
+		s.storeIndex(w)
+	}
+	return ret
+}
+
+func (e *otaEntry) toOwnedOTA() OwnedOTA {
+	return OwnedOTA{
+		OTA:         e.OTA,
+		Value:       (*hexutil.Big)(e.Value),
+		BlockNumber: hexutil.Uint64(e.BlockNumber),
+		TxHash:      e.TxHash,
+	}
+}
+
+// list returns the unspent OTAs of the address at the head state.
+func (s *otaScanner) list(ctx context.Context, addr common.Address) ([]OwnedOTA, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, err := s.watch(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	statedb, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	return s.refresh(w, statedb), nil
+}
+
+// subscribe starts watching the address and subscribes to its new OTAs.
+func (s *otaScanner) subscribe(ctx context.Context, addr common.Address, ch chan<- NewOTAEvent) (event.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.watch(ctx, addr); err != nil {
+		return nil, err
+	}
+	return s.scope.Track(s.feed.Subscribe(ch)), nil
+}
+
+func (s *otaScanner) loop() {
+	chainCh := make(chan core.ChainEvent, 16)
+	sub := s.b.SubscribeChainEvent(chainCh)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-chainCh:
+			s.processBlock(ev.Block)
+		case <-sub.Err():
+			s.mu.Lock()
+			s.running = false
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (s *otaScanner) processBlock(block *types.Block) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []NewOTAEvent
+	for addr, w := range s.watches {
+		// blocks of a reorganization are scanned again, known OTAs are skipped
+		found, err := s.scanBlock(context.Background(), w, block)
+		if err != nil {
+			log.Warn("Failed to scan the OTAs of a block", "address", addr, "number", block.NumberU64(), "err", err)
+			continue
+		}
+		for i := range found {
+			events = append(events, NewOTAEvent{Account: addr, OTA: found[i].toOwnedOTA()})
+		}
+		s.storeIndex(w)
+	}
+
+	for _, ev := range events {
+		log.Info("New OTA received", "address", ev.Account, "ota", ev.OTA.OTA, "tx", ev.OTA.TxHash)
+		s.feed.Send(ev)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/accounts/abi"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// buyCoinDefinition is the ABI of the buyCoinNote method of the privacy coin
+// contract.
+const buyCoinDefinition = `[{"type": "function","name": "buyCoinNote","inputs": [{"name": "OtaAddr","type": "string"},{"name": "Value","type": "uint256"}],"outputs": []}]`
+
+// otaTestBackend serves the states and receipts of a block and of its parent to
+// the OTA scanner.
+type otaTestBackend struct {
+	Backend
+	headers  map[uint64]*types.Header
+	states   map[uint64]*state.StateDB
+	receipts types.Receipts
+}
+
+func (b *otaTestBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return b.states[uint64(blockNr)], b.headers[uint64(blockNr)], nil
+}
+
+func (b *otaTestBackend) GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error) {
+	return b.receipts, nil
+}
+
+// newTestOTA returns a new OTA of the wan address of the spend key A and of the
+// view key B.
+func newTestOTA(t *testing.T, A, B *ecdsa.PublicKey) []byte {
+	keys := hexutil.PKPair2HexSlice(A, B)
+	ota, err := crypto.GenerateOneTimeKey(keys[0], keys[1], keys[2], keys[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := hexutil.Decode("0x" + strings.Replace(strings.Join(ota, ""), "0x", "", -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanAddr, err := keystore.WaddrFromUncompressedRawBytes(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wanAddr[:]
+}
+
+func TestOTAScannerScanBlock(t *testing.T) {
+	var (
+		spendKey, _ = crypto.GenerateKey()
+		viewKey, _  = crypto.GenerateKey()
+		otherKey, _ = crypto.GenerateKey()
+		coinABI, _  = abi.JSON(strings.NewReader(buyCoinDefinition))
+		value, _    = new(big.Int).SetString(vm.Wancoin10, 10)
+		coinAddr    = vm.GetWanCoinSCAddress()
+		proxyAddr   = common.HexToAddress("0x1234")
+	)
+	buyCoin := func(ota []byte) []byte {
+		input, err := coinABI.Pack("buyCoinNote", hexutil.Encode(ota), value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return input
+	}
+	var (
+		old      = newTestOTA(t, &spendKey.PublicKey, &viewKey.PublicKey)
+		owned    = newTestOTA(t, &spendKey.PublicKey, &viewKey.PublicKey)
+		failed   = newTestOTA(t, &spendKey.PublicKey, &viewKey.PublicKey)
+		proxied  = newTestOTA(t, &spendKey.PublicKey, &viewKey.PublicKey)
+		notOwned = newTestOTA(t, &otherKey.PublicKey, &otherKey.PublicKey)
+		txs      = []*types.Transaction{
+			types.NewTransaction(0, coinAddr, value, big.NewInt(100000), big.NewInt(1), buyCoin(notOwned)),
+			types.NewTransaction(1, coinAddr, value, big.NewInt(100000), big.NewInt(1), buyCoin(owned)),
+			types.NewTransaction(2, coinAddr, value, big.NewInt(100000), big.NewInt(1), buyCoin(failed)),
+			// a contract calling the privacy coin contract
+			types.NewTransaction(3, proxyAddr, value, big.NewInt(100000), big.NewInt(1), buyCoin(proxied)),
+		}
+		receipts = types.Receipts{
+			{Status: types.ReceiptStatusSuccessful},
+			{Status: types.ReceiptStatusSuccessful},
+			{Status: types.ReceiptStatusFailed},
+			{Status: types.ReceiptStatusSuccessful},
+		}
+		parentHeader = &types.Header{Number: big.NewInt(6)}
+		block        = types.NewBlock(&types.Header{Number: big.NewInt(7), ParentHash: parentHeader.Hash()}, txs, nil, receipts)
+	)
+
+	// The block adds the OTAs of the successful transactions to the state
+	db, _ := ethdb.NewMemDatabase()
+	parent, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	vm.AddOTAIfNotExist(parent, value, old)
+	root, _ := parent.CommitTo(db, false)
+	parent, _ = state.New(root, state.NewDatabase(db))
+	statedb, _ := state.New(root, state.NewDatabase(db))
+	for _, ota := range [][]byte{notOwned, owned, proxied} {
+		vm.AddOTAIfNotExist(statedb, value, ota)
+	}
+	root, _ = statedb.CommitTo(db, false)
+	statedb, _ = state.New(root, state.NewDatabase(db))
+
+	s := newOTAScanner(&otaTestBackend{
+		headers:  map[uint64]*types.Header{6: parentHeader, 7: block.Header()},
+		states:   map[uint64]*state.StateDB{6: parent, 7: statedb},
+		receipts: receipts,
+	})
+	w := &otaWatch{pub: &spendKey.PublicKey, viewKey: viewKey.D}
+	found, err := s.scanBlock(context.Background(), w, block)
+	if err != nil {
+		t.Fatalf("failed to scan block: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("found %d OTAs, want 2", len(found))
+	}
+	want := map[string]common.Hash{common.Bytes2Hex(owned): txs[1].Hash(), common.Bytes2Hex(proxied): {}}
+	for _, e := range found {
+		txHash, ok := want[common.Bytes2Hex(e.OTA)]
+		if !ok || !w.index.has(e.OTA) {
+			t.Fatalf("wrong OTA found %x", e.OTA)
+		}
+		if e.Value.Cmp(value) != 0 || e.BlockNumber != 7 || e.TxHash != txHash {
+			t.Fatalf("wrong OTA entry %+v", e)
+		}
+	}
+	if w.index.Number != 7 {
+		t.Fatalf("index number %d, want 7", w.index.Number)
+	}
+
+	// Scanning the block again, as after a reorganization, doesn't add it twice
+	if found, err := s.scanBlock(context.Background(), w, block); err != nil || len(found) != 0 || len(w.index.Entries) != 2 {
+		t.Fatalf("OTA added again, found %d, index has %d, err %v", len(found), len(w.index.Entries), err)
+	}
+
+	// A block that isn't canonical anymore isn't scanned
+	side := types.NewBlock(&types.Header{Number: big.NewInt(7), ParentHash: parentHeader.Hash(), Extra: []byte{1}}, txs, nil, receipts)
+	if _, err := s.scanBlock(context.Background(), w, side); err != errNonCanonical {
+		t.Fatalf("side block scanned, err %v", err)
+	}
+}
@@ -0,0 +1,518 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/common/math"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/rlp"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+var (
+	ErrOTANotOwned     = errors.New("OTA isn't owned by the account")
+	ErrRefundCallFail  = errors.New("refund call fails")
+	ErrNoStamp         = errors.New("no stamp is given")
+	ErrStampNotEnough  = errors.New("no stamp has enough gas for the call")
+	ErrPrivacyCallFail = errors.New("privacy contract call fails")
+)
+
+// otaPrivateKey returns the one-time private key of the OTA owned by key, its
+// public key is the A1 of the OTA.
+func otaPrivateKey(key *keystore.Key, ota []byte) (*ecdsa.PrivateKey, error) {
+	if key.IsWatchOnly() {
+		return nil, keystore.ErrWatchOnly
+	}
+	if key.PrivateKey == nil || key.PrivateKey2 == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	if !keystore.IsOTAOwner(&key.PrivateKey.PublicKey, key.PrivateKey2.D, ota) {
+		return nil, ErrOTANotOwned
+	}
+
+	A1, S1, err := keystore.GeneratePKPairFromWAddress(ota)
+	if err != nil {
+		return nil, err
+	}
+	otaKey, _, err := crypto.GenerateOneTimePrivateKey2528(key.PrivateKey, key.PrivateKey2, A1, S1)
+	if err != nil {
+		return nil, err
+	}
+	otaKey.PublicKey = *A1
+	return otaKey, nil
+}
+
+// ringSignOTA ring signs hashMsg with the key of the OTA, hidden among mixSize OTAs
+// of the same value. It returns the ring signed data and the value of the OTA.
+func ringSignOTA(state vm.StateDB, otaKey *ecdsa.PrivateKey, ota []byte, mixSize int, hashMsg []byte) (string, *big.Int, error) {
+	if mixSize <= 0 {
+		return "", nil, ErrInvalidOTAMixNum
+	}
+	if uint64(mixSize) > params.GetOTAMixSetMaxSize {
+		return "", nil, ErrReqTooManyOTAMix
+	}
+
+	image := crypto.FromECDSAPub(crypto.KeyImage(otaKey.D, &otaKey.PublicKey))
+	if used, _, err := vm.CheckOTAImageExist(state, image); err != nil {
+		return "", nil, err
+	} else if used {
+		return "", nil, vm.ErrOTAReused
+	}
+
+	ax, err := vm.GetAXFromWanAddr(ota)
+	if err != nil {
+		return "", nil, err
+	}
+	mixSet, value, err := getOTAMixSet(state, ax, mixSize)
+	if err != nil {
+		return "", nil, err
+	}
+	mixWanAddrs := make([]string, 0, len(mixSet))
+	for _, mix := range mixSet {
+		mixWanAddrs = append(mixWanAddrs, common.ToHex(mix))
+	}
+
+	ringSignedData, err := genRingSignData(hashMsg, otaKey.D.Bytes(), &otaKey.PublicKey, mixWanAddrs)
+	if err != nil {
+		return "", nil, err
+	}
+	return ringSignedData, value, nil
+}
+
+// buildRefundTx returns the unsigned wanCoinSC.refundCoin transaction refunding the
+// OTA to the account to. The OTA must be owned by to, whose key is decrypted with
+// passwd, and the mixSize OTAs of the ring are picked among the ones of the same
+// denomination.
+func (s *PrivateAccountAPI) buildRefundTx(ctx context.Context, ota []byte, to common.Address, mixSize int, passwd string) (*types.Transaction, error) {
+	if len(ota) != common.WAddressLength {
+		return nil, ErrInvalidOTAAddr
+	}
+
+	ks := fetchKeystore(s.am)
+	account, err := ks.Find(accounts.Account{Address: to})
+	if err != nil {
+		return nil, err
+	}
+	key, err := ks.GetKey(account, passwd)
+	if err != nil {
+		return nil, err
+	}
+	otaKey, err := otaPrivateKey(key, ota)
+	if err != nil {
+		return nil, err
+	}
+
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+
+	// the contract checks the ring signature over the address of the sender
+	ringSignedData, value, err := ringSignOTA(state, otaKey, ota, mixSize, to.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	input, err := vm.PackRefundCoinInput(ringSignedData, value)
+	if err != nil {
+		return nil, err
+	}
+
+	coinAddr := vm.GetWanCoinSCAddress()
+	callArgs := CallArgs{From: to, To: &coinAddr, Data: input}
+	bc := NewPublicBlockChainAPI(s.b)
+	gas, err := bc.EstimateGas(ctx, callArgs, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	callArgs.Gas = *gas
+	if _, _, failed, err := bc.doCall(ctx, callArgs, rpc.PendingBlockNumber, vm.Config{}, nil, nil); err != nil {
+		return nil, err
+	} else if failed {
+		return nil, ErrRefundCallFail
+	}
+
+	sendArgs := SendTxArgs{
+		From: to,
+		To:   &coinAddr,
+		Gas:  gas,
+		Data: input,
+	}
+	if err := sendArgs.setDefaults(ctx, s.b); err != nil {
+		return nil, err
+	}
+	return sendArgs.toTransaction(), nil
+}
+
+// RefundOTA refunds the OTA otaAddr of the privacy coin contract to the account to,
+// which must own it. The ring signature hides it among mixSize OTAs of the same
+// value, the transaction is checked, signed with passwd and submitted.
+func (s *PrivateAccountAPI) RefundOTA(ctx context.Context, otaAddr hexutil.Bytes, to common.Address, mixSize int, passwd string) (common.Hash, error) {
+	account := accounts.Account{Address: to}
+	wallet, err := s.am.Find(account)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	// Hold the addresse's mutex around signing to prevent concurrent assignment of
+	// the same nonce to multiple accounts.
+	s.nonceLock.LockAddr(to)
+	defer s.nonceLock.UnlockAddr(to)
+
+	tx, err := s.buildRefundTx(ctx, otaAddr, to, mixSize, passwd)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	var chainID *big.Int
+	if config := s.b.ChainConfig(); config != nil {
+		chainID = config.ChainId
+	}
+	signed, err := wallet.SignTxWithPassphrase(account, passwd, tx, chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, s.b, signed)
+}
+
+// PrivacyCallArgs represents a contract call to be sent as a privacy transaction,
+// whose gas is paid by a stamp ring signed with mixSize other stamps.
+type PrivacyCallArgs struct {
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"`
+	Data     hexutil.Bytes  `json:"data"`
+	GasPrice *hexutil.Big   `json:"gasPrice"`
+	MixSize  hexutil.Uint64 `json:"mixSize"`
+}
+
+// PrivacyTxFee is the gas of a privacy transaction. CallGas is the gas of the
+// contract call alone, RequiredGas adds the ring signature, the key image and the
+// larger payload the stamp must pay for.
+type PrivacyTxFee struct {
+	CallGas     hexutil.Uint64 `json:"callGas"`
+	RequiredGas hexutil.Uint64 `json:"requiredGas"`
+	GasPrice    *hexutil.Big   `json:"gasPrice"`
+	StampValue  *hexutil.Big   `json:"stampValue"`
+}
+
+// maxRingSignedDataLen returns the longest ring signed data of a ring of n keys.
+func maxRingSignedDataLen(n int) int {
+	const (
+		pkLen  = 2 + 2*65 // hex public keys and key image
+		bigLen = 2 + 2*32 // hex w and q
+	)
+	return n*pkLen + (n - 1) + pkLen + 2*(n*bigLen+n-1) + 3
+}
+
+// privacyTxData packs the ring signed data and the contract call data in the
+// payload of a privacy transaction.
+func privacyTxData(ringSignedData string, callData []byte) ([]byte, error) {
+	return core.TokenAbi.Pack("combine", ringSignedData, callData)
+}
+
+// privacyRequiredGas returns the stamp gas the privacy transaction of payload
+// needs, for a ring of mixSize+1 stamps and a contract call of callGas.
+func privacyRequiredGas(payload []byte, callData []byte, to common.Address, mixSize int, callGas uint64, istanbul bool) uint64 {
+	ringGas := params.RequiredGasPerMixPub*uint64(mixSize+1) + params.SstoreSetGas
+	// the intrinsic gas is charged on the whole payload instead of the call data
+	intrinsic := core.IntrinsicGas(payload, &to, true, istanbul).Uint64()
+	execGas := callGas - core.IntrinsicGas(callData, &to, true, istanbul).Uint64()
+	return ringGas + intrinsic + execGas
+}
+
+// pendingIstanbul reports whether the pending block is past the Istanbul fork,
+// which reprices the transaction data.
+func pendingIstanbul(b Backend) bool {
+	config := b.ChainConfig()
+	return config != nil && config.IsIstanbul(new(big.Int).Add(b.CurrentBlock().Number(), common.Big1))
+}
+
+// privacyCall executes the contract call of args with gas at the pending state. A
+// privacy transaction doesn't pay gas from the sender, it's credited before.
+func privacyCall(ctx context.Context, b Backend, args *PrivacyCallArgs, gas uint64) (bool, error) {
+	state, header, err := b.StateAndHeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if state == nil || err != nil {
+		return false, err
+	}
+	gasLimit := new(big.Int).SetUint64(gas)
+	gasPrice := args.GasPrice.ToInt()
+	state.AddBalance(args.From, new(big.Int).Mul(gasLimit, gasPrice))
+
+	to := args.To
+	msg := types.NewMessage(args.From, &to, 0, common.Big0, gasLimit, gasPrice, args.Data, false)
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header, vm.Config{})
+	if err != nil {
+		return false, err
+	}
+	gp := new(core.GasPool).AddGas(math.MaxBig256)
+	_, _, failed, err := core.ApplyMessage(evm, msg, gp)
+	if err := vmError(); err != nil {
+		return false, err
+	}
+	return failed, err
+}
+
+// estimatePrivacyCallGas returns the gas of the contract call of args, intrinsic gas
+// included.
+func estimatePrivacyCallGas(ctx context.Context, b Backend, args *PrivacyCallArgs) (uint64, error) {
+	block, err := b.BlockByNumber(ctx, rpc.PendingBlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	lo, hi := params.TxGas-1, block.GasLimit().Uint64()
+	if failed, err := privacyCall(ctx, b, args, hi); err != nil {
+		return 0, err
+	} else if failed {
+		return 0, ErrPrivacyCallFail
+	}
+
+	// Binary search the gas requirement, as it may be higher than the amount used
+	for lo+1 < hi {
+		mid := (hi + lo) / 2
+		if failed, err := privacyCall(ctx, b, args, mid); err != nil || failed {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi, nil
+}
+
+// privacyFee estimates the call gas and the required stamp gas of args, it fills
+// the default gas price and mix size.
+func privacyFee(ctx context.Context, b Backend, args *PrivacyCallArgs) (*PrivacyTxFee, error) {
+	if args.GasPrice == nil {
+		price, err := b.SuggestPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args.GasPrice = (*hexutil.Big)(price)
+	}
+	if args.GasPrice.ToInt().Sign() <= 0 {
+		return nil, vm.ErrInvalidGasPrice
+	}
+	if args.MixSize == 0 || uint64(args.MixSize) > params.GetOTAMixSetMaxSize {
+		return nil, ErrInvalidOTAMixNum
+	}
+
+	callGas, err := estimatePrivacyCallGas(ctx, b, args)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := privacyTxData(strings.Repeat("f", maxRingSignedDataLen(int(args.MixSize)+1)), args.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivacyTxFee{
+		CallGas:     hexutil.Uint64(callGas),
+		RequiredGas: hexutil.Uint64(privacyRequiredGas(payload, args.Data, args.To, int(args.MixSize), callGas, pendingIstanbul(b))),
+		GasPrice:    args.GasPrice,
+	}, nil
+}
+
+// stampGas returns the gas a stamp of value pays at gasPrice.
+func stampGas(value *big.Int, gasPrice *big.Int) uint64 {
+	gas := new(big.Int).Div(value, gasPrice)
+	if gas.BitLen() > 64 {
+		return ^uint64(0)
+	}
+	return gas.Uint64()
+}
+
+// EstimatePrivacyTxFee estimates the gas of the contract call of args sent as a
+// privacy transaction, and returns the smallest supported stamp value paying it.
+func (s *PublicTransactionPoolAPI) EstimatePrivacyTxFee(ctx context.Context, args PrivacyCallArgs) (*PrivacyTxFee, error) {
+	fee, err := privacyFee(ctx, s.b, &args)
+	if err != nil {
+		return nil, err
+	}
+
+	stamps := vm.GetSupportStampOTABalances()
+	sort.Slice(stamps, func(i, j int) bool { return stamps[i].Cmp(stamps[j]) < 0 })
+	for _, value := range stamps {
+		if stampGas(value, fee.GasPrice.ToInt()) >= uint64(fee.RequiredGas) {
+			fee.StampValue = (*hexutil.Big)(value)
+			return fee, nil
+		}
+	}
+	return nil, ErrStampNotEnough
+}
+
+// PrivacyTxArgs represents the arguments to build a privacy transaction calling a
+// contract. Account is the keystore account owning the stamps, the transaction is
+// sent from SenderOTA if it's set, which must be owned by Account as well, or from
+// Account otherwise.
+type PrivacyTxArgs struct {
+	Account   common.Address  `json:"account"`
+	SenderOTA hexutil.Bytes   `json:"senderOTA"`
+	To        common.Address  `json:"to"`
+	Data      hexutil.Bytes   `json:"data"`
+	GasPrice  *hexutil.Big    `json:"gasPrice"`
+	Nonce     *hexutil.Uint64 `json:"nonce"`
+	Stamps    []hexutil.Bytes `json:"stamps"`
+	MixSize   hexutil.Uint64  `json:"mixSize"`
+}
+
+// PrivacyTxResult is a signed privacy transaction, the stamp paying it and its fee.
+type PrivacyTxResult struct {
+	Raw   hexutil.Bytes      `json:"raw"`
+	Tx    *types.Transaction `json:"tx"`
+	Stamp hexutil.Bytes      `json:"stamp"`
+	Fee   *PrivacyTxFee      `json:"fee"`
+}
+
+// BuildPrivacyTx builds the privacy transaction calling args.To with args.Data. The
+// smallest stamp of args.Stamps paying the call is ring signed over the address of
+// the sender, which the chain checks, and the transaction is signed with passwd
+// but not sent.
+func (s *PrivateAccountAPI) BuildPrivacyTx(ctx context.Context, args PrivacyTxArgs, passwd string) (*PrivacyTxResult, error) {
+	if len(args.Stamps) == 0 {
+		return nil, ErrNoStamp
+	}
+
+	ks := fetchKeystore(s.am)
+	account, err := ks.Find(accounts.Account{Address: args.Account})
+	if err != nil {
+		return nil, err
+	}
+	key, err := ks.GetKey(account, passwd)
+	if err != nil {
+		return nil, err
+	}
+	if key.IsWatchOnly() {
+		return nil, keystore.ErrWatchOnly
+	}
+
+	from := args.Account
+	var senderKey *ecdsa.PrivateKey
+	if len(args.SenderOTA) != 0 {
+		if senderKey, err = otaPrivateKey(key, args.SenderOTA); err != nil {
+			return nil, err
+		}
+		from = crypto.PubkeyToAddress(senderKey.PublicKey)
+	}
+
+	callArgs := &PrivacyCallArgs{From: from, To: args.To, Data: args.Data, GasPrice: args.GasPrice, MixSize: args.MixSize}
+	fee, err := privacyFee(ctx, s.b, callArgs)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice := fee.GasPrice.ToInt()
+
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+
+	type stamp struct {
+		ota   []byte
+		value *big.Int
+	}
+	stamps := make([]stamp, 0, len(args.Stamps))
+	for _, ota := range args.Stamps {
+		ax, err := vm.GetAXFromWanAddr(ota)
+		if err != nil {
+			return nil, err
+		}
+		value, err := vm.GetOtaBalanceFromAX(state, ax)
+		if err != nil {
+			return nil, err
+		}
+		if value.Sign() > 0 && stampGas(value, gasPrice) >= uint64(fee.RequiredGas) {
+			stamps = append(stamps, stamp{ota, value})
+		}
+	}
+	sort.Slice(stamps, func(i, j int) bool { return stamps[i].value.Cmp(stamps[j].value) < 0 })
+
+	var (
+		payload []byte
+		chosen  *stamp
+		lastErr = ErrStampNotEnough
+	)
+	for i := range stamps {
+		otaKey, err := otaPrivateKey(key, stamps[i].ota)
+		if err != nil {
+			return nil, err
+		}
+		ringSignedData, _, err := ringSignOTA(state, otaKey, stamps[i].ota, int(args.MixSize), from.Bytes())
+		if err != nil {
+			// spent stamps or denominations without enough mixes are skipped
+			lastErr = err
+			continue
+		}
+		if payload, err = privacyTxData(ringSignedData, args.Data); err != nil {
+			return nil, err
+		}
+		required := privacyRequiredGas(payload, args.Data, args.To, int(args.MixSize), uint64(fee.CallGas), pendingIstanbul(s.b))
+		if stampGas(stamps[i].value, gasPrice) >= required {
+			fee.RequiredGas = hexutil.Uint64(required)
+			chosen = &stamps[i]
+			break
+		}
+	}
+	if chosen == nil {
+		return nil, lastErr
+	}
+	fee.StampValue = (*hexutil.Big)(chosen.value)
+
+	if args.Nonce == nil {
+		s.nonceLock.LockAddr(from)
+		defer s.nonceLock.UnlockAddr(from)
+
+		nonce, err := s.b.GetPoolNonce(ctx, from)
+		if err != nil {
+			return nil, err
+		}
+		args.Nonce = (*hexutil.Uint64)(&nonce)
+	}
+	// the whole stamp is spent, the gas limit must cover it
+	gas := new(big.Int).SetUint64(stampGas(chosen.value, gasPrice))
+	tx := types.NewOTATransaction(uint64(*args.Nonce), args.To, common.Big0, gas, gasPrice, payload)
+
+	var chainID *big.Int
+	if config := s.b.ChainConfig(); config != nil {
+		chainID = config.ChainId
+	}
+	var signed *types.Transaction
+	if senderKey != nil {
+		signed, err = types.SignTx(tx, types.NewEIP155Signer(chainID), senderKey)
+	} else {
+		signed, err = types.SignTx(tx, types.NewEIP155Signer(chainID), key.PrivateKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivacyTxResult{Raw: data, Tx: signed, Stamp: chosen.ota, Fee: fee}, nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/crypto"
+)
+
+func TestMaxRingSignedDataLen(t *testing.T) {
+	for n := 1; n <= 10; n++ {
+		pks := make([]*ecdsa.PublicKey, n)
+		var x *ecdsa.PrivateKey
+		for i := range pks {
+			sk, _ := crypto.GenerateKey()
+			if i == 0 {
+				x = sk
+			}
+			pks[i] = &sk.PublicKey
+		}
+
+		retPks, image, w, q, err := crypto.RingSign(crypto.Keccak256([]byte("sender")), x.D, pks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := encodeRingSignOut(retPks, image, w, q)
+		if len(data) > maxRingSignedDataLen(n) {
+			t.Fatal("ring signed data longer than estimated", n, len(data), maxRingSignedDataLen(n))
+		}
+	}
+}
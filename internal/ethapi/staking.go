@@ -0,0 +1,396 @@
+// Copyright 2018 Wanchain Foundation Ltd
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	bn256 "github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/rlp"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+var (
+	ErrStakingKeyMissing = errors.New("secPk and bn256Pk are required, or use personal_buildStakeTx to derive them from the keystore")
+	ErrStakingNoAccount  = errors.New("validator account not found in keystore")
+)
+
+// StakingTxArgs represents the arguments to build a pos staking contract transaction.
+// Method is one of stakeIn, stakeRegister, stakeAppend, stakeUpdate, stakeUpdateFeeRate,
+// partnerIn, delegateIn and delegateOut.
+type StakingTxArgs struct {
+	From       common.Address  `json:"from"`
+	Method     string          `json:"method"`
+	Validator  *common.Address `json:"validator"`
+	SecPk      hexutil.Bytes   `json:"secPk"`
+	Bn256Pk    hexutil.Bytes   `json:"bn256Pk"`
+	LockEpochs hexutil.Uint64  `json:"lockEpochs"`
+	FeeRate    hexutil.Uint64  `json:"feeRate"`
+	MaxFeeRate *hexutil.Uint64 `json:"maxFeeRate"`
+	Renewal    bool            `json:"renewal"`
+	Gas        *hexutil.Big    `json:"gas"`
+	GasPrice   *hexutil.Big    `json:"gasPrice"`
+	Value      *hexutil.Big    `json:"value"`
+	Nonce      *hexutil.Uint64 `json:"nonce"`
+}
+
+// toStakingCall converts the arguments to the staking contract call. For stakeIn and
+// stakeRegister the validator is the address of secPk.
+func (args *StakingTxArgs) toStakingCall() (*vm.StakingCall, error) {
+	call := &vm.StakingCall{
+		Method:     args.Method,
+		SecPk:      args.SecPk,
+		Bn256Pk:    args.Bn256Pk,
+		LockEpochs: uint64(args.LockEpochs),
+		FeeRate:    uint64(args.FeeRate),
+		MaxFeeRate: uint64(args.FeeRate),
+		Renewal:    args.Renewal,
+	}
+	if args.MaxFeeRate != nil {
+		call.MaxFeeRate = uint64(*args.MaxFeeRate)
+	}
+
+	switch args.Method {
+	case "stakeIn", "stakeRegister":
+		if len(args.SecPk) == 0 || len(args.Bn256Pk) == 0 {
+			return nil, ErrStakingKeyMissing
+		}
+	default:
+		if args.Validator == nil {
+			return nil, errors.New("validator must be given for " + args.Method)
+		}
+		call.Validator = *args.Validator
+	}
+	return call, nil
+}
+
+// runStakingCall executes the staking contract call on the state, so that the
+// call is checked by the contract itself.
+func runStakingCall(ctx context.Context, b Backend, state *state.StateDB, header *types.Header, from common.Address, data []byte, amount *big.Int) error {
+	to := vm.WanCscPrecompileAddr
+	msg := types.NewMessage(from, &to, 0, amount, big.NewInt(defaultGas), defaultGasPrice, data, false)
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header, vm.Config{})
+	if err != nil {
+		return err
+	}
+	state.Prepare(common.Hash{}, header.Hash(), 0)
+	if _, _, err = evm.Call(vm.AccountRef(from), to, data, defaultGas, amount); err != nil {
+		return err
+	}
+	return vmError()
+}
+
+// buildStakingTx packs the staking call, runs it on a copy of the pending state
+// and returns the unsigned transaction.
+func buildStakingTx(ctx context.Context, b Backend, args *StakingTxArgs) (*types.Transaction, error) {
+	call, err := args.toStakingCall()
+	if err != nil {
+		return nil, err
+	}
+	input, err := vm.PackStakingCall(call)
+	if err != nil {
+		return nil, err
+	}
+
+	state, header, err := b.StateAndHeaderByNumber(ctx, rpc.PendingBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	amount := new(big.Int)
+	if args.Value != nil {
+		amount = args.Value.ToInt()
+	}
+	if err := runStakingCall(ctx, b, state.Copy(), header, args.From, input, amount); err != nil {
+		return nil, err
+	}
+
+	to := vm.WanCscPrecompileAddr
+	sendArgs := SendTxArgs{
+		From:     args.From,
+		To:       &to,
+		Gas:      args.Gas,
+		GasPrice: args.GasPrice,
+		Value:    args.Value,
+		Data:     input,
+		Nonce:    args.Nonce,
+	}
+	if err := sendArgs.setDefaults(ctx, b); err != nil {
+		return nil, err
+	}
+	return sendArgs.toTransaction(), nil
+}
+
+// PublicStakingAPI provides an API to build pos staking transactions.
+type PublicStakingAPI struct {
+	b Backend
+}
+
+// NewPublicStakingAPI creates a new staking transaction builder API.
+func NewPublicStakingAPI(b Backend) *PublicStakingAPI {
+	return &PublicStakingAPI{b}
+}
+
+// BuildStakeTx returns the unsigned staking transaction described by args. The
+// transaction is validated against the staking rules and the latest state first.
+func (s *PublicStakingAPI) BuildStakeTx(ctx context.Context, args StakingTxArgs) (*SignTransactionResult, error) {
+	tx, err := buildStakingTx(ctx, s.b, &args)
+	if err != nil {
+		return nil, err
+	}
+	data, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{data, tx}, nil
+}
+
+// fillStakingKeys derives secPk and bn256Pk of stakeIn and stakeRegister from the
+// keystore account of the validator, which defaults to args.From.
+func (s *PrivateAccountAPI) fillStakingKeys(args *StakingTxArgs, passwd string) error {
+	if args.Method != "stakeIn" && args.Method != "stakeRegister" {
+		return nil
+	}
+	if len(args.SecPk) != 0 && len(args.Bn256Pk) != 0 {
+		return nil
+	}
+
+	keyAddr := args.From
+	if args.Validator != nil {
+		keyAddr = *args.Validator
+	}
+	ks := fetchKeystore(s.am)
+	account, err := ks.Find(accounts.Account{Address: keyAddr})
+	if err != nil {
+		return ErrStakingNoAccount
+	}
+	key, err := ks.GetKey(account, passwd)
+	if err != nil {
+		return err
+	}
+	if key.PrivateKey == nil || key.PrivateKey2 == nil {
+		return ErrStakingNoAccount
+	}
+
+	args.SecPk = crypto.FromECDSAPub(&key.PrivateKey.PublicKey)
+	args.Bn256Pk = new(bn256.G1).ScalarBaseMult(posconfig.GenerateD3byKey2(key.PrivateKey2)).Marshal()
+	return nil
+}
+
+// signStakingTx builds the staking transaction described by args and signs it
+// with the key of args.From.
+func (s *PrivateAccountAPI) signStakingTx(ctx context.Context, args *StakingTxArgs, passwd string) (*types.Transaction, error) {
+	account := accounts.Account{Address: args.From}
+	wallet, err := s.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillStakingKeys(args, passwd); err != nil {
+		return nil, err
+	}
+	tx, err := buildStakingTx(ctx, s.b, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var chainID *big.Int
+	if config := s.b.ChainConfig(); config != nil {
+		chainID = config.ChainId
+	}
+	return wallet.SignTxWithPassphrase(account, passwd, tx, chainID)
+}
+
+// BuildStakeTx returns the signed staking transaction described by args without
+// sending it. The keys of stakeIn and stakeRegister are derived from the keystore.
+func (s *PrivateAccountAPI) BuildStakeTx(ctx context.Context, args StakingTxArgs, passwd string) (*SignTransactionResult, error) {
+	if args.Nonce == nil {
+		// Hold the addresse's mutex around signing to prevent concurrent assignment of
+		// the same nonce to multiple accounts.
+		s.nonceLock.LockAddr(args.From)
+		defer s.nonceLock.UnlockAddr(args.From)
+	}
+	tx, err := s.signStakingTx(ctx, &args, passwd)
+	if err != nil {
+		return nil, err
+	}
+	data, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &SignTransactionResult{data, tx}, nil
+}
+
+// SendStakeTx builds, signs and submits the staking transaction described by args.
+func (s *PrivateAccountAPI) SendStakeTx(ctx context.Context, args StakingTxArgs, passwd string) (common.Hash, error) {
+	if args.Nonce == nil {
+		// Hold the addresse's mutex around signing and sending to prevent
+		// concurrent assignment of the same nonce to multiple accounts.
+		s.nonceLock.LockAddr(args.From)
+		defer s.nonceLock.UnlockAddr(args.From)
+	}
+	tx, err := s.signStakingTx(ctx, &args, passwd)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, s.b, tx)
+}
+
+// StakeIn registers the keystore account args.Validator (default args.From) as a
+// validator with the value of args.
+func (s *PrivateAccountAPI) StakeIn(ctx context.Context, args StakingTxArgs, passwd string) (common.Hash, error) {
+	args.Method = "stakeIn"
+	return s.SendStakeTx(ctx, args, passwd)
+}
+
+const (
+	stakingStageInput     = "input"
+	stakingStageBalance   = "balance"
+	stakingStageExecution = "execution"
+)
+
+// StakerInfoChange is one field of the staker info changed by a staking call.
+type StakerInfoChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// StakingCallResult is the result of a staking contract call dry run. When the call
+// is invalid, Stage tells which check failed: input, balance or execution.
+type StakingCallResult struct {
+	Valid     bool               `json:"valid"`
+	Method    string             `json:"method"`
+	Validator common.Address     `json:"validator"`
+	Stage     string             `json:"stage,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	Before    *vm.StakerInfo     `json:"before"`
+	After     *vm.StakerInfo     `json:"after"`
+	Changes   []StakerInfoChange `json:"changes"`
+	Logs      []*types.Log       `json:"logs"`
+}
+
+func (r *StakingCallResult) fail(stage string, err error) *StakingCallResult {
+	r.Valid = false
+	r.Stage = stage
+	r.Error = err.Error()
+	return r
+}
+
+// ValidateStakingCall runs the staking contract call data sent from from with value
+// against a copy of the state of blockNr, without sending anything. The input is
+// checked first, then the call is executed by the staking contract, and the
+// resulting staker info changes and logs are returned.
+func (s *PublicStakingAPI) ValidateStakingCall(ctx context.Context, from common.Address, to common.Address, data hexutil.Bytes, value *hexutil.Big, blockNr rpc.BlockNumber) (*StakingCallResult, error) {
+	if to != vm.WanCscPrecompileAddr {
+		return nil, errors.New("to is not the staking contract address")
+	}
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	state = state.Copy()
+	amount := new(big.Int)
+	if value != nil {
+		amount = value.ToInt()
+	}
+
+	res := &StakingCallResult{Valid: true, Changes: make([]StakerInfoChange, 0), Logs: make([]*types.Log, 0)}
+	if res.Method, err = vm.StakingCallMethod(data); err != nil {
+		return res.fail(stakingStageInput, err), nil
+	}
+	if err = vm.ValidStakingInput(data); err != nil {
+		return res.fail(stakingStageInput, err), nil
+	}
+	res.Validator, _ = vm.StakingCallValidator(data)
+	res.Before, _ = vm.GetStakerInfo(state, res.Validator)
+
+	if state.GetBalance(from).Cmp(amount) < 0 {
+		return res.fail(stakingStageBalance, errors.New("insufficient balance for value")), nil
+	}
+	if err = runStakingCall(ctx, s.b, state, header, from, data, amount); err != nil {
+		return res.fail(stakingStageExecution, err), nil
+	}
+
+	res.After, _ = vm.GetStakerInfo(state, res.Validator)
+	res.Changes = stakerInfoChanges(res.Before, res.After)
+	if logs := state.GetLogs(common.Hash{}); logs != nil {
+		res.Logs = logs
+	}
+	return res, nil
+}
+
+// stakerInfoChanges lists the fields which differ between before and after.
+func stakerInfoChanges(before, after *vm.StakerInfo) []StakerInfoChange {
+	changes := make([]StakerInfoChange, 0)
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, StakerInfoChange{field, from, to})
+		}
+	}
+	if before == nil {
+		before = &vm.StakerInfo{}
+	}
+	if after == nil {
+		after = &vm.StakerInfo{}
+	}
+
+	add("amount", bigString(before.Amount), bigString(after.Amount))
+	add("stakeAmount", bigString(before.StakeAmount), bigString(after.StakeAmount))
+	add("lockEpochs", fmt.Sprint(before.LockEpochs), fmt.Sprint(after.LockEpochs))
+	add("nextLockEpochs", fmt.Sprint(before.NextLockEpochs), fmt.Sprint(after.NextLockEpochs))
+	add("stakingEpoch", fmt.Sprint(before.StakingEpoch), fmt.Sprint(after.StakingEpoch))
+	add("feeRate", fmt.Sprint(before.FeeRate), fmt.Sprint(after.FeeRate))
+
+	clients := make(map[common.Address]vm.ClientInfo)
+	for _, c := range before.Clients {
+		clients[c.Address] = c
+	}
+	for _, c := range after.Clients {
+		old := clients[c.Address]
+		prefix := "clients[" + c.Address.Hex() + "]."
+		add(prefix+"amount", bigString(old.Amount), bigString(c.Amount))
+		add(prefix+"quitEpoch", fmt.Sprint(old.QuitEpoch), fmt.Sprint(c.QuitEpoch))
+	}
+
+	partners := make(map[common.Address]vm.PartnerInfo)
+	for _, p := range before.Partners {
+		partners[p.Address] = p
+	}
+	for _, p := range after.Partners {
+		old := partners[p.Address]
+		prefix := "partners[" + p.Address.Hex() + "]."
+		add(prefix+"amount", bigString(old.Amount), bigString(p.Amount))
+		add(prefix+"renewal", fmt.Sprint(old.Renewal), fmt.Sprint(p.Renewal))
+	}
+	return changes
+}
+
+func bigString(v *big.Int) string {
+	if v == nil {
+		return "0"
+	}
+	return v.String()
+}
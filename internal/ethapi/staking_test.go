@@ -0,0 +1,138 @@
+// Copyright 2018 Wanchain Foundation Ltd
+
+package ethapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/common/math"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/params"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+func TestStakerInfoChanges(t *testing.T) {
+	client := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	before := &vm.StakerInfo{
+		Amount:      big.NewInt(100),
+		StakeAmount: big.NewInt(1000),
+		LockEpochs:  10,
+		FeeRate:     100,
+	}
+	after := &vm.StakerInfo{
+		Amount:      big.NewInt(100),
+		StakeAmount: big.NewInt(1000),
+		LockEpochs:  10,
+		FeeRate:     100,
+		Clients:     []vm.ClientInfo{{Address: client, Amount: big.NewInt(50), StakeAmount: big.NewInt(500)}},
+	}
+
+	changes := stakerInfoChanges(before, after)
+	if len(changes) != 1 {
+		t.Fatal("wrong changes count", len(changes))
+	}
+	if changes[0].Field != "clients["+client.Hex()+"].amount" || changes[0].From != "0" || changes[0].To != "50" {
+		t.Fatal("wrong change", changes[0])
+	}
+
+	changes = stakerInfoChanges(nil, before)
+	if len(changes) != 4 {
+		t.Fatal("wrong changes count of a new staker", len(changes))
+	}
+	if len(stakerInfoChanges(before, before)) != 0 {
+		t.Fatal("no change expected")
+	}
+}
+
+// stakingTestBackend serves a single state to the staking API.
+type stakingTestBackend struct {
+	Backend
+	state  *state.StateDB
+	header *types.Header
+}
+
+func (b *stakingTestBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	return b.state, b.header, nil
+}
+
+func (b *stakingTestBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+	state.SetBalance(msg.From(), math.MaxBig256)
+	context := core.NewEVMContext(msg, header, nil, &header.Coinbase)
+	return vm.NewEVM(context, state, params.TestChainConfig, vmCfg), func() error { return nil }, nil
+}
+
+func TestValidateStakingCall(t *testing.T) {
+	var (
+		db, _      = ethdb.NewMemDatabase()
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(db))
+		from       = common.HexToAddress("0x2d0e7c0813a51d3bd1d08246af2a8a7a57d8922e")
+		balance    = new(big.Int).Mul(big.NewInt(20000), big.NewInt(params.Wan))
+		secPk      = common.FromHex("0x04d7dffe5e06d2c7024d9bb93f675b8242e71901ee66a1bfe3fe5369324c0a75bf6f033dc4af65f5d0fe7072e98788fcfa670919b5bdc046f1ca91f28dff59db70")
+		bn256Pk    = common.FromHex("0x150b2b3230d6d6c8d1c133ec42d82f84add5e096c57665ff50ad071f6345cf45191fd8015cea72c4591ab3fd2ade12287c28a092ac0abf9ea19c13eb65fd4910")
+	)
+	statedb.SetBalance(from, balance)
+	api := NewPublicStakingAPI(&stakingTestBackend{
+		state:  statedb,
+		header: &types.Header{Number: big.NewInt(1), Time: big.NewInt(time.Now().Unix()), Difficulty: big.NewInt(1), GasLimit: big.NewInt(10000000)},
+	})
+	stakeIn := func(lockEpochs, feeRate uint64) hexutil.Bytes {
+		input, err := vm.PackStakingCall(&vm.StakingCall{Method: "stakeIn", SecPk: secPk, Bn256Pk: bn256Pk, LockEpochs: lockEpochs, FeeRate: feeRate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return input
+	}
+	wan := func(amount int64) *hexutil.Big {
+		return (*hexutil.Big)(new(big.Int).Mul(big.NewInt(amount), big.NewInt(params.Wan)))
+	}
+
+	res, err := api.ValidateStakingCall(context.Background(), from, vm.WanCscPrecompileAddr, stakeIn(10, 100), wan(10000), rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.Stage != "" || res.Method != "stakeIn" || res.Validator != from {
+		t.Fatalf("valid stakeIn rejected %+v", res)
+	}
+	if res.Before != nil || res.After == nil || res.After.Amount.Cmp(wan(10000).ToInt()) != 0 || res.After.FeeRate != 100 {
+		t.Fatalf("wrong staker info before %+v, after %+v", res.Before, res.After)
+	}
+	if len(res.Changes) == 0 || res.Changes[0].Field != "amount" || res.Changes[0].To != wan(10000).ToInt().String() {
+		t.Fatalf("wrong staker info changes %+v", res.Changes)
+	}
+	// The dry run must leave the state of the backend untouched
+	if info, _ := vm.GetStakerInfo(statedb, from); info != nil || statedb.GetBalance(from).Cmp(balance) != 0 {
+		t.Fatal("validation modified the state")
+	}
+
+	rejected := []struct {
+		data  hexutil.Bytes
+		value *hexutil.Big
+		stage string
+	}{
+		{stakeIn(10, 10001), wan(10000), stakingStageInput},        // fee rate above 100%
+		{stakeIn(10, 100), wan(30000), stakingStageBalance},        // value above the balance
+		{stakeIn(10, 100), wan(9999), stakingStageExecution},       // stake below the minimum
+		{hexutil.Bytes{0x01, 0x02}, wan(10000), stakingStageInput}, // no method id
+	}
+	for i, tt := range rejected {
+		res, err := api.ValidateStakingCall(context.Background(), from, vm.WanCscPrecompileAddr, tt.data, tt.value, rpc.LatestBlockNumber)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if res.Valid || res.Stage != tt.stage || res.Error == "" {
+			t.Errorf("test %d: result mismatch: have valid %v stage %q error %q, want stage %q", i, res.Valid, res.Stage, res.Error, tt.stage)
+		}
+	}
+	if _, err := api.ValidateStakingCall(context.Background(), from, from, stakeIn(10, 100), wan(10000), rpc.LatestBlockNumber); err == nil {
+		t.Error("call to another contract validated")
+	}
+}
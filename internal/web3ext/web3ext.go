@@ -386,6 +386,21 @@ web3._extend({
 			call: 'pos_getValidRBCnt',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getRBParticipation',
+			call: 'pos_getRBParticipation',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getSlsWorkStatus',
+			call: 'pos_getSlsWorkStatus',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getEquivocations',
+			call: 'pos_getEquivocations',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'getRbStage',
 			call: 'pos_getRbStage',
@@ -401,6 +416,32 @@ web3._extend({
 			call: 'pos_getTps',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'getStakerHistory',
+			call: 'pos_getStakerHistory',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'getDelegatorHistory',
+			call: 'pos_getDelegatorHistory',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'getDelegatorIncentives',
+			call: 'pos_getDelegatorIncentives',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'buildStakeTx',
+			call: 'pos_buildStakeTx',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'validateStakingCall',
+			call: 'pos_validateStakingCall',
+			params: 5,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, null, null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
 	]
 });
 `
@@ -504,12 +545,14 @@ web3._extend({
 		new web3._extend.Method({
 			name: 'traceBlockByNumber',
 			call: 'debug_traceBlockByNumber',
-			params: 1
+			params: 2,
+			inputFormatter: [null, null]
 		}),
 		new web3._extend.Method({
 			name: 'traceBlockByHash',
 			call: 'debug_traceBlockByHash',
-			params: 1
+			params: 2,
+			inputFormatter: [null, null]
 		}),
 		new web3._extend.Method({
 			name: 'seedHash',
@@ -682,6 +725,16 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'estimatePrivacyTxFee',
+			call: 'eth_estimatePrivacyTxFee',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getOTAImageSpend',
+			call: 'eth_getOTAImageSpend',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'submitTransaction',
 			call: 'eth_submitTransaction',
@@ -781,6 +834,37 @@ web3._extend({
 			call: 'personal_importRawKey',
 			params: 3
 		}),
+		new web3._extend.Method({
+			name: 'newMnemonic',
+			call: 'personal_newMnemonic',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'importMnemonic',
+			call: 'personal_importMnemonic',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'importWatchAccount',
+			call: 'personal_importWatchAccount',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'exportWatchAccount',
+			call: 'personal_exportWatchAccount',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'importWatchKey',
+			call: 'personal_importWatchKey',
+			params: 3
+		}),
+		new web3._extend.Method({
+			name: 'checkOTA',
+			call: 'personal_checkOTA',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'sign',
 			call: 'personal_sign',
@@ -802,6 +886,38 @@ web3._extend({
 			call: 'personal_deriveAccount',
 			params: 3
 		}),
+		new web3._extend.Method({
+			name: 'buildStakeTx',
+			call: 'personal_buildStakeTx',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'sendStakeTx',
+			call: 'personal_sendStakeTx',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'stakeIn',
+			call: 'personal_stakeIn',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'listOTAs',
+			call: 'personal_listOTAs',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'refundOTA',
+			call: 'personal_refundOTA',
+			params: 4,
+			inputFormatter: [null, web3._extend.formatters.inputAddressFormatter, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'buildPrivacyTx',
+			call: 'personal_buildPrivacyTx',
+			params: 2
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
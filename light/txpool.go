@@ -19,6 +19,7 @@ package light
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -67,6 +68,7 @@ type TxPool struct {
 	clearIdx     uint64                               // earliest block nr that can contain mined tx info
 
 	homestead bool
+	istanbul  bool // Whether the pending block is past the Istanbul fork
 }
 
 // TxRelayBackend provides an interface to the mechanism that forwards transacions
@@ -309,6 +311,7 @@ func (pool *TxPool) setNewHead(head *types.Header) {
 	pool.relay.NewHead(pool.head, m, r)
 
 	pool.homestead = true //pool.config.IsHomestead(head.Number)
+	pool.istanbul = pool.config.IsIstanbul(new(big.Int).Add(head.Number, big.NewInt(1)))
 
 	pool.signer = types.MakeSigner(pool.config, head.Number)
 }
@@ -378,7 +381,7 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 	}
 
 	// Should supply enough intrinsic gas
-	if types.IsNormalTransaction(tx.Txtype()) && tx.Gas().Cmp(core.IntrinsicGas(tx.Data(), tx.To(), pool.homestead)) < 0 {
+	if types.IsNormalTransaction(tx.Txtype()) && tx.Gas().Cmp(core.IntrinsicGas(tx.Data(), tx.To(), pool.homestead, pool.istanbul)) < 0 {
 		return core.ErrIntrinsicGas
 	}
 
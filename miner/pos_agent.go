@@ -2,12 +2,16 @@ package miner
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"sync"
 
-	"github.com/wanchain/go-wanchain/accounts"
 	"github.com/wanchain/go-wanchain/accounts/keystore"
 	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
 	"github.com/wanchain/go-wanchain/consensus/pluto"
+	"github.com/wanchain/go-wanchain/core/types"
 
 	//"github.com/wanchain/go-wanchain/common/hexutil"
 	"time"
@@ -18,6 +22,7 @@ import (
 	"github.com/wanchain/go-wanchain/pos/epochLeader"
 	"github.com/wanchain/go-wanchain/pos/incentive"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/possigner"
 	"github.com/wanchain/go-wanchain/pos/randombeacon"
 	"github.com/wanchain/go-wanchain/pos/slotleader"
 	"github.com/wanchain/go-wanchain/pos/util"
@@ -80,6 +85,56 @@ func posInitMiner(s Backend, key *keystore.Key) {
 	//}
 }
 
+// posTxArgs are the arguments of the pos transactions sent by the random beacon
+// and the slot leader selection.
+type posTxArgs struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Gas   *hexutil.Big   `json:"gas"`
+	Value *hexutil.Big   `json:"value"`
+	Data  hexutil.Bytes  `json:"data"`
+}
+
+// remotePosTxSender returns the sender of the pos transactions signed by the
+// remote signer, they are added to the local pool as eth_sendPosTransaction
+// only signs with the keystore.
+func remotePosTxSender(s Backend, signer possigner.Signer) func(map[string]interface{}) (common.Hash, error) {
+	var nonceLock sync.Mutex
+	return func(arg map[string]interface{}) (common.Hash, error) {
+		blob, err := json.Marshal(arg)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		var args posTxArgs
+		if err := json.Unmarshal(blob, &args); err != nil {
+			return common.Hash{}, err
+		}
+		if args.From != signer.Address() {
+			return common.Hash{}, fmt.Errorf("remote signer address %s isn't the sender %s", signer.Address().Hex(), args.From.Hex())
+		}
+		if args.Gas == nil || args.Value == nil {
+			return common.Hash{}, fmt.Errorf("pos tx without gas or value")
+		}
+
+		// Hold the lock from the nonce assignment to the pool insertion
+		nonceLock.Lock()
+		defer nonceLock.Unlock()
+
+		pool := s.TxPool()
+		nonce := pool.State().GetNonce(args.From)
+		tx := types.NewTransaction(nonce, args.To, (*big.Int)(args.Value), (*big.Int)(args.Gas), pool.GasPrice(), args.Data)
+		tx.SetTxtype(types.POS_TX)
+		signed, err := possigner.SignTx(signer, tx, s.BlockChain().Config().ChainId)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if err := pool.AddLocal(signed); err != nil {
+			return common.Hash{}, err
+		}
+		return signed.Hash(), nil
+	}
+}
+
 // backendTimerLoop is pos main time loop
 func (self *Miner) backendTimerLoop(s Backend) {
 	self.mu.Lock()
@@ -91,24 +146,29 @@ func (self *Miner) backendTimerLoop(s Backend) {
 	if errb != nil {
 		panic(errb)
 	}
-	wallet, errf := s.AccountManager().Find(accounts.Account{Address: eb})
-	if wallet == nil || errf != nil {
-		panic(errf)
-	}
-	type getKey interface {
-		GetUnlockedKey(address common.Address) (*keystore.Key, error)
+	// the key is address only if the validator keys are in a remote signer
+	key, signFn, err := possigner.ValidatorKey(s.AccountManager(), eb)
+	if err != nil {
+		panic(err)
 	}
-	key, err := wallet.(getKey).GetUnlockedKey(eb)
-	if key == nil || err != nil {
+	posSigner, err := possigner.ForKey(key)
+	if err != nil {
 		panic(err)
 	}
-	log.Debug("Get unlocked key success address:" + eb.Hex())
-	localPublicKey := hex.EncodeToString(crypto.FromECDSAPub(&key.PrivateKey.PublicKey))
+	log.Debug("Get validator key success address:" + eb.Hex())
+	localPublicKey := hex.EncodeToString(crypto.FromECDSAPub(posSigner.PublicKey()))
 
 	if pluto, ok := self.engine.(*pluto.Pluto); ok {
-		pluto.Authorize(eb, wallet.SignHash, key)
+		pluto.Authorize(eb, signFn, key)
 	}
 	posInitMiner(s, key)
+	// the validator transactions can't be signed by the keystore of the node
+	// with a remote signer
+	if remote, _ := possigner.Remote(); remote != nil {
+		util.SetPosTxSender(remotePosTxSender(s, remote))
+	} else {
+		util.SetPosTxSender(nil)
+	}
 	// get rpcClient
 	url := posconfig.Cfg().NodeCfg.IPCEndpoint()
 	rc, err := rpc.Dial(url)
@@ -34,6 +34,7 @@ var (
 
 const MainnetPow2PosUpgradeBlockNumber = 4046000
 const TestnetPow2PosUpgradeBlockNumber = 3560000
+const TestnetPetersburgBlockNumber = 18000000
 const InternalPow2PosUpgradeBlockNumber = 200
 
 var (
@@ -89,7 +90,8 @@ var (
 		//EIP150Hash:     common.HexToHash("0x41941023680923e0fe4d74a34bdac8141f2540e3ae90623718e47d66d1ca4a2d"),
 		//EIP155Block:    big.NewInt(10),
 		//EIP158Block:    big.NewInt(10),
-		ByzantiumBlock: big.NewInt(0),
+		ByzantiumBlock:  big.NewInt(0),
+		PetersburgBlock: big.NewInt(TestnetPetersburgBlockNumber),
 
 		Ethash:        new(EthashConfig),
 		PosFirstBlock: big.NewInt(TestnetPow2PosUpgradeBlockNumber), // set as n * epoch_length
@@ -130,9 +132,11 @@ var (
 		//EIP150Hash:     common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000000"),
 		//EIP155Block:    big.NewInt(3),
 		//EIP158Block:    big.NewInt(3),
-		ByzantiumBlock: big.NewInt(0),
-		PosFirstBlock:  big.NewInt(1),
-		IsPosActive:    true,
+		ByzantiumBlock:  big.NewInt(0),
+		PosFirstBlock:   big.NewInt(1),
+		IsPosActive:     true,
+		PetersburgBlock: big.NewInt(0),
+		IstanbulBlock:   big.NewInt(0),
 
 		Pluto: &PlutoConfig{
 			Period: 10,
@@ -148,14 +152,18 @@ var (
 	// means that all fields must be set at all times. This forces
 	// anyone adding flags to the config to also have to set these
 	// fields.
-	AllProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(100), false, new(EthashConfig), nil, nil}
+	AllProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), big.NewInt(100), false, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), new(EthashConfig), nil, nil}
 
 	TestChainConfig = &ChainConfig{
-		ChainId:        big.NewInt(1),
-		ByzantiumBlock: big.NewInt(0),
-		Ethash:         new(EthashConfig),
-		PosFirstBlock:  big.NewInt(TestnetPow2PosUpgradeBlockNumber), // set as n * epoch_length
-		IsPosActive:    false,
+		ChainId:            big.NewInt(1),
+		ByzantiumBlock:     big.NewInt(0),
+		Ethash:             new(EthashConfig),
+		PosFirstBlock:      big.NewInt(TestnetPow2PosUpgradeBlockNumber), // set as n * epoch_length
+		IsPosActive:        false,
+		RingSigVerifyBlock: big.NewInt(0),
+		BlsVerifyBlock:     big.NewInt(0),
+		PetersburgBlock:    big.NewInt(0),
+		IstanbulBlock:      big.NewInt(0),
 	}
 
 	TestRules = TestChainConfig.Rules(new(big.Int))
@@ -187,6 +195,11 @@ type ChainConfig struct {
 	PosFirstBlock  *big.Int `json:"posFirstBlock,omitempty"`
 	IsPosActive    bool     `json:"isPosActive,omitempty"`
 
+	RingSigVerifyBlock *big.Int `json:"ringSigVerifyBlock,omitempty"` // Ring signature verify precompile switch block (nil = no fork)
+	BlsVerifyBlock     *big.Int `json:"blsVerifyBlock,omitempty"`     // BLS aggregate verify precompile switch block (nil = no fork)
+	PetersburgBlock    *big.Int `json:"petersburgBlock,omitempty"`    // Petersburg switch block (nil = no fork)
+	IstanbulBlock      *big.Int `json:"istanbulBlock,omitempty"`      // Istanbul switch block (nil = no fork)
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
@@ -237,7 +250,7 @@ func (c *ChainConfig) String() string {
 		engine = "unknown"
 	}
 	//return fmt.Sprintf("{ChainID: %v Homestead: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Engine: %v}",
-	return fmt.Sprintf("{ChainID: %v Byzantium: %v Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Byzantium: %v RingSigVerify: %v BlsVerify: %v Petersburg: %v Istanbul: %v Engine: %v}",
 		c.ChainId,
 		//c.HomesteadBlock,
 		//c.DAOForkBlock,
@@ -247,6 +260,10 @@ func (c *ChainConfig) String() string {
 		//c.EIP158Block,
 
 		c.ByzantiumBlock,
+		c.RingSigVerifyBlock,
+		c.BlsVerifyBlock,
+		c.PetersburgBlock,
+		c.IstanbulBlock,
 		engine,
 	)
 }
@@ -277,6 +294,33 @@ func (c *ChainConfig) String() string {
 //	return isForked(c.ByzantiumBlock, num)
 //}
 
+// IsRingSigVerify returns whether num is either equal to the ring signature verify
+// fork block or greater.
+func (c *ChainConfig) IsRingSigVerify(num *big.Int) bool {
+	return isForked(c.RingSigVerifyBlock, num)
+}
+
+// IsBlsVerify returns whether num is either equal to the BLS aggregate verify fork
+// block or greater.
+func (c *ChainConfig) IsBlsVerify(num *big.Int) bool {
+	return isForked(c.BlsVerifyBlock, num)
+}
+
+// IsPetersburg returns whether num is either equal to the Petersburg fork block or
+// greater. Petersburg brings the Constantinople opcodes (EIP-145, EIP-1014 and
+// EIP-1052) without the SSTORE net gas metering of EIP-1283.
+func (c *ChainConfig) IsPetersburg(num *big.Int) bool {
+	return isForked(c.PetersburgBlock, num)
+}
+
+// IsIstanbul returns whether num is either equal to the Istanbul fork block or
+// greater. Istanbul includes the Petersburg changes and brings the CHAINID and
+// SELFBALANCE opcodes, the BLAKE2F pre-compiled contract and the gas repricing of
+// EIP-1108, EIP-1884 and EIP-2028, and the SSTORE net gas metering of EIP-2200.
+func (c *ChainConfig) IsIstanbul(num *big.Int) bool {
+	return isForked(c.IstanbulBlock, num)
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -296,9 +340,43 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 	//	return GasTableHomestead
 	//}
 
+	if c.IsIstanbul(num) {
+		return GasTableIstanbul
+	}
+	if c.IsPetersburg(num) {
+		return GasTablePetersburg
+	}
 	return GasTableEIP158
 }
 
+// CheckConfigForkOrder checks that the forks extending the instruction set of the
+// previous ones are enabled in order: a fork can be enabled neither without the
+// previous one nor before it.
+func (c *ChainConfig) CheckConfigForkOrder() error {
+	type fork struct {
+		name  string
+		block *big.Int
+	}
+	var lastFork fork
+	for _, cur := range []fork{
+		{"petersburgBlock", c.PetersburgBlock},
+		{"istanbulBlock", c.IstanbulBlock},
+	} {
+		if lastFork.name != "" {
+			switch {
+			case lastFork.block == nil && cur.block != nil:
+				return fmt.Errorf("unsupported fork ordering: %v not enabled, but %v enabled at %v",
+					lastFork.name, cur.name, cur.block)
+			case lastFork.block != nil && cur.block != nil && lastFork.block.Cmp(cur.block) > 0:
+				return fmt.Errorf("unsupported fork ordering: %v enabled at %v, but %v enabled at %v",
+					lastFork.name, lastFork.block, cur.name, cur.block)
+			}
+		}
+		lastFork = cur
+	}
+	return nil
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -350,6 +428,19 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	//	return newCompatError("Byzantium fork block", c.ByzantiumBlock, newcfg.ByzantiumBlock)
 	//}
 
+	if isForkIncompatible(c.RingSigVerifyBlock, newcfg.RingSigVerifyBlock, head) {
+		return newCompatError("Ring signature verify fork block", c.RingSigVerifyBlock, newcfg.RingSigVerifyBlock)
+	}
+	if isForkIncompatible(c.BlsVerifyBlock, newcfg.BlsVerifyBlock, head) {
+		return newCompatError("BLS verify fork block", c.BlsVerifyBlock, newcfg.BlsVerifyBlock)
+	}
+	if isForkIncompatible(c.PetersburgBlock, newcfg.PetersburgBlock, head) {
+		return newCompatError("Petersburg fork block", c.PetersburgBlock, newcfg.PetersburgBlock)
+	}
+	if isForkIncompatible(c.IstanbulBlock, newcfg.IstanbulBlock, head) {
+		return newCompatError("Istanbul fork block", c.IstanbulBlock, newcfg.IstanbulBlock)
+	}
+
 	return nil
 }
 
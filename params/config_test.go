@@ -37,6 +37,56 @@ func TestCheckCompatible(t *testing.T) {
 			head:    9,
 			wantErr: nil,
 		},
+		{
+			stored: AllProtocolChanges,
+			new:    &ChainConfig{RingSigVerifyBlock: big.NewInt(5)},
+			head:   3,
+			wantErr: &ConfigCompatError{
+				What:         "Ring signature verify fork block",
+				StoredConfig: big.NewInt(0),
+				NewConfig:    big.NewInt(5),
+				RewindTo:     0,
+			},
+		},
+		{
+			stored: AllProtocolChanges,
+			new:    &ChainConfig{RingSigVerifyBlock: big.NewInt(0), BlsVerifyBlock: big.NewInt(5)},
+			head:   3,
+			wantErr: &ConfigCompatError{
+				What:         "BLS verify fork block",
+				StoredConfig: big.NewInt(0),
+				NewConfig:    big.NewInt(5),
+				RewindTo:     0,
+			},
+		},
+		{
+			stored:  &ChainConfig{PetersburgBlock: big.NewInt(10)},
+			new:     &ChainConfig{PetersburgBlock: big.NewInt(20)},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{PetersburgBlock: big.NewInt(10)},
+			new:    &ChainConfig{PetersburgBlock: big.NewInt(20)},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Petersburg fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{IstanbulBlock: big.NewInt(10)},
+			new:    &ChainConfig{IstanbulBlock: nil},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Istanbul fork block",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    nil,
+				RewindTo:     9,
+			},
+		},
 		//{
 		//	stored: AllProtocolChanges,
 		//	new:    &ChainConfig{ByzantiumBlock: nil},
@@ -79,3 +129,30 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckConfigForkOrder(t *testing.T) {
+	for _, config := range []*ChainConfig{
+		MainnetChainConfig,
+		TestnetChainConfig,
+		InternalChainConfig,
+		PlutoChainConfig,
+		TestChainConfig,
+		AllProtocolChanges,
+		{BlsVerifyBlock: big.NewInt(0)},
+		{RingSigVerifyBlock: big.NewInt(10), BlsVerifyBlock: big.NewInt(9)},
+		{PetersburgBlock: big.NewInt(0), IstanbulBlock: big.NewInt(0)},
+	} {
+		if err := config.CheckConfigForkOrder(); err != nil {
+			t.Errorf("config %v rejected: %v", config, err)
+		}
+	}
+
+	for _, config := range []*ChainConfig{
+		{IstanbulBlock: big.NewInt(0)},
+		{PetersburgBlock: big.NewInt(10), IstanbulBlock: big.NewInt(5)},
+	} {
+		if err := config.CheckConfigForkOrder(); err == nil {
+			t.Errorf("config %v accepted", config)
+		}
+	}
+}
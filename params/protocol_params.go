@@ -49,10 +49,22 @@ const (
 	TierStepGas      uint64 = 0     // Once per operation, for a selection of them.
 	LogTopicGas      uint64 = 375   // Multiplied by the * of the LOG*, per LOG transaction. e.g. LOG0 incurs 0 * c_txLogTopicGas, LOG4 incurs 4 * c_txLogTopicGas.
 	CreateGas        uint64 = 32000 // Once per CREATE operation & contract-creation transaction.
+	Create2Gas       uint64 = 32000 // Once per CREATE2 operation
 	SuicideRefundGas uint64 = 24000 // Refunded following a suicide operation.
 	MemoryGas        uint64 = 3     // Times the address of the (highest referenced byte in memory + 1). NOTE: referencing happens on read, write and in instructions such as RETURN and CALL.
 	TxDataNonZeroGas uint64 = 68    // Per byte of data attached to a transaction that is not equal to zero. NOTE: Not payable on data of calls between transactions.
 
+	TxDataNonZeroGasEIP2028 uint64 = 16 // Per byte of non zero data attached to a transaction after the Istanbul fork (EIP-2028)
+
+	SstoreSentryGasEIP2200   uint64 = 2300  // Minimum gas left for an SSTORE operation after the Istanbul fork, not consumed (EIP-2200)
+	SstoreNoopGasEIP2200     uint64 = 800   // Once per SSTORE operation if the value doesn't change.
+	SstoreDirtyGasEIP2200    uint64 = 800   // Once per SSTORE operation if a dirty value is changed.
+	SstoreInitGasEIP2200     uint64 = 20000 // Once per SSTORE operation from clean zero to non-zero.
+	SstoreInitRefundEIP2200  uint64 = 19200 // Once per SSTORE operation for resetting to the original zero value.
+	SstoreCleanGasEIP2200    uint64 = 5000  // Once per SSTORE operation from clean non-zero to something else.
+	SstoreCleanRefundEIP2200 uint64 = 4200  // Once per SSTORE operation for resetting to the original non-zero value.
+	SstoreClearRefundEIP2200 uint64 = 15000 // Once per SSTORE operation for clearing an originally existing storage slot.
+
 	MaxCodeSize = 24576 // Maximum bytecode to permit for a contract
 
 	// Precompiled contract gas prices
@@ -70,9 +82,20 @@ const (
 	Bn256PairingBaseGas     uint64 = 100000 // Base price for an elliptic curve pairing check
 	Bn256PairingPerPointGas uint64 = 80000  // Per-point price for an elliptic curve pairing check
 
+	Bn256AddGasIstanbul             uint64 = 150   // Gas needed for an elliptic curve addition after the Istanbul fork (EIP-1108)
+	Bn256ScalarMulGasIstanbul       uint64 = 6000  // Gas needed for an elliptic curve scalar multiplication after the Istanbul fork (EIP-1108)
+	Bn256PairingBaseGasIstanbul     uint64 = 45000 // Base price for an elliptic curve pairing check after the Istanbul fork (EIP-1108)
+	Bn256PairingPerPointGasIstanbul uint64 = 34000 // Per-point price for an elliptic curve pairing check after the Istanbul fork (EIP-1108)
+	Blake2FRoundGas                 uint64 = 1     // Per round price for a BLAKE2 compression (EIP-152)
+
 	RequiredGasPerMixPub uint64 = 4000 // ring signature mix difficulty gas
 	GetOTAMixSetMaxSize  uint64 = 20   // Max number of mix ota set size from once getting
 
+	RingSigVerifyBaseGas   uint64 = 3000   // Base price for a ring signature verification
+	RingSigVerifyPerPubGas uint64 = 6000   // Per public key price for a ring signature verification
+	BlsVerifyBaseGas       uint64 = 100000 // Base price for a BLS aggregate signature verification
+	BlsVerifyPerKeyGas     uint64 = 80000  // Per public key price for a BLS aggregate signature verification
+
 	//SlsStgOnePerByteGas		uint64 = 20      // per byte gas for SlsStgOnePerByteGas
 	SlsStgTwoPerByteGas uint64 = 20 // per byte gas for SlsStgOnePerByteGas
 )
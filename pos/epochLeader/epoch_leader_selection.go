@@ -274,30 +274,30 @@ func (e *Epocher) createStakerProbabilityArray(statedb *state.StateDB, epochID u
 		return nil, vm.ErrUnknown
 	}
 
-	listAddr := vm.StakersInfoAddr
-	ps := newProposerSorter()
+	ps := BuildProbabilityArray(vm.GetStakersSnap(statedb), epochID)
+	log.Debug("get createStakerProbabilityArray", "len", len(ps))
 
-	statedb.ForEachStorageByteArray(listAddr, func(key common.Hash, value []byte) bool {
-		staker := vm.StakerInfo{}
-		err := rlp.DecodeBytes(value, &staker)
-		if err != nil {
-			log.Error(err.Error())
-			return true
-		}
-		_, p, err := CalEpochProbabilityStaker(&staker, epochID)
+	return ps, nil
+}
+
+// BuildProbabilityArray returns the stakers which can be selected in epochID, sorted
+// by probability, with the probabilities accumulated so that the last one is the total.
+func BuildProbabilityArray(stakers []vm.StakerInfo, epochID uint64) ProposerSorter {
+	ps := newProposerSorter()
+	for i := range stakers {
+		_, p, err := CalEpochProbabilityStaker(&stakers[i], epochID)
 		if err != nil || p == nil {
 			// this validator has no enough
-			return true
+			continue
 		}
 		item := Proposer{
-			PubSec256:     staker.PubSec256,
-			PubBn256:      staker.PubBn256,
+			PubSec256:     stakers[i].PubSec256,
+			PubBn256:      stakers[i].PubBn256,
 			Probabilities: p,
 		}
 		ps = append(ps, item)
 		log.Debug(common.ToHex(item.Probabilities.Bytes()))
-		return true
-	})
+	}
 
 	sort.Stable(ProposerSorter(ps))
 
@@ -308,10 +308,33 @@ func (e *Epocher) createStakerProbabilityArray(statedb *state.StateDB, epochID u
 
 		ps[idx].Probabilities = big.NewInt(0).Add(ps[idx].Probabilities, ps[idx-1].Probabilities)
 	}
+	return ps
+}
 
-	log.Debug("get createStakerProbabilityArray", "len", len(ps))
+// SelectLeaders samples count proposers from ps by the random number r, based on
+// proportion of Probabilities. prefix is 0 for epoch leaders and 1 for random proposers.
+// ps must be built by BuildProbabilityArray.
+func SelectLeaders(r []byte, prefix byte, ps ProposerSorter, count int) []Proposer {
+	//the last one is total properties
+	tp := ps[len(ps)-1].Probabilities
 
-	return ps, nil
+	var buffer bytes.Buffer
+	buffer.Write([]byte{prefix})
+	buffer.Write(r)
+	cr := crypto.Keccak256(buffer.Bytes()) //cr = hash(prefix||r)
+
+	leaders := make([]Proposer, 0, count)
+	for i := 0; i < count; i++ {
+		crBig := new(big.Int).SetBytes(cr)
+		crBig = crBig.Mod(crBig, tp) //cr_big = cr mod tp
+
+		//select pki whose probability bigger than cr_big left
+		idx := sort.Search(len(ps), func(i int) bool { return ps[i].Probabilities.Cmp(crBig) > 0 })
+		leaders = append(leaders, ps[idx])
+
+		cr = crypto.Keccak256(cr)
+	}
+	return leaders
 }
 
 //select epoch leader from PublicKeys based on proportion of Probabilities
@@ -320,41 +343,20 @@ func (e *Epocher) epochLeaderSelection(r []byte, ps ProposerSorter, epochId uint
 		return ErrInvalidRandomProposerSelection
 	}
 
-	//the last one is total properties
-	tp := ps[len(ps)-1].Probabilities
-
-	var Byte0 = []byte{byte(0)}
-	var buffer bytes.Buffer
-	buffer.Write(Byte0)
-	buffer.Write(r)
-	r0 := buffer.Bytes()       //r0 = 0||r
-	cr := crypto.Keccak256(r0) //cr = hash(r0)
-
-	//randomProposerPublicKeys := make([]*ecdsa.PublicKey, 0)  //store the selected publickeys
 	log.Debug("epochLeaderSelection selecting")
 	selectionCount := posconfig.EpochLeaderCount
 	info, err := e.GetWhiteInfo(epochId)
 	if err == nil {
 		selectionCount = posconfig.EpochLeaderCount - int(info.WlCount.Uint64())
 	}
-	for i := 0; i < selectionCount; i++ {
-
-		crBig := new(big.Int).SetBytes(cr)
-		crBig = crBig.Mod(crBig, tp) //cr_big = cr mod tp
-
-		//fmt.Println("epoch leader mod tp=" + common.ToHex(crBig.Bytes()))
-		//select pki whose probability bigger than cr_big left
-		idx := sort.Search(len(ps), func(i int) bool { return ps[i].Probabilities.Cmp(crBig) > 0 })
-
-		log.Debug("select epoch leader", "epochid=", epochId, "idx=", i, "pub=", ps[idx].PubSec256)
-		//randomProposerPublicKeys = append(randomProposerPublicKeys, ps[idx].PubSec256)
-		val, err := rlp.EncodeToBytes(&ps[idx])
+	leaders := SelectLeaders(r, 0, ps, selectionCount)
+	for i := range leaders {
+		log.Debug("select epoch leader", "epochid=", epochId, "idx=", i, "pub=", leaders[i].PubSec256)
+		val, err := rlp.EncodeToBytes(&leaders[i])
 		if err != nil {
 			continue
 		}
 		e.epochLeadersDb.PutWithIndex(epochId, uint64(i), "", val)
-
-		cr = crypto.Keccak256(cr)
 	}
 
 	return nil
@@ -401,34 +403,16 @@ func (e *Epocher) randomProposerSelection(r []byte, ps ProposerSorter, epochId u
 		return ErrInvalidEpochProposerSelection
 	}
 
-	//the last one is total properties
-	tp := ps[len(ps)-1].Probabilities
-
-	var Byte1 = []byte{byte(1)}
-	var buffer bytes.Buffer
-	buffer.Write(Byte1)
-	buffer.Write(r)
-	r1 := buffer.Bytes()       //r1 = 1||r
-	cr := crypto.Keccak256(r1) //cr = hash(r1)
-
 	log.Info("random proposer selecting...\n")
-	for i := 0; i < posconfig.RandomProperCount; i++ {
-
-		crBig := new(big.Int).SetBytes(cr)
-		crBig = crBig.Mod(crBig, tp) //cr_big = cr mod tp
-
-		//select pki whose probability bigger than cr_big left
-		idx := sort.Search(len(ps), func(i int) bool { return ps[i].Probabilities.Cmp(crBig) > 0 })
-
-		val, err := rlp.EncodeToBytes(ps[idx])
+	leaders := SelectLeaders(r, 1, ps, posconfig.RandomProperCount)
+	for i := range leaders {
+		val, err := rlp.EncodeToBytes(leaders[i])
 
 		if err != nil {
 			continue
 		}
 
 		e.rbLeadersDb.PutWithIndex(epochId, uint64(i), "", val)
-
-		cr = crypto.Keccak256(cr)
 	}
 
 	return nil
@@ -0,0 +1,183 @@
+// Package equivocation detects slot leaders signing two different blocks for the
+// same pos slot, and keeps the evidence for governance and monitoring.
+package equivocation
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/event"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+const (
+	evidenceKey      = "equivocation"
+	evidenceCountKey = "equivocationCount"
+
+	// seenHeaders is the number of recent slots whose headers are remembered,
+	// two epochs covers the side chains the node may still import.
+	seenHeaders = 2 * posconfig.SlotCount
+
+	// MaxQueryEpochs is the widest epoch range of a query.
+	MaxQueryEpochs = 1000
+)
+
+var (
+	ErrInvalidRange = errors.New("invalid epoch range")
+
+	mu   sync.Mutex
+	seen *lru.Cache // slotKey -> *types.Header
+	db   *posdb.Db
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+)
+
+func init() {
+	seen, _ = lru.New(seenHeaders)
+}
+
+// Evidence is a pair of headers of the same epoch and slot, both sealed by signer.
+type Evidence struct {
+	EpochID uint64         `json:"epochId"`
+	SlotID  uint64         `json:"slotId"`
+	Signer  common.Address `json:"signer"`
+	Header1 *types.Header  `json:"header1"`
+	Header2 *types.Header  `json:"header2"`
+	Time    uint64         `json:"time"`
+}
+
+// EvidenceEvent is posted when a new equivocation is detected.
+type EvidenceEvent struct {
+	Evidence *Evidence
+}
+
+type slotKey struct {
+	epochID uint64
+	slotID  uint64
+	signer  common.Address
+}
+
+func getDb() *posdb.Db {
+	if db == nil {
+		db = posdb.GetDbByName(posconfig.EquivocationLocalDB)
+		if db == nil {
+			db = posdb.NewDb(posconfig.EquivocationLocalDB)
+		}
+	}
+	return db
+}
+
+// Check records the sealed header of the slot. If signer already sealed another
+// header for the same slot, the evidence is stored, posted and returned.
+func Check(header *types.Header, epochID uint64, slotID uint64, signer common.Address) *Evidence {
+	key := slotKey{epochID, slotID, signer}
+	hash := header.Hash()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	v, ok := seen.Get(key)
+	if !ok {
+		seen.Add(key, types.CopyHeader(header))
+		return nil
+	}
+	first := v.(*types.Header)
+	if first.Hash() == hash {
+		return nil
+	}
+
+	evidences := readEvidences(epochID)
+	for _, e := range evidences {
+		if e.SlotID == slotID && e.Signer == signer && e.Header1.Hash() == first.Hash() && e.Header2.Hash() == hash {
+			return nil
+		}
+	}
+
+	evidence := &Evidence{
+		EpochID: epochID,
+		SlotID:  slotID,
+		Signer:  signer,
+		Header1: first,
+		Header2: types.CopyHeader(header),
+		Time:    uint64(time.Now().Unix()),
+	}
+	if err := storeEvidence(evidence, uint64(len(evidences))); err != nil {
+		log.SyslogErr("store equivocation evidence fail", "epochID", epochID, "slotID", slotID, "err", err.Error())
+	}
+
+	log.SyslogWarning("Slot leader equivocation detected", "epochID", epochID, "slotID", slotID,
+		"signer", signer.Hex(), "hash1", first.Hash().Hex(), "hash2", hash.Hex())
+	go feed.Send(EvidenceEvent{Evidence: evidence})
+	return evidence
+}
+
+func storeEvidence(e *Evidence, index uint64) error {
+	buf, err := rlp.EncodeToBytes(e)
+	if err != nil {
+		return err
+	}
+	if _, err := getDb().PutWithIndex(e.EpochID, index, evidenceKey, buf); err != nil {
+		return err
+	}
+
+	count := make([]byte, 8)
+	binary.BigEndian.PutUint64(count, index+1)
+	_, err = getDb().Put(e.EpochID, evidenceCountKey, count)
+	return err
+}
+
+func readEvidences(epochID uint64) []*Evidence {
+	buf, err := getDb().Get(epochID, evidenceCountKey)
+	if err != nil || len(buf) != 8 {
+		return nil
+	}
+
+	count := binary.BigEndian.Uint64(buf)
+	ret := make([]*Evidence, 0, count)
+	for i := uint64(0); i < count; i++ {
+		buf, err := getDb().GetWithIndex(epochID, i, evidenceKey)
+		if err != nil {
+			continue
+		}
+		var e Evidence
+		if err := rlp.DecodeBytes(buf, &e); err != nil {
+			log.SyslogErr("decode equivocation evidence fail", "epochID", epochID, "index", i, "err", err.Error())
+			continue
+		}
+		ret = append(ret, &e)
+	}
+	return ret
+}
+
+// GetEvidences returns the evidences stored from fromEpoch to toEpoch (both inclusive).
+func GetEvidences(fromEpoch uint64, toEpoch uint64) ([]*Evidence, error) {
+	if fromEpoch > toEpoch || toEpoch-fromEpoch >= MaxQueryEpochs {
+		return nil, ErrInvalidRange
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	ret := make([]*Evidence, 0)
+	for epochID := fromEpoch; ; epochID++ {
+		ret = append(ret, readEvidences(epochID)...)
+		if epochID == toEpoch {
+			break
+		}
+	}
+	return ret, nil
+}
+
+// SubscribeEvidenceEvent registers a subscription of EvidenceEvent.
+func SubscribeEvidenceEvent(ch chan<- EvidenceEvent) event.Subscription {
+	return scope.Track(feed.Subscribe(ch))
+}
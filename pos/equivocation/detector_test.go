@@ -0,0 +1,66 @@
+package equivocation
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+)
+
+func TestCheck(t *testing.T) {
+	db = posdb.GetDb()
+	signer := common.HexToAddress("0x2d0e7c0813a51d3bd1d08246af2a8a7a57d8922e")
+	epochID, slotID := uint64(30000), uint64(17)
+
+	ch := make(chan EvidenceEvent, 1)
+	sub := SubscribeEvidenceEvent(ch)
+	defer sub.Unsubscribe()
+
+	h1 := &types.Header{Number: big.NewInt(100), Difficulty: big.NewInt(1), Extra: []byte{1}}
+	h2 := &types.Header{Number: big.NewInt(100), Difficulty: big.NewInt(1), Extra: []byte{2}}
+
+	if Check(h1, epochID, slotID, signer) != nil {
+		t.Fatal("first header isn't an equivocation")
+	}
+	if Check(h1, epochID, slotID, signer) != nil {
+		t.Fatal("the same header isn't an equivocation")
+	}
+	if Check(h2, epochID, slotID+1, signer) != nil {
+		t.Fatal("another slot isn't an equivocation")
+	}
+
+	e := Check(h2, epochID, slotID, signer)
+	if e == nil || e.Header1.Hash() != h1.Hash() || e.Header2.Hash() != h2.Hash() || e.Signer != signer {
+		t.Fatal("equivocation not detected", e)
+	}
+	if Check(h2, epochID, slotID, signer) != nil {
+		t.Fatal("evidence should be stored once")
+	}
+
+	select {
+	case ev := <-ch:
+		if ev.Evidence.SlotID != slotID {
+			t.Fatal("wrong event", ev.Evidence)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no evidence event")
+	}
+
+	evidences, err := GetEvidences(epochID-1, epochID+1)
+	if err != nil || len(evidences) != 1 {
+		t.Fatal("wrong evidences", evidences, err)
+	}
+	if evidences[0].Header2.Hash() != h2.Hash() || evidences[0].EpochID != epochID {
+		t.Fatal("wrong stored evidence", evidences[0])
+	}
+
+	if _, err := GetEvidences(epochID, epochID-1); err != ErrInvalidRange {
+		t.Fatal("invalid range should fail", err)
+	}
+	if _, err := GetEvidences(0, MaxQueryEpochs); err != ErrInvalidRange {
+		t.Fatal("too wide range should fail", err)
+	}
+}
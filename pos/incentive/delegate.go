@@ -9,9 +9,10 @@ import (
 	"github.com/wanchain/go-wanchain/log"
 )
 
-// delegate can calc the delegate division
-func delegate(addrs []common.Address, values []*big.Int, epochID uint64) ([][]vm.ClientIncentive, *big.Int, error) {
+// delegate can calc the delegate division, the fees deducted from each client are returned in the same layout
+func delegate(addrs []common.Address, values []*big.Int, epochID uint64) ([][]vm.ClientIncentive, [][]*big.Int, *big.Int, error) {
 	finalIncentive := make([][]vm.ClientIncentive, 0)
+	finalFees := make([][]*big.Int, 0)
 	remain := big.NewInt(0)
 	for i := 0; i < len(addrs); i++ {
 		stakers, division, totalProbility, err := getStakerInfoAndCheck(epochID, addrs[i])
@@ -20,11 +21,12 @@ func delegate(addrs []common.Address, values []*big.Int, epochID uint64) ([][]vm
 			continue
 		}
 
-		incentive, subRemain := delegateDivision(addrs[i], values[i], stakers, division, totalProbility)
+		incentive, fees, subRemain := delegateDivision(addrs[i], values[i], stakers, division, totalProbility)
 		finalIncentive = append(finalIncentive, incentive)
+		finalFees = append(finalFees, fees)
 		remain.Add(remain, subRemain)
 	}
-	return finalIncentive, remain, nil
+	return finalIncentive, finalFees, remain, nil
 }
 
 func getStakerInfoAndCheck(epochID uint64, addr common.Address) ([]vm.ClientProbability, uint64, *big.Int, error) {
@@ -77,7 +79,7 @@ func sumStakerProbility(inputs []vm.ClientProbability) *big.Int {
 }
 
 func delegateDivision(addr common.Address, value *big.Int, stakers []vm.ClientProbability,
-	divisionPercent uint64, totalProbility *big.Int) ([]vm.ClientIncentive, *big.Int) {
+	divisionPercent uint64, totalProbility *big.Int) ([]vm.ClientIncentive, []*big.Int, *big.Int) {
 	valueCeiling := value
 
 	remain := big.NewInt(0).Sub(value, valueCeiling)
@@ -87,6 +89,7 @@ func delegateDivision(addr common.Address, value *big.Int, stakers []vm.ClientPr
 	lastValue := big.NewInt(0).Sub(valueCeiling, commission)
 	tp := sumStakerProbility(stakers)
 	result := make([]vm.ClientIncentive, len(stakers))
+	fees := make([]*big.Int, len(stakers))
 
 	for i := 0; i < len(stakers); i++ {
 		result[i].ValidatorAddr = stakers[i].ValidatorAddr
@@ -98,6 +101,14 @@ func delegateDivision(addr common.Address, value *big.Int, stakers []vm.ClientPr
 			result[i].Incentive.Div(result[i].Incentive, tp)
 		}
 
+		// fee is the part of the client's share kept by the validator as commission
+		fees[i] = big.NewInt(0)
+		if i != 0 && tp.Sign() != 0 {
+			gross := big.NewInt(0).Mul(valueCeiling, stakers[i].Probability)
+			gross.Div(gross, tp)
+			fees[i].Sub(gross, result[i].Incentive)
+		}
+
 		// Position O is validator
 		if i == 0 {
 			result[0].Incentive.Add(result[0].Incentive, commission)
@@ -108,5 +119,5 @@ func delegateDivision(addr common.Address, value *big.Int, stakers []vm.ClientPr
 			result[i].Incentive.SetUint64(0)
 		}
 	}
-	return result, remain
+	return result, fees, remain
 }
@@ -18,9 +18,9 @@ func TestDelegate(t *testing.T) {
 		values[i] = big.NewInt(1e18)
 	}
 
-	finalIncentive, remain, err := delegate(epAddrs, values, 0)
+	finalIncentive, fees, remain, err := delegate(epAddrs, values, 0)
 
-	if err != nil {
+	if err != nil || len(fees) != len(finalIncentive) {
 		t.FailNow()
 	}
 
@@ -40,6 +40,9 @@ func TestDelegate(t *testing.T) {
 				if finalIncentive[i][m].Incentive.String() != "22500000000000000" {
 					t.FailNow()
 				}
+				if fees[i][m].String() != "2500000000000000" {
+					t.FailNow()
+				}
 			}
 			fmt.Println("<--------")
 		}
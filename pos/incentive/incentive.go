@@ -62,6 +62,7 @@ func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bo
 		return true
 	}
 	finalIncentive := make([][]vm.ClientIncentive, 0)
+	ledger := make([]LedgerEntry, 0)
 	remainsAll := big.NewInt(0)
 
 	total, foundation, gasPool := calculateIncentivePool(stateDb, epochID)
@@ -90,7 +91,7 @@ func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bo
 	sumRemain := big.NewInt(0).Sub(total, sum)
 	remainsAll.Add(remainsAll, sumRemain)
 
-	incentives, remains, err := epochLeaderAllocate(epochLeaderSubsidy, epAddrs, epAct, epochID)
+	incentives, fees, remains, err := epochLeaderAllocate(epochLeaderSubsidy, epAddrs, epAct, epochID)
 	if err != nil {
 		log.SyslogErr("Incentive epochLeaderAllocate error", "error", err.Error(), "epochLeaderSubsidy", epochLeaderSubsidy.String(), "epAddrs", epAddrs)
 		return false
@@ -99,13 +100,14 @@ func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bo
 	if incentives != nil {
 		log.Info("epoch leader allocate", "total", sumToPay(incentives), "len", len(incentives))
 		finalIncentive = append(finalIncentive, incentives...)
+		ledger = appendLedger(ledger, epochID, RoleEpochLeader, incentives, fees)
 	} else {
 		log.Warn("Nothing epoch Leader to incentive.")
 	}
 
 	remainsAll.Add(remainsAll, remains)
 
-	incentives, remains, err = randomProposerAllocate(randomProposerSubsidy, rpAddrs, rpAct, epochID)
+	incentives, fees, remains, err = randomProposerAllocate(randomProposerSubsidy, rpAddrs, rpAct, epochID)
 	if err != nil {
 		log.SyslogErr("Incentive randomProposerAllocate error", "error", err.Error(), "randomProposerSubsidy", randomProposerSubsidy.String(), "rpAddrs", rpAddrs)
 		return false
@@ -114,13 +116,14 @@ func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bo
 	if incentives != nil {
 		log.Info("random proposer allocate", "total", sumToPay(incentives), "len", len(incentives))
 		finalIncentive = append(finalIncentive, incentives...)
+		ledger = appendLedger(ledger, epochID, RoleRandomProposer, incentives, fees)
 	} else {
 		log.Warn("Nothing random proposer to incentive.")
 	}
 
 	remainsAll.Add(remainsAll, remains)
 
-	incentives, remains, err = slotLeaderAllocate(slotLeaderSubsidy, slAddrs, slBlk, slAct, posconfig.SlotCount-ctrlCount, epochID)
+	incentives, fees, remains, err = slotLeaderAllocate(slotLeaderSubsidy, slAddrs, slBlk, slAct, posconfig.SlotCount-ctrlCount, epochID)
 	if err != nil {
 		log.SyslogErr("Incentive slotLeaderAllocate error", "slotLeaderSubsidy", slotLeaderSubsidy.String(), "slAddrs", slAddrs)
 		return false
@@ -129,6 +132,7 @@ func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bo
 	if incentives != nil {
 		log.Info("slot leader allocate", "total", sumToPay(incentives), "len", len(incentives))
 		finalIncentive = append(finalIncentive, incentives...)
+		ledger = appendLedger(ledger, epochID, RoleSlotLeader, incentives, fees)
 	} else {
 		log.Warn("Nothing slot leader to incentive.")
 	}
@@ -150,6 +154,7 @@ func Run(chain consensus.ChainReader, stateDb *state.StateDB, epochID uint64) bo
 
 	setStakerInfo(epochID, finalIncentive)
 	saveIncentiveHistory(epochID, finalIncentive)
+	saveIncentiveLedger(epochID, ledger)
 	localDbSetValue(epochID, dictEpochBlock, chain.CurrentHeader().Number)
 
 	finished(stateDb, epochID)
@@ -179,24 +184,24 @@ func finished(stateDb *state.StateDB, epochID uint64) {
 
 // protocalRunerAllocate use to calc the subsidy of protocal Participant (Epoch leader and Random proposer)
 func protocalRunerAllocate(funds *big.Int, addrs []common.Address, acts []int,
-	epochID uint64) ([][]vm.ClientIncentive, *big.Int, error) {
+	epochID uint64) ([][]vm.ClientIncentive, [][]*big.Int, *big.Int, error) {
 	remains := big.NewInt(0)
 
 	if addrs == nil || len(addrs) == 0 {
-		return nil, remains.Add(remains, funds), nil
+		return nil, nil, remains.Add(remains, funds), nil
 	}
 
 	count := len(addrs)
 	if count == 0 {
-		return nil, nil, errors.New("protocalRunerAllocate addrs length == 0")
+		return nil, nil, nil, errors.New("protocalRunerAllocate addrs length == 0")
 	}
 
 	if count != len(acts) {
-		return nil, nil, errors.New("protocalRunerAllocate addrs length != acts length")
+		return nil, nil, nil, errors.New("protocalRunerAllocate addrs length != acts length")
 	}
 
 	if funds == nil {
-		return nil, nil, errors.New("protocalRunerAllocate funds == nil")
+		return nil, nil, nil, errors.New("protocalRunerAllocate funds == nil")
 	}
 
 	fundOne := funds.Div(funds, big.NewInt(int64(count)))
@@ -212,35 +217,35 @@ func protocalRunerAllocate(funds *big.Int, addrs []common.Address, acts []int,
 		}
 	}
 
-	finalIncentive, subRemain, err := delegate(fundAddrs, fundValues, epochID)
+	finalIncentive, fees, subRemain, err := delegate(fundAddrs, fundValues, epochID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	remains.Add(remains, subRemain)
 
-	return finalIncentive, remains, nil
+	return finalIncentive, fees, remains, nil
 }
 
 // epochLeaderAllocate input funds, address and activity returns address and its amount allocate and remaining funds.
 func epochLeaderAllocate(funds *big.Int, addrs []common.Address, acts []int,
-	epochID uint64) ([][]vm.ClientIncentive, *big.Int, error) {
+	epochID uint64) ([][]vm.ClientIncentive, [][]*big.Int, *big.Int, error) {
 	return protocalRunerAllocate(funds, addrs, acts, epochID)
 }
 
 //randomProposerAllocate input funds, address and activity returns address and its amount allocate and remaining funds.
 func randomProposerAllocate(funds *big.Int, addrs []common.Address, acts []int,
-	epochID uint64) ([][]vm.ClientIncentive, *big.Int, error) {
+	epochID uint64) ([][]vm.ClientIncentive, [][]*big.Int, *big.Int, error) {
 	return protocalRunerAllocate(funds, addrs, acts, epochID)
 }
 
 //slotLeaderAllocate input funds, address, blocks and activity returns address and its amount allocate and remaining funds.
 //slotCount is the slot count ctrled by others not foundation.
 func slotLeaderAllocate(funds *big.Int, addrs []common.Address, blocks []int,
-	act float64, slotCount int, epochID uint64) ([][]vm.ClientIncentive, *big.Int, error) {
+	act float64, slotCount int, epochID uint64) ([][]vm.ClientIncentive, [][]*big.Int, *big.Int, error) {
 	remains := big.NewInt(0)
 
 	if addrs == nil || len(addrs) == 0 || slotCount == 0 || act == 0 {
-		return nil, remains.Add(remains, funds), nil
+		return nil, nil, remains.Add(remains, funds), nil
 	}
 
 	scale := 100000.0
@@ -265,13 +270,13 @@ func slotLeaderAllocate(funds *big.Int, addrs []common.Address, blocks []int,
 		fundValues = append(fundValues, big.NewInt(0).Mul(incentiveActive, big.NewInt(int64(blocks[i]))))
 	}
 
-	finalIncentive, subRemain, err := delegate(fundAddrs, fundValues, epochID-1)
+	finalIncentive, fees, subRemain, err := delegate(fundAddrs, fundValues, epochID-1)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	remains.Add(remains, subRemain)
 
-	return finalIncentive, remains, nil
+	return finalIncentive, fees, remains, nil
 }
 
 func sumToPay(readyToPay [][]vm.ClientIncentive) *big.Int {
@@ -0,0 +1,98 @@
+package incentive
+
+import (
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+const (
+	RoleEpochLeader    = "epochLeader"
+	RoleRandomProposer = "randomProposer"
+	RoleSlotLeader     = "slotLeader"
+
+	dictEpochLedger = "epoch_ledger"
+)
+
+// LedgerEntry is one payout of an epoch incentive to a validator or one of its
+// delegators (and partners).
+type LedgerEntry struct {
+	EpochID       uint64
+	Role          string
+	ValidatorAddr common.Address
+	WalletAddr    common.Address
+	Amount        *big.Int
+	FeeDeducted   *big.Int // commission kept by the validator, 0 for the validator itself
+	IsValidator   bool
+}
+
+// appendLedger flattens the incentives of one role into ledger entries. fees has
+// the same layout as incentives, a missing fee is treated as 0.
+func appendLedger(ledger []LedgerEntry, epochID uint64, role string, incentives [][]vm.ClientIncentive, fees [][]*big.Int) []LedgerEntry {
+	for i := 0; i < len(incentives); i++ {
+		for m := 0; m < len(incentives[i]); m++ {
+			fee := big.NewInt(0)
+			if i < len(fees) && m < len(fees[i]) && fees[i][m] != nil {
+				fee = fees[i][m]
+			}
+			ledger = append(ledger, LedgerEntry{
+				EpochID:       epochID,
+				Role:          role,
+				ValidatorAddr: incentives[i][m].ValidatorAddr,
+				WalletAddr:    incentives[i][m].WalletAddr,
+				Amount:        incentives[i][m].Incentive,
+				FeeDeducted:   fee,
+				IsValidator:   m == 0,
+			})
+		}
+	}
+	return ledger
+}
+
+func saveIncentiveLedger(epochID uint64, ledger []LedgerEntry) {
+	buf, err := rlp.EncodeToBytes(ledger)
+	if err != nil {
+		log.SyslogErr(err.Error())
+		return
+	}
+	localDb.Put(epochID, dictEpochLedger, buf)
+}
+
+// GetEpochLedger returns all the payouts of the epoch.
+func GetEpochLedger(epochID uint64) ([]LedgerEntry, error) {
+	buf, err := localDb.Get(epochID, dictEpochLedger)
+	if err != nil {
+		return nil, err
+	}
+
+	var ledger []LedgerEntry
+	err = rlp.DecodeBytes(buf, &ledger)
+	if err != nil {
+		log.SyslogErr(err.Error())
+		return nil, err
+	}
+	return ledger, nil
+}
+
+// GetAddressLedger returns the payouts received by the wallet addr from
+// fromEpoch to toEpoch (both inclusive). Epochs without a ledger are skipped.
+func GetAddressLedger(addr common.Address, fromEpoch, toEpoch uint64) []LedgerEntry {
+	entries := make([]LedgerEntry, 0)
+	for epochID := fromEpoch; epochID <= toEpoch; epochID++ {
+		ledger, err := GetEpochLedger(epochID)
+		if err == nil {
+			for i := 0; i < len(ledger); i++ {
+				if ledger[i].WalletAddr == addr {
+					entries = append(entries, ledger[i])
+				}
+			}
+		}
+		if epochID == toEpoch {
+			break
+		}
+	}
+	return entries
+}
@@ -0,0 +1,42 @@
+package incentive
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/core/vm"
+)
+
+func TestIncentiveLedger(t *testing.T) {
+	generateTestAddrs()
+	testInitDb()
+
+	incentives := [][]vm.ClientIncentive{
+		{
+			{ValidatorAddr: epAddrs[0], WalletAddr: epAddrs[0], Incentive: big.NewInt(110)},
+			{ValidatorAddr: epAddrs[0], WalletAddr: epAddrs[1], Incentive: big.NewInt(90)},
+		},
+	}
+	fees := [][]*big.Int{{big.NewInt(0), big.NewInt(10)}}
+
+	ledger := appendLedger(nil, 5, RoleEpochLeader, incentives, fees)
+	ledger = appendLedger(ledger, 5, RoleSlotLeader, incentives, nil)
+	if len(ledger) != 4 {
+		t.Fatalf("wrong ledger length %d", len(ledger))
+	}
+	if !ledger[0].IsValidator || ledger[1].IsValidator || ledger[1].FeeDeducted.Int64() != 10 || ledger[3].FeeDeducted.Sign() != 0 {
+		t.Fatalf("wrong ledger %v", ledger)
+	}
+	saveIncentiveLedger(5, ledger)
+
+	entries := GetAddressLedger(epAddrs[1], 0, 10)
+	if len(entries) != 2 {
+		t.Fatalf("wrong entries length %d", len(entries))
+	}
+	if entries[0].Role != RoleEpochLeader || entries[1].Role != RoleSlotLeader || entries[0].Amount.Int64() != 90 {
+		t.Fatalf("wrong entries %v", entries)
+	}
+	if entries := GetAddressLedger(epAddrs[1], 6, 10); len(entries) != 0 {
+		t.Fatalf("unexpected entries %v", entries)
+	}
+}
@@ -31,9 +31,11 @@ import (
 	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/internal/ethapi"
 	"github.com/wanchain/go-wanchain/pos/epochLeader"
+	"github.com/wanchain/go-wanchain/pos/equivocation"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 	"github.com/wanchain/go-wanchain/pos/posdb"
 	"github.com/wanchain/go-wanchain/pos/slotleader"
+	"github.com/wanchain/go-wanchain/pos/stakehistory"
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
@@ -41,6 +43,11 @@ var (
 	maxUint64 = uint64(1<<64 - 1)
 )
 
+const (
+	// maxIncentiveEpochRange limits the epochs scanned by one incentive ledger query
+	maxIncentiveEpochRange = 1000
+)
+
 type PosChainReader interface {
 	// Config retrieves the blockchain's chain configuration.
 	Config() *params.ChainConfig
@@ -241,6 +248,19 @@ func (a PosApi) GetSlotCreateStatusByEpochID(epochID uint64) bool {
 	return slotleader.GetSlotLeaderSelection().GetSlotCreateStatusByEpochID(epochID)
 }
 
+// GetSlsWorkStatus returns the slot leader selection work stage and journal of
+// the local node in the epoch.
+func (a PosApi) GetSlsWorkStatus(epochID uint64) (*slotleader.SlsWorkStatus, error) {
+	if !isPosStage() {
+		return nil, nil
+	}
+	s := slotleader.GetSlotLeaderSelection()
+	if s == nil {
+		return nil, errors.New("slot leader selection is not initialized")
+	}
+	return s.GetWorkStatus(epochID), nil
+}
+
 func (a PosApi) GetRandom(epochId uint64, blockNr int64) (*big.Int, error) {
 	if !isPosStage() {
 		return nil, nil
@@ -302,6 +322,43 @@ func (a PosApi) GetReorgState(epochid uint64) ([]uint64, error) {
 	return []uint64{reOrgNum, reOrgLen}, nil
 }
 
+// GetEquivocations returns the evidences of slot leaders sealing two different
+// blocks for the same slot, detected by the local node from fromEpoch to toEpoch
+// (both inclusive).
+func (a PosApi) GetEquivocations(fromEpoch uint64, toEpoch uint64) ([]*equivocation.Evidence, error) {
+	return equivocation.GetEvidences(fromEpoch, toEpoch)
+}
+
+// Equivocations streams the evidences of the equivocations detected from now on.
+func (a PosApi) Equivocations(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan equivocation.EvidenceEvent)
+		sub := equivocation.SubscribeEvidenceEvent(events)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev.Evidence)
+			case <-sub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 func (a PosApi) GetRbSignatureCount(epochId uint64, blockNr int64) (int, error) {
 	if !isPosStage() {
 		return 0, nil
@@ -402,6 +459,33 @@ func (a PosApi) GetStakerInfo(targetBlkNum uint64) ([]*StakerJson, error) {
 	return stakers, nil
 }
 
+// GetStakerHistory returns the stakeIn, stakeAppend, stakeUpdate, fee rate, partner and
+// delegation events of the validator addr from fromEpoch to toEpoch (both inclusive).
+// Only blocks covered by a finished stake history section are included.
+func (a PosApi) GetStakerHistory(addr common.Address, fromEpoch uint64, toEpoch uint64) ([]StakeEventJson, error) {
+	if fromEpoch > toEpoch {
+		return nil, errors.New("fromEpoch should not be bigger than toEpoch")
+	}
+	events, err := stakehistory.GetStakerHistory(addr, fromEpoch, toEpoch)
+	if err != nil {
+		return nil, err
+	}
+	return ToStakeEventJson(events), nil
+}
+
+// GetDelegatorHistory returns the delegateIn, delegateOut and partnerIn events sent by
+// addr from fromEpoch to toEpoch (both inclusive).
+func (a PosApi) GetDelegatorHistory(addr common.Address, fromEpoch uint64, toEpoch uint64) ([]StakeEventJson, error) {
+	if fromEpoch > toEpoch {
+		return nil, errors.New("fromEpoch should not be bigger than toEpoch")
+	}
+	events, err := stakehistory.GetDelegatorHistory(addr, fromEpoch, toEpoch)
+	if err != nil {
+		return nil, err
+	}
+	return ToStakeEventJson(events), nil
+}
+
 func isPosStage() bool {
 	return posconfig.FirstEpochId != 0
 }
@@ -504,6 +588,21 @@ func (a PosApi) GetEpochIncentivePayDetail(epochID uint64) ([]ValidatorInfo, err
 	return ret, nil
 }
 
+// GetDelegatorIncentives returns every incentive payout received by the wallet addr
+// from fromEpoch to toEpoch (both inclusive), split by role and validator.
+func (a PosApi) GetDelegatorIncentives(addr common.Address, fromEpoch uint64, toEpoch uint64) ([]IncentiveLedgerJson, error) {
+	if !isPosStage() {
+		return nil, nil
+	}
+	if fromEpoch > toEpoch {
+		return nil, errors.New("fromEpoch should not be bigger than toEpoch")
+	}
+	if toEpoch-fromEpoch >= maxIncentiveEpochRange {
+		return nil, fmt.Errorf("epoch range should be less than %d", maxIncentiveEpochRange)
+	}
+	return ToIncentiveLedgerJson(incentive.GetAddressLedger(addr, fromEpoch, toEpoch)), nil
+}
+
 func (a PosApi) GetTotalIncentive() (string, error) {
 	if !isPosStage() {
 		return "Not POS stage.", nil
@@ -900,6 +999,116 @@ func (a PosApi) GetValidRBCnt(epochId uint64) ([]uint64, error) {
 	return cnts, err
 }
 
+// GetRBParticipation returns the dkg1, dkg2 and sig participation of each random
+// beacon proposer of the epoch. The state is read at the chain head, the
+// transactions are collected from the blocks of the epoch, and the failures are
+// the validation errors this node saw in its tx pool or while executing blocks.
+func (a PosApi) GetRBParticipation(epochId uint64) (*RBParticipationJson, error) {
+	if !isPosStage() {
+		return nil, nil
+	}
+	selector := epochLeader.GetEpocher()
+	if selector == nil {
+		return nil, errors.New("GetEpocherInst error")
+	}
+	stateDb, _, err := a.backend.StateAndHeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	leaders := selector.GetRBProposerGroup(epochId)
+	ret := &RBParticipationJson{
+		EpochId:   epochId,
+		Dkg1Count: vm.GetValidDkg1Cnt(stateDb, epochId),
+		Dkg2Count: vm.GetValidDkg2Cnt(stateDb, epochId),
+		SigCount:  vm.GetValidSigCnt(stateDb, epochId),
+		Proposers: make([]RBProposerJson, len(leaders)),
+	}
+	for i := range leaders {
+		pid := uint32(i)
+		p := &ret.Proposers[i]
+		p.ProposerId = pid
+		p.Address = leaders[i].SecAddr
+		p.Active = vm.IsRBActive(stateDb, epochId, pid)
+
+		cij, err := vm.GetCji(stateDb, epochId, pid)
+		p.Dkg1.Submitted = err == nil && len(cij) != 0
+		p.Dkg2.Submitted = vm.IsJoinDKG2(stateDb, epochId, pid)
+		sig, err := vm.GetSig(stateDb, epochId, pid)
+		p.Sig.Submitted = err == nil && sig != nil
+	}
+
+	if err := a.collectRBTransactions(epochId, ret); err != nil {
+		return nil, err
+	}
+
+	for _, f := range vm.GetRBFailures(epochId) {
+		if int(f.ProposerId) >= len(ret.Proposers) {
+			continue
+		}
+		stage := ret.Proposers[f.ProposerId].stage(f.Stage)
+		if stage == nil {
+			continue
+		}
+		stage.Failures = append(stage.Failures, RBFailureJson{Sender: f.Sender, Time: f.Time, Error: f.Error, Count: f.Count})
+	}
+	return ret, nil
+}
+
+// collectRBTransactions walks back the blocks of the epoch from its last block,
+// and adds the random beacon transactions of the epoch to the proposers of ret.
+func (a PosApi) collectRBTransactions(epochId uint64, ret *RBParticipationJson) error {
+	number := util.GetEpochBlock(epochId)
+	if number == 0 {
+		return nil
+	}
+	if head := a.chain.CurrentHeader().Number.Uint64(); number > head {
+		number = head
+	}
+
+	stages := map[string]string{
+		string(vm.GetDkg1Id()):     vm.RBStageDkg1,
+		string(vm.GetDkg2Id()):     vm.RBStageDkg2,
+		string(vm.GetSigShareId()): vm.RBStageSig,
+	}
+
+	for i := 0; i < posconfig.SlotCount && number > 0; i, number = i+1, number-1 {
+		header := a.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return errors.New("get header by number fail")
+		}
+		ep, _ := util.CalEpSlbyTd(header.Difficulty.Uint64())
+		if ep < epochId {
+			break
+		}
+		if ep > epochId {
+			continue
+		}
+
+		block := a.chain.GetBlock(header.Hash(), number)
+		if block == nil {
+			return errors.New("get block fail")
+		}
+		for _, tx := range block.Transactions() {
+			if tx.To() == nil || *tx.To() != vm.RandomBeaconPrecompileAddr || len(tx.Data()) < 4 {
+				continue
+			}
+			stageName, ok := stages[string(tx.Data()[:4])]
+			if !ok {
+				continue
+			}
+			eid, pid, err := vm.DecodeRBPayloadHead(tx.Data()[4:])
+			if err != nil || eid != epochId || int(pid) >= len(ret.Proposers) {
+				continue
+			}
+			stage := ret.Proposers[pid].stage(stageName)
+			stage.TxHashes = append(stage.TxHashes, tx.Hash())
+			stage.BlockNumbers = append(stage.BlockNumbers, number)
+		}
+	}
+	return nil
+}
+
 func (a PosApi) GetRbStage(slotId uint64) uint64 {
 	stage, _, _ := vm.GetRBStage(slotId)
 	return uint64(stage)
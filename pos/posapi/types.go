@@ -6,6 +6,8 @@ import (
 	"github.com/wanchain/go-wanchain/common/math"
 	"github.com/wanchain/go-wanchain/core/vm"
 	"github.com/wanchain/go-wanchain/pos/epochLeader"
+	"github.com/wanchain/go-wanchain/pos/incentive"
+	"github.com/wanchain/go-wanchain/pos/stakehistory"
 )
 
 type ValidatorActivity struct {
@@ -171,3 +173,108 @@ func ToStakerJson(staker *vm.StakerInfo) *StakerJson {
 
 	return &stakeJson
 }
+
+type StakeEventJson struct {
+	Event       string                `json:"event"`
+	EpochId     uint64                `json:"epochId"`
+	BlockNumber uint64                `json:"blockNumber"`
+	TxHash      common.Hash           `json:"txHash"`
+	LogIndex    uint64                `json:"logIndex"`
+	From        common.Address        `json:"from"`
+	Validator   common.Address        `json:"validator"`
+	Value       *math.HexOrDecimal256 `json:"value"`
+	FeeRate     uint64                `json:"feeRate"`
+	MaxFeeRate  uint64                `json:"maxFeeRate"`
+	LockEpochs  uint64                `json:"lockEpochs"`
+	Renewal     bool                  `json:"renewal"`
+}
+
+func ToStakeEventJson(events []stakehistory.StakeEvent) []StakeEventJson {
+	ej := make([]StakeEventJson, len(events))
+	for i := 0; i < len(events); i++ {
+		ej[i].Event = events[i].Event
+		ej[i].EpochId = events[i].EpochId
+		ej[i].BlockNumber = events[i].BlockNumber
+		ej[i].TxHash = events[i].TxHash
+		ej[i].LogIndex = events[i].LogIndex
+		ej[i].From = events[i].Sender
+		ej[i].Validator = events[i].Validator
+		ej[i].Value = (*math.HexOrDecimal256)(events[i].Value)
+		ej[i].FeeRate = events[i].FeeRate
+		ej[i].MaxFeeRate = events[i].MaxFeeRate
+		ej[i].LockEpochs = events[i].LockEpochs
+		ej[i].Renewal = events[i].Renewal
+	}
+	return ej
+}
+
+type IncentiveLedgerJson struct {
+	EpochId     uint64                `json:"epochId"`
+	Role        string                `json:"role"`
+	Validator   common.Address        `json:"validator"`
+	Address     common.Address        `json:"address"`
+	Amount      *math.HexOrDecimal256 `json:"amount"`
+	FeeDeducted *math.HexOrDecimal256 `json:"feeDeducted"`
+	Type        string                `json:"type"`
+}
+
+func ToIncentiveLedgerJson(entries []incentive.LedgerEntry) []IncentiveLedgerJson {
+	lj := make([]IncentiveLedgerJson, len(entries))
+	for i := 0; i < len(entries); i++ {
+		lj[i].EpochId = entries[i].EpochID
+		lj[i].Role = entries[i].Role
+		lj[i].Validator = entries[i].ValidatorAddr
+		lj[i].Address = entries[i].WalletAddr
+		lj[i].Amount = (*math.HexOrDecimal256)(entries[i].Amount)
+		lj[i].FeeDeducted = (*math.HexOrDecimal256)(entries[i].FeeDeducted)
+		if entries[i].IsValidator {
+			lj[i].Type = "validator"
+		} else {
+			lj[i].Type = "delegator"
+		}
+	}
+	return lj
+}
+
+type RBFailureJson struct {
+	Sender common.Address `json:"sender"`
+	Time   uint64         `json:"time"`
+	Error  string         `json:"error"`
+	Count  uint64         `json:"count"`
+}
+
+type RBStageJson struct {
+	Submitted    bool            `json:"submitted"`
+	TxHashes     []common.Hash   `json:"txHashes"`
+	BlockNumbers []uint64        `json:"blockNumbers"`
+	Failures     []RBFailureJson `json:"failures"`
+}
+
+type RBProposerJson struct {
+	ProposerId uint32         `json:"proposerId"`
+	Address    common.Address `json:"address"`
+	Active     bool           `json:"active"`
+	Dkg1       RBStageJson    `json:"dkg1"`
+	Dkg2       RBStageJson    `json:"dkg2"`
+	Sig        RBStageJson    `json:"sig"`
+}
+
+type RBParticipationJson struct {
+	EpochId   uint64           `json:"epochId"`
+	Dkg1Count uint64           `json:"dkg1Count"`
+	Dkg2Count uint64           `json:"dkg2Count"`
+	SigCount  uint64           `json:"sigCount"`
+	Proposers []RBProposerJson `json:"proposers"`
+}
+
+func (p *RBProposerJson) stage(stage string) *RBStageJson {
+	switch stage {
+	case vm.RBStageDkg1:
+		return &p.Dkg1
+	case vm.RBStageDkg2:
+		return &p.Dkg2
+	case vm.RBStageSig:
+		return &p.Sig
+	}
+	return nil
+}
@@ -24,17 +24,21 @@ var (
 	SelfTestMode = false
 	IsDev        = false
 	MineEnabled  = false
+	// RemoteSigner is the endpoint of the signer holding the validator keys,
+	// empty to sign with the unlocked etherbase key.
+	RemoteSigner = ""
 )
 
 const (
-	RbLocalDB        = "rblocaldb"
-	EpLocalDB        = "eplocaldb"
-	StakerLocalDB    = "stlocaldb"
-	PosLocalDB       = "pos"
-	IncentiveLocalDB = "incentive"
-	ReorgLocalDB     = "forkdb"
-	ApolloEpochID    = 18104
-	AugustEpochID    = 18116 //TODO change it as mainnet 8.8
+	RbLocalDB           = "rblocaldb"
+	EpLocalDB           = "eplocaldb"
+	StakerLocalDB       = "stlocaldb"
+	PosLocalDB          = "pos"
+	IncentiveLocalDB    = "incentive"
+	ReorgLocalDB        = "forkdb"
+	EquivocationLocalDB = "equivocation"
+	ApolloEpochID       = 18104
+	AugustEpochID       = 18116 //TODO change it as mainnet 8.8
 
 )
 
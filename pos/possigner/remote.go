@@ -0,0 +1,287 @@
+package possigner
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/crypto"
+	bn256 "github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+// Namespace is the json-rpc namespace of the signer service.
+const Namespace = "signer"
+
+var errInvalidAccount = errors.New("invalid remote signer account")
+
+// Account is the validator account served by a signer.
+type Account struct {
+	Address        common.Address `json:"address"`
+	PublicKey      hexutil.Bytes  `json:"publicKey"`
+	Bn256PublicKey hexutil.Bytes  `json:"bn256PublicKey"`
+}
+
+// SlotLeaderProofArgs are the inputs of a slot leader proof.
+type SlotLeaderProofArgs struct {
+	SMA        []hexutil.Bytes `json:"sma"`
+	PublicKeys []hexutil.Bytes `json:"publicKeys"`
+	RB         hexutil.Bytes   `json:"rb"`
+	SlotID     hexutil.Uint64  `json:"slotId"`
+	EpochID    hexutil.Uint64  `json:"epochId"`
+}
+
+// SlotLeaderProof is the proof message and the proof of a slot leader.
+type SlotLeaderProof struct {
+	ProofMeg []hexutil.Bytes `json:"proofMeg"`
+	Proof    []*hexutil.Big  `json:"proof"`
+}
+
+// Service exposes a Signer over json-rpc, it's run by the process holding the
+// validator keys.
+type Service struct {
+	signer Signer
+}
+
+// NewService returns a service of the signer.
+func NewService(signer Signer) *Service {
+	return &Service{signer: signer}
+}
+
+func (s *Service) Account() *Account {
+	ret := &Account{
+		Address:   s.signer.Address(),
+		PublicKey: crypto.FromECDSAPub(s.signer.PublicKey()),
+	}
+	if pk := s.signer.Bn256PublicKey(); pk != nil {
+		ret.Bn256PublicKey = pk.Marshal()
+	}
+	return ret
+}
+
+func (s *Service) SignHash(hash hexutil.Bytes) (hexutil.Bytes, error) {
+	return s.signer.SignHash(hash)
+}
+
+func (s *Service) SlotLeaderProof(args SlotLeaderProofArgs) (*SlotLeaderProof, error) {
+	sma, err := decodePks(args.SMA)
+	if err != nil {
+		return nil, err
+	}
+	pks, err := decodePks(args.PublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	proofMeg, proof, err := s.signer.SlotLeaderProof(sma, pks, args.RB, uint64(args.SlotID), uint64(args.EpochID))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &SlotLeaderProof{ProofMeg: encodePks(proofMeg), Proof: make([]*hexutil.Big, len(proof))}
+	for i := range proof {
+		ret.Proof[i] = (*hexutil.Big)(proof[i])
+	}
+	return ret, nil
+}
+
+func (s *Service) GenerateSMA(pieces []hexutil.Bytes) ([]hexutil.Bytes, error) {
+	pks, err := decodePks(pieces)
+	if err != nil {
+		return nil, err
+	}
+	sma, err := s.signer.GenerateSMA(pks)
+	if err != nil {
+		return nil, err
+	}
+	return encodePks(sma), nil
+}
+
+func (s *Service) RbSignShare(ens []hexutil.Bytes, m *hexutil.Big) (hexutil.Bytes, error) {
+	points := make([]*bn256.G1, len(ens))
+	for i := range ens {
+		points[i] = new(bn256.G1)
+		if _, err := points[i].Unmarshal(ens[i]); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if m == nil {
+		return nil, ErrInvalidInput
+	}
+
+	share, err := s.signer.RbSignShare(points, m.ToInt())
+	if err != nil {
+		return nil, err
+	}
+	return share.Marshal(), nil
+}
+
+// Serve serves the signer on the ipc endpoint (a unix socket or a windows named
+// pipe) until the returned listener is closed.
+func Serve(endpoint string, signer Signer) (net.Listener, error) {
+	server := rpc.NewServer()
+	if err := server.RegisterName(Namespace, NewService(signer)); err != nil {
+		return nil, err
+	}
+
+	listener, err := rpc.CreateIPCListener(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	go server.ServeListener(listener)
+
+	log.Info("Validator signer started", "endpoint", endpoint, "address", signer.Address().Hex())
+	return listener, nil
+}
+
+// RemoteSigner is a Signer calling a signer service over json-rpc, the node then
+// holds no validator secret.
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+	pk      *ecdsa.PublicKey
+	bn256Pk *bn256.G1
+}
+
+// NewRemoteSigner dials the signer service at the endpoint, an ipc path or an
+// http/ws url, and fetches the validator account.
+func NewRemoteSigner(endpoint string) (*RemoteSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := NewRemoteSignerWithClient(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return signer, nil
+}
+
+// NewRemoteSignerWithClient returns a signer calling the service over the client.
+func NewRemoteSignerWithClient(client *rpc.Client) (*RemoteSigner, error) {
+	var account Account
+	if err := client.Call(&account, Namespace+"_account"); err != nil {
+		return nil, err
+	}
+
+	pk := crypto.ToECDSAPub(account.PublicKey)
+	if pk == nil || pk.X == nil || crypto.PubkeyToAddress(*pk) != account.Address {
+		return nil, errInvalidAccount
+	}
+
+	r := &RemoteSigner{client: client, address: account.Address, pk: pk}
+	if len(account.Bn256PublicKey) != 0 {
+		r.bn256Pk = new(bn256.G1)
+		if _, err := r.bn256Pk.Unmarshal(account.Bn256PublicKey); err != nil {
+			return nil, errInvalidAccount
+		}
+	}
+	return r, nil
+}
+
+func (r *RemoteSigner) Address() common.Address {
+	return r.address
+}
+
+func (r *RemoteSigner) PublicKey() *ecdsa.PublicKey {
+	return r.pk
+}
+
+func (r *RemoteSigner) Bn256PublicKey() *bn256.G1 {
+	return r.bn256Pk
+}
+
+func (r *RemoteSigner) SignHash(hash []byte) ([]byte, error) {
+	if len(hash) != common.HashLength {
+		return nil, ErrInvalidHashLen
+	}
+
+	var sig hexutil.Bytes
+	if err := r.client.Call(&sig, Namespace+"_signHash", hexutil.Bytes(hash)); err != nil {
+		return nil, err
+	}
+	if pub, err := crypto.SigToPub(hash, sig); err != nil || crypto.PubkeyToAddress(*pub) != r.address {
+		return nil, errors.New("remote signer returned an invalid signature")
+	}
+	return sig, nil
+}
+
+func (r *RemoteSigner) SlotLeaderProof(sma []*ecdsa.PublicKey, publicKeys []*ecdsa.PublicKey, rb []byte,
+	slotID uint64, epochID uint64) ([]*ecdsa.PublicKey, []*big.Int, error) {
+	args := SlotLeaderProofArgs{
+		SMA:        encodePks(sma),
+		PublicKeys: encodePks(publicKeys),
+		RB:         rb,
+		SlotID:     hexutil.Uint64(slotID),
+		EpochID:    hexutil.Uint64(epochID),
+	}
+
+	var ret SlotLeaderProof
+	if err := r.client.Call(&ret, Namespace+"_slotLeaderProof", args); err != nil {
+		return nil, nil, err
+	}
+
+	proofMeg, err := decodePks(ret.ProofMeg)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof := make([]*big.Int, len(ret.Proof))
+	for i := range ret.Proof {
+		if ret.Proof[i] == nil {
+			return nil, nil, ErrInvalidInput
+		}
+		proof[i] = ret.Proof[i].ToInt()
+	}
+	return proofMeg, proof, nil
+}
+
+func (r *RemoteSigner) GenerateSMA(pieces []*ecdsa.PublicKey) ([]*ecdsa.PublicKey, error) {
+	var ret []hexutil.Bytes
+	if err := r.client.Call(&ret, Namespace+"_generateSMA", encodePks(pieces)); err != nil {
+		return nil, err
+	}
+	return decodePks(ret)
+}
+
+func (r *RemoteSigner) RbSignShare(ens []*bn256.G1, m *big.Int) (*bn256.G1, error) {
+	points := make([]hexutil.Bytes, len(ens))
+	for i := range ens {
+		points[i] = ens[i].Marshal()
+	}
+
+	var ret hexutil.Bytes
+	if err := r.client.Call(&ret, Namespace+"_rbSignShare", points, (*hexutil.Big)(m)); err != nil {
+		return nil, err
+	}
+
+	share := new(bn256.G1)
+	if _, err := share.Unmarshal(ret); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+func encodePks(pks []*ecdsa.PublicKey) []hexutil.Bytes {
+	ret := make([]hexutil.Bytes, len(pks))
+	for i := range pks {
+		ret[i] = crypto.FromECDSAPub(pks[i])
+	}
+	return ret
+}
+
+func decodePks(bufs []hexutil.Bytes) ([]*ecdsa.PublicKey, error) {
+	ret := make([]*ecdsa.PublicKey, len(bufs))
+	for i := range bufs {
+		ret[i] = crypto.ToECDSAPub(bufs[i])
+		if ret[i] == nil || ret[i].X == nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,243 @@
+// Package possigner abstracts the validator crypto of the pos consensus, so that
+// the validator keys can be kept out of the node process by a remote signer.
+package possigner
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/wanchain/go-wanchain/accounts"
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	bn256 "github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/uleaderselection"
+)
+
+var (
+	ErrNoSigner       = errors.New("no validator signer")
+	ErrNoBn256Key     = errors.New("validator signer has no bn256 key")
+	ErrInvalidHashLen = errors.New("hash to sign must be 32 bytes")
+	ErrInvalidInput   = errors.New("invalid signer input")
+)
+
+// Signer performs all the operations needing the validator secret keys: the
+// block seal, the slot leader proof, the SMA of the slot leader selection and
+// the random beacon signature share. The dkg shares of the random beacon are
+// encrypted to the other proposers and don't need the secret keys.
+type Signer interface {
+	// Address returns the validator account address.
+	Address() common.Address
+	// PublicKey returns the secp256k1 public key of the validator.
+	PublicKey() *ecdsa.PublicKey
+	// Bn256PublicKey returns the bn256 public key of the random beacon, or nil.
+	Bn256PublicKey() *bn256.G1
+
+	// SignHash signs the 32 bytes hash with the validator key.
+	SignHash(hash []byte) ([]byte, error)
+	// SlotLeaderProof generates the slot leader proof, see uleaderselection.GenerateSlotLeaderProof.
+	SlotLeaderProof(sma []*ecdsa.PublicKey, publicKeys []*ecdsa.PublicKey, rb []byte, slotID uint64,
+		epochID uint64) ([]*ecdsa.PublicKey, []*big.Int, error)
+	// GenerateSMA computes the SMA pieces, see uleaderselection.GenerateSMA.
+	GenerateSMA(pieces []*ecdsa.PublicKey) ([]*ecdsa.PublicKey, error)
+	// RbSignShare returns (sk^-1)*(ens[0]+...+ens[n-1])*m, the random beacon
+	// signature share from the encrypted shares sent to the validator.
+	RbSignShare(ens []*bn256.G1, m *big.Int) (*bn256.G1, error)
+}
+
+// LocalSigner is a Signer backed by a decrypted key in memory. It's used when no
+// remote signer is configured, in tests, and by the remote signer process.
+type LocalSigner struct {
+	key *keystore.Key
+}
+
+// NewLocalSigner returns a signer of the key.
+func NewLocalSigner(key *keystore.Key) *LocalSigner {
+	return &LocalSigner{key: key}
+}
+
+func (l *LocalSigner) Address() common.Address {
+	if l.key.Address == (common.Address{}) && l.key.PrivateKey != nil {
+		return crypto.PubkeyToAddress(l.key.PrivateKey.PublicKey)
+	}
+	return l.key.Address
+}
+
+func (l *LocalSigner) PublicKey() *ecdsa.PublicKey {
+	if l.key.PrivateKey == nil {
+		return nil
+	}
+	return &l.key.PrivateKey.PublicKey
+}
+
+func (l *LocalSigner) Bn256PublicKey() *bn256.G1 {
+	sk := l.bn256SK()
+	if sk == nil {
+		return nil
+	}
+	return new(bn256.G1).ScalarBaseMult(sk)
+}
+
+func (l *LocalSigner) bn256SK() *big.Int {
+	if l.key.PrivateKey2 == nil || l.key.PrivateKey2.D == nil {
+		return nil
+	}
+	return posconfig.GenerateD3byKey2(l.key.PrivateKey2)
+}
+
+func (l *LocalSigner) SignHash(hash []byte) ([]byte, error) {
+	if len(hash) != common.HashLength {
+		return nil, ErrInvalidHashLen
+	}
+	if l.key.PrivateKey == nil {
+		return nil, ErrNoSigner
+	}
+	return crypto.Sign(hash, l.key.PrivateKey)
+}
+
+func (l *LocalSigner) SlotLeaderProof(sma []*ecdsa.PublicKey, publicKeys []*ecdsa.PublicKey, rb []byte,
+	slotID uint64, epochID uint64) ([]*ecdsa.PublicKey, []*big.Int, error) {
+	return uleaderselection.GenerateSlotLeaderProof(l.key.PrivateKey, sma, publicKeys, rb, slotID, epochID)
+}
+
+func (l *LocalSigner) GenerateSMA(pieces []*ecdsa.PublicKey) ([]*ecdsa.PublicKey, error) {
+	return uleaderselection.GenerateSMA(l.key.PrivateKey, pieces)
+}
+
+func (l *LocalSigner) RbSignShare(ens []*bn256.G1, m *big.Int) (*bn256.G1, error) {
+	sk := l.bn256SK()
+	if sk == nil {
+		return nil, ErrNoBn256Key
+	}
+	if m == nil {
+		return nil, ErrInvalidInput
+	}
+
+	// sk^-1
+	skinver := new(big.Int).ModInverse(sk, bn256.Order)
+	gskshare := new(bn256.G1).ScalarBaseMult(big.NewInt(0))
+	for _, en := range ens {
+		if en == nil {
+			return nil, ErrInvalidInput
+		}
+		gskshare.Add(gskshare, new(bn256.G1).ScalarMult(en, skinver))
+	}
+	return new(bn256.G1).ScalarMult(gskshare, m), nil
+}
+
+var (
+	remoteMu  sync.Mutex
+	remote    Signer
+	remoteURL string
+)
+
+// SetSigner sets the signer used instead of the unlocked validator key, nil
+// clears it.
+func SetSigner(signer Signer) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+
+	remote = signer
+	remoteURL = posconfig.RemoteSigner
+}
+
+// Remote returns the remote signer configured by posconfig.RemoteSigner, it's
+// dialed on the first call. It returns nil if there is no remote signer.
+func Remote() (Signer, error) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+
+	if remote != nil && remoteURL == posconfig.RemoteSigner {
+		return remote, nil
+	}
+	if posconfig.RemoteSigner == "" {
+		return nil, nil
+	}
+
+	signer, err := NewRemoteSigner(posconfig.RemoteSigner)
+	if err != nil {
+		log.SyslogErr("dial remote signer fail", "url", posconfig.RemoteSigner, "err", err.Error())
+		return nil, err
+	}
+	log.SyslogInfo("remote signer connected", "url", posconfig.RemoteSigner, "address", signer.Address().Hex())
+	remote, remoteURL = signer, posconfig.RemoteSigner
+	return remote, nil
+}
+
+// ForKey returns the remote signer if there is one, otherwise a local signer
+// of the key.
+func ForKey(key *keystore.Key) (Signer, error) {
+	signer, err := Remote()
+	if err != nil {
+		return nil, err
+	}
+	if signer != nil {
+		return signer, nil
+	}
+	if key == nil || (key.PrivateKey == nil && key.PrivateKey2 == nil) {
+		return nil, ErrNoSigner
+	}
+	return NewLocalSigner(key), nil
+}
+
+// SignFn adapts the signer to the signer callback of the consensus engines.
+func SignFn(signer Signer) func(accounts.Account, []byte) ([]byte, error) {
+	return func(_ accounts.Account, hash []byte) ([]byte, error) {
+		return signer.SignHash(hash)
+	}
+}
+
+// SignTx signs the transaction with the hash signing of the signer.
+func SignTx(signer Signer, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	var txSigner types.Signer = types.HomesteadSigner{}
+	if chainID != nil {
+		txSigner = types.NewEIP155Signer(chainID)
+	}
+	sig, err := signer.SignHash(txSigner.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(txSigner, sig)
+}
+
+// ValidatorKey returns the key and the seal callback of the etherbase. With a
+// remote signer the key only carries the address, otherwise it's the unlocked
+// key of the local keystore.
+func ValidatorKey(am *accounts.Manager, eb common.Address) (*keystore.Key, func(accounts.Account, []byte) ([]byte, error), error) {
+	signer, err := Remote()
+	if err != nil {
+		return nil, nil, err
+	}
+	if signer != nil {
+		if signer.Address() != eb {
+			return nil, nil, fmt.Errorf("remote signer address %s isn't the etherbase %s", signer.Address().Hex(), eb.Hex())
+		}
+		return &keystore.Key{Address: eb}, SignFn(signer), nil
+	}
+
+	wallet, err := am.Find(accounts.Account{Address: eb})
+	if wallet == nil || err != nil {
+		return nil, nil, fmt.Errorf("signer missing: %v", err)
+	}
+	type getKey interface {
+		GetUnlockedKey(address common.Address) (*keystore.Key, error)
+	}
+	unlocker, ok := wallet.(getKey)
+	if !ok {
+		return nil, nil, ErrNoSigner
+	}
+	key, err := unlocker.GetUnlockedKey(eb)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil {
+		return nil, nil, ErrNoSigner
+	}
+	return key, wallet.SignHash, nil
+}
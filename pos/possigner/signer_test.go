@@ -0,0 +1,156 @@
+package possigner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/crypto"
+	bn256 "github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
+	"github.com/wanchain/go-wanchain/pos/uleaderselection"
+	"github.com/wanchain/go-wanchain/rpc"
+)
+
+func newTestSigners(t *testing.T) (*LocalSigner, *RemoteSigner) {
+	sk1, _ := crypto.GenerateKey()
+	sk2, _ := crypto.GenerateKey()
+	local := NewLocalSigner(&keystore.Key{
+		Address:     crypto.PubkeyToAddress(sk1.PublicKey),
+		PrivateKey:  sk1,
+		PrivateKey2: sk2,
+	})
+
+	server := rpc.NewServer()
+	if err := server.RegisterName(Namespace, NewService(local)); err != nil {
+		t.Fatal(err)
+	}
+	remote, err := NewRemoteSignerWithClient(rpc.DialInProc(server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return local, remote
+}
+
+func TestRemoteSignerAccount(t *testing.T) {
+	local, remote := newTestSigners(t)
+
+	if remote.Address() != local.Address() {
+		t.Fatal("wrong address", remote.Address().Hex())
+	}
+	if !uleaderselection.PublicKeyEqual(remote.PublicKey(), local.PublicKey()) {
+		t.Fatal("wrong public key")
+	}
+	if remote.Bn256PublicKey().String() != local.Bn256PublicKey().String() {
+		t.Fatal("wrong bn256 public key")
+	}
+
+	hash := crypto.Keccak256([]byte("block"))
+	sig, err := remote.SignHash(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != local.Address() {
+		t.Fatal("wrong signature", err)
+	}
+	if _, err := remote.SignHash(hash[1:]); err != ErrInvalidHashLen {
+		t.Fatal("short hash should be rejected", err)
+	}
+}
+
+func TestRemoteSignerSlotLeader(t *testing.T) {
+	local, remote := newTestSigners(t)
+
+	pieces := make([]*ecdsa.PublicKey, 3)
+	for i := range pieces {
+		alpha := big.NewInt(int64(i + 2))
+		pieces[i] = new(ecdsa.PublicKey)
+		pieces[i].Curve = crypto.S256()
+		pieces[i].X, pieces[i].Y = crypto.S256().ScalarMult(local.PublicKey().X, local.PublicKey().Y, alpha.Bytes())
+	}
+
+	sma, err := remote.GenerateSMA(pieces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, _ := local.GenerateSMA(pieces)
+	for i := range expect {
+		if !uleaderselection.PublicKeyEqual(sma[i], expect[i]) {
+			t.Fatal("wrong sma", i)
+		}
+	}
+
+	// the only epoch leader is the leader of every slot
+	pks := []*ecdsa.PublicKey{local.PublicKey()}
+	rb := []byte{1}
+	proofMeg, proof, err := remote.SlotLeaderProof(sma, pks, rb, 7, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !uleaderselection.VerifySlotLeaderProof(proof, proofMeg, pks, rb) {
+		t.Fatal("invalid slot leader proof")
+	}
+}
+
+func TestRemoteSignerRbSignShare(t *testing.T) {
+	local, remote := newTestSigners(t)
+
+	ens := []*bn256.G1{
+		new(bn256.G1).ScalarBaseMult(big.NewInt(11)),
+		new(bn256.G1).ScalarBaseMult(big.NewInt(13)),
+	}
+	m := big.NewInt(1234)
+
+	share, err := remote.RbSignShare(ens, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, _ := local.RbSignShare(ens, m)
+	if share.String() != expect.String() {
+		t.Fatal("wrong sign share")
+	}
+
+	// sk*share == (11+13)*m*G
+	got := new(bn256.G1).ScalarMult(share, local.bn256SK())
+	want := new(bn256.G1).ScalarBaseMult(big.NewInt(24 * 1234))
+	if got.String() != want.String() {
+		t.Fatal("sign share doesn't match the bn256 key")
+	}
+}
+
+func TestForKey(t *testing.T) {
+	if _, err := ForKey(nil); err != ErrNoSigner {
+		t.Fatal("no key should have no signer", err)
+	}
+
+	local, remote := newTestSigners(t)
+	signer, err := ForKey(local.key)
+	if err != nil || signer.Address() != local.Address() {
+		t.Fatal("local key should be used", err)
+	}
+
+	SetSigner(remote)
+	defer SetSigner(nil)
+	signer, err = ForKey(&keystore.Key{Address: remote.Address()})
+	if err != nil || signer != Signer(remote) {
+		t.Fatal("remote signer should be used", err)
+	}
+}
+
+func TestSignTx(t *testing.T) {
+	local, remote := newTestSigners(t)
+
+	tx := types.NewTransaction(0, common.HexToAddress("0xaa"), big.NewInt(0), big.NewInt(21000), big.NewInt(1), nil)
+	tx.SetTxtype(types.POS_TX)
+	signed, err := SignTx(remote, tx, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := types.Sender(types.NewEIP155Signer(big.NewInt(3)), signed)
+	if err != nil || from != local.Address() {
+		t.Fatal("wrong sender", from.Hex(), err)
+	}
+}
@@ -0,0 +1,209 @@
+package randombeacon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+const (
+	rbJournalKey = "RB_JOURNAL"
+
+	// RbJournalVersion is the version of the stored journal, journals of other
+	// versions are dropped on load.
+	RbJournalVersion = 1
+)
+
+// status of a random beacon task in the journal
+const (
+	RbTaskNone      = 0
+	RbTaskGenerated = 1 // payload generated, tx not sent
+	RbTaskSent      = 2 // tx handed to the tx pool
+	RbTaskIncluded  = 3 // payload found in the chain state
+)
+
+// RbJournalEntry is the progress of the dkg1, dkg2 or sig task of one proposer.
+type RbJournalEntry struct {
+	ProposerId uint32
+	Stage      uint64
+	Status     uint64
+	TxHash     common.Hash
+	Time       uint64
+}
+
+// RbJournal records the random beacon work of the local node in one epoch, so that
+// a restarted node neither resends nor skips its transactions.
+type RbJournal struct {
+	Version uint64
+	EpochId uint64
+	Entries []RbJournalEntry
+}
+
+type rbJournal struct {
+	mu   sync.Mutex
+	data RbJournal
+}
+
+func newRbJournal(epochId uint64) *rbJournal {
+	return &rbJournal{data: RbJournal{Version: RbJournalVersion, EpochId: epochId}}
+}
+
+// loadRbJournal loads the journal of the epoch, or returns an empty one if there
+// isn't a valid one stored.
+func loadRbJournal(epochId uint64) *rbJournal {
+	j := newRbJournal(epochId)
+	b, err := posdb.GetDb().Get(epochId, rbJournalKey)
+	if err != nil || len(b) == 0 {
+		return j
+	}
+
+	var data RbJournal
+	err = rlp.DecodeBytes(b, &data)
+	if err != nil {
+		log.SyslogErr("random beacon load journal fail", "err", err)
+		return j
+	}
+
+	if data.Version != RbJournalVersion || data.EpochId != epochId {
+		log.SyslogWarning("random beacon drop journal", "version", data.Version, "epochId", data.EpochId)
+		return j
+	}
+
+	j.data = data
+	return j
+}
+
+func (j *rbJournal) store() error {
+	b, err := rlp.EncodeToBytes(&j.data)
+	if err != nil {
+		log.SyslogErr("random beacon store journal fail", "err", err)
+		return err
+	}
+
+	_, err = posdb.GetDb().Put(j.data.EpochId, rbJournalKey, b)
+	if err != nil {
+		log.SyslogErr("random beacon store journal fail", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+func (j *rbJournal) entry(stage int, proposerId uint32) *RbJournalEntry {
+	for i := range j.data.Entries {
+		e := &j.data.Entries[i]
+		if e.Stage == uint64(stage) && e.ProposerId == proposerId {
+			return e
+		}
+	}
+	return nil
+}
+
+func (j *rbJournal) status(stage int, proposerId uint32) uint64 {
+	if j == nil {
+		return RbTaskNone
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if e := j.entry(stage, proposerId); e != nil {
+		return e.Status
+	}
+	return RbTaskNone
+}
+
+// update sets the status of a task and writes the journal through. A zero txHash
+// keeps the recorded one.
+func (j *rbJournal) update(stage int, proposerId uint32, status uint64, txHash common.Hash) {
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	e := j.entry(stage, proposerId)
+	if e == nil {
+		j.data.Entries = append(j.data.Entries, RbJournalEntry{ProposerId: proposerId, Stage: uint64(stage)})
+		e = &j.data.Entries[len(j.data.Entries)-1]
+	}
+	e.Status = status
+	if txHash != (common.Hash{}) {
+		e.TxHash = txHash
+	}
+	e.Time = uint64(time.Now().Unix())
+
+	j.store()
+}
+
+// entries returns a copy of the journal entries.
+func (j *rbJournal) entries() []RbJournalEntry {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ret := make([]RbJournalEntry, len(j.data.Entries))
+	copy(ret, j.data.Entries)
+	return ret
+}
+
+// isOnChain reports whether the payload of the task is in the state.
+func (rb *RandomBeacon) isOnChain(stage int, proposerId uint32) bool {
+	if rb.statedb == nil {
+		return false
+	}
+
+	switch stage {
+	case vm.RbDkg1Stage:
+		cji, err := rb.getCji(rb.statedb, rb.epochId, proposerId)
+		return err == nil && len(cji) != 0
+	case vm.RbDkg2Stage:
+		return vm.IsJoinDKG2(rb.statedb, rb.epochId, proposerId)
+	case vm.RbSignStage:
+		sig, err := vm.GetSig(rb.statedb, rb.epochId, proposerId)
+		return err == nil && sig != nil
+	}
+	return false
+}
+
+// reconcileJournal marks the sent tasks found in the state as included. After a
+// restart the tx pool may have lost the sent transactions, so the sent tasks
+// that aren't included are set back to generated to be sent again.
+func (rb *RandomBeacon) reconcileJournal(restart bool) {
+	for _, e := range rb.journal.entries() {
+		if e.Status != RbTaskSent && !(restart && e.Status == RbTaskGenerated) {
+			continue
+		}
+
+		stage := int(e.Stage)
+		if rb.isOnChain(stage, e.ProposerId) {
+			rb.journal.update(stage, e.ProposerId, RbTaskIncluded, common.Hash{})
+		} else if restart && e.Status == RbTaskSent {
+			log.SyslogInfo("random beacon resend lost tx", "epochId", rb.epochId, "stage", stage, "proposerId", e.ProposerId, "txHash", e.TxHash.String())
+			rb.journal.update(stage, e.ProposerId, RbTaskGenerated, common.Hash{})
+		}
+	}
+}
+
+// isTaskSent reports whether the task needn't be sent again.
+func (rb *RandomBeacon) isTaskSent(stage int, proposerId uint32) bool {
+	if rb.journal.status(stage, proposerId) >= RbTaskSent {
+		return true
+	}
+
+	if rb.isOnChain(stage, proposerId) {
+		rb.journal.update(stage, proposerId, RbTaskIncluded, common.Hash{})
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,83 @@
+package randombeacon
+
+import (
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core/state"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+func TestRbJournal_storeAndLoad(t *testing.T) {
+	epochId := uint64(2000)
+	txHash := common.HexToHash("0x1234")
+
+	j := newRbJournal(epochId)
+	j.update(vm.RbDkg1Stage, 3, RbTaskGenerated, common.Hash{})
+	j.update(vm.RbDkg1Stage, 3, RbTaskSent, txHash)
+	j.update(vm.RbSignStage, 4, RbTaskGenerated, common.Hash{})
+
+	loaded := loadRbJournal(epochId)
+	if loaded.status(vm.RbDkg1Stage, 3) != RbTaskSent {
+		t.Error("invalid dkg1 status", loaded.status(vm.RbDkg1Stage, 3))
+	}
+	if loaded.status(vm.RbSignStage, 4) != RbTaskGenerated {
+		t.Error("invalid sig status", loaded.status(vm.RbSignStage, 4))
+	}
+	if loaded.status(vm.RbDkg2Stage, 3) != RbTaskNone {
+		t.Error("invalid dkg2 status", loaded.status(vm.RbDkg2Stage, 3))
+	}
+	if entries := loaded.entries(); len(entries) != 2 || entries[0].TxHash != txHash {
+		t.Error("invalid journal entries", entries)
+	}
+
+	// journals of another version are dropped
+	old := RbJournal{Version: RbJournalVersion + 1, EpochId: epochId, Entries: loaded.entries()}
+	b, _ := rlp.EncodeToBytes(&old)
+	posdb.GetDb().Put(epochId, rbJournalKey, b)
+	if len(loadRbJournal(epochId).entries()) != 0 {
+		t.Error("journal of another version should be dropped")
+	}
+}
+
+func TestRandomBeacon_reconcileJournal(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	rb := RandomBeacon{}
+	rb.epochId = 2001
+	rb.statedb = statedb
+	rb.getCji = func(db vm.StateDB, epochId uint64, proposerId uint32) ([]*bn256.G2, error) {
+		if proposerId == 0 {
+			return []*bn256.G2{new(bn256.G2)}, nil
+		}
+		return nil, nil
+	}
+
+	rb.journal = newRbJournal(rb.epochId)
+	rb.journal.update(vm.RbDkg1Stage, 0, RbTaskSent, common.Hash{})
+	rb.journal.update(vm.RbDkg1Stage, 1, RbTaskSent, common.Hash{})
+
+	rb.reconcileJournal(false)
+	if rb.journal.status(vm.RbDkg1Stage, 0) != RbTaskIncluded {
+		t.Error("included dkg1 should be marked")
+	}
+	if rb.journal.status(vm.RbDkg1Stage, 1) != RbTaskSent {
+		t.Error("pending dkg1 should stay sent")
+	}
+
+	rb.reconcileJournal(true)
+	if rb.journal.status(vm.RbDkg1Stage, 1) != RbTaskGenerated {
+		t.Error("lost dkg1 should be resent after restart")
+	}
+	if rb.isTaskSent(vm.RbDkg1Stage, 1) {
+		t.Error("lost dkg1 should not be treated as sent")
+	}
+	if !rb.isTaskSent(vm.RbDkg1Stage, 0) {
+		t.Error("included dkg1 should be treated as sent")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/wanchain/go-wanchain/crypto/bn256/cloudflare"
 	"github.com/wanchain/go-wanchain/pos/epochLeader"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/possigner"
 	"github.com/wanchain/go-wanchain/pos/util"
 	"github.com/wanchain/go-wanchain/rlp"
 	"github.com/wanchain/go-wanchain/rpc"
@@ -121,6 +122,7 @@ type RandomBeacon struct {
 	epochId      uint64
 	polys        PolyMap
 	taskTags     TaskTags
+	journal      *rbJournal
 	proposerPks  []bn256.G1
 	myPropserIds []uint32
 
@@ -259,6 +261,10 @@ func (rb *RandomBeacon) updateEpochId(epochId uint64) {
 
 	if oldEpochId == maxUint64 {
 		rb.loadPolys()
+		rb.journal = loadRbJournal(epochId)
+		rb.reconcileJournal(true)
+	} else {
+		rb.journal = newRbJournal(epochId)
 	}
 }
 
@@ -279,6 +285,8 @@ func (rb *RandomBeacon) doLoop(statedb vm.StateDB, rc *rpc.Client, epochId uint6
 
 	if rb.epochId == maxUint64 || rb.epochId < epochId {
 		rb.updateEpochId(epochId)
+	} else {
+		rb.reconcileJournal(false)
 	}
 
 	// rb.epochId == epochId
@@ -387,7 +395,10 @@ func (rb *RandomBeacon) getMyRBProposerId(epochId uint64) []uint32 {
 	}
 
 	log.SyslogInfo("get my RBP id", "RBP group pk", pks)
-	selfPk := posconfig.Cfg().GetMinerBn256PK()
+	var selfPk *bn256.G1
+	if signer, err := possigner.ForKey(posconfig.Cfg().MinerKey); err == nil {
+		selfPk = signer.Bn256PublicKey()
+	}
 	if selfPk == nil {
 		log.SyslogInfo("get my RBP id, can't get miner bn256 pk")
 		return nil
@@ -411,7 +422,7 @@ func (rb *RandomBeacon) doDKG1s() error {
 			continue
 		}
 
-		if rb.polys[ppId].poly != nil && rb.polys[ppId].s != nil {
+		if rb.isTaskSent(vm.RbDkg1Stage, ppId) {
 			rb.taskTags[i] = true
 			continue
 		}
@@ -419,7 +430,6 @@ func (rb *RandomBeacon) doDKG1s() error {
 		err := rb.doDKG1(ppId)
 		if err == nil {
 			rb.taskTags[i] = true
-			rb.storePolys()
 		} else {
 			// try the best to send every tx,
 			// prevent that one error stop all left task.
@@ -438,6 +448,13 @@ func (rb *RandomBeacon) doDKG1(proposerId uint32) error {
 		return err
 	}
 
+	// the polynomial must survive a restart before the commit is sent, dkg2 needs it
+	err = rb.storePolys()
+	if err != nil {
+		return err
+	}
+	rb.journal.update(vm.RbDkg1Stage, proposerId, RbTaskGenerated, common.Hash{})
+
 	return rb.sendDKG1(txPayload)
 }
 
@@ -453,25 +470,34 @@ func (rb *RandomBeacon) generateDKG1(proposerId uint32) (*vm.RbDKG1FlatTxPayload
 
 	sshare := make([]big.Int, nr)
 
-	// fi(x)
-	s, err := rand.Int(rand.Reader, bn256.Order)
-	if err != nil {
-		log.SyslogErr("dkg1, get rand fail", "err", err)
-		return nil, err
-	}
+	// fi(x), a polynomial kept from before a restart is reused, so that the resent
+	// commit matches the encrypt shares of dkg2
+	poly := rb.polys[proposerId].poly
+	isNew := poly == nil || rb.polys[proposerId].s == nil
+	if isNew {
+		s, err := rand.Int(rand.Reader, bn256.Order)
+		if err != nil {
+			log.SyslogErr("dkg1, get rand fail", "err", err)
+			return nil, err
+		}
 
-	poly, err:= rbselection.RandPoly(int(posconfig.Cfg().PolymDegree), *s)
-	if err != nil {
-		log.SyslogErr("dkg1, get rand poly fail", "err", err)
-		return nil, err
+		poly, err = rbselection.RandPoly(int(posconfig.Cfg().PolymDegree), *s)
+		if err != nil {
+			log.SyslogErr("dkg1, get rand poly fail", "err", err)
+			return nil, err
+		}
+
+		rb.polys[proposerId] = PolyInfo{poly, s}
 	}
 
-	rb.polys[proposerId] = PolyInfo{poly, s}
+	var err error
 	for i := 0; i < nr; i++ {
 		// share for i is fi(x) evaluation result on x[i]
 		sshare[i], err = rbselection.EvaluatePoly(poly, &x[i], int(posconfig.Cfg().PolymDegree))
 		if err != nil {
-			delete(rb.polys, proposerId)
+			if isNew {
+				delete(rb.polys, proposerId)
+			}
 			log.SyslogErr("dkg1, evaluate poly fail", "err", err)
 			return nil, err
 		}
@@ -501,6 +527,11 @@ func (rb *RandomBeacon) doDKG2s() error {
 			continue
 		}
 
+		if rb.isTaskSent(vm.RbDkg2Stage, ppId) {
+			rb.taskTags[i] = true
+			continue
+		}
+
 		err := rb.doDKG2(ppId)
 		if err == nil || err == errNoDKG1Data {
 			rb.taskTags[i] = true
@@ -591,6 +622,11 @@ func (rb *RandomBeacon) doSIGs() error {
 			continue
 		}
 
+		if rb.isTaskSent(vm.RbSignStage, id) {
+			rb.taskTags[i] = true
+			continue
+		}
+
 		err := rb.doSIG(id)
 		if err == nil || err == errInsufficient {
 			rb.taskTags[i] = true
@@ -616,7 +652,12 @@ func (rb *RandomBeacon) doSIG(proposerId uint32) error {
 }
 
 func (rb *RandomBeacon) generateSIG(proposerId uint32) (*vm.RbSIGTxPayload, error) {
-	prikey := posconfig.Cfg().GetMinerBn256SK()
+	signer, err := possigner.ForKey(posconfig.Cfg().MinerKey)
+	if err != nil {
+		log.SyslogErr("generate sig fail", "err", err.Error())
+		return nil, err
+	}
+
 	datas := make([]RbEnsDataCollector, 0)
 
 	for id, pk := range rb.proposerPks {
@@ -636,17 +677,9 @@ func (rb *RandomBeacon) generateSIG(proposerId uint32) (*vm.RbSIGTxPayload, erro
 
 	// Compute Group Secret Key Share
 	// Random proposers get information from the blockchain and compute its group secret share.
-
-	//set zero
-	gskshare := new(bn256.G1).ScalarBaseMult(big.NewInt(int64(0)))
-
-	// sk^-1
-	skinver := new(big.Int).ModInverse(prikey, bn256.Order)
+	ens := make([]*bn256.G1, dkgCount)
 	for i := 0; i < dkgCount; i++ {
-		temp := new(bn256.G1).ScalarMult(datas[i].ens[proposerId], skinver)
-
-		// gskshare[i] = (sk^-1)*(enshare[1][i]+...+enshare[Nr][i])
-		gskshare.Add(gskshare, temp)
+		ens[i] = datas[i].ens[proposerId]
 	}
 
 	// Signing Stage
@@ -659,7 +692,12 @@ func (rb *RandomBeacon) generateSIG(proposerId uint32) (*vm.RbSIGTxPayload, erro
 	m := new(big.Int).SetBytes(mBuf)
 
 	// Compute signature share
-	gsigshare := new(bn256.G1).ScalarMult(gskshare, m)
+	// gsigshare[i] = (sk^-1)*(enshare[1][i]+...+enshare[Nr][i])*m, by the signer holding sk
+	gsigshare, err := signer.RbSignShare(ens, m)
+	if err != nil {
+		log.SyslogErr("generate sig fail", "err", err.Error())
+		return nil, err
+	}
 	return &vm.RbSIGTxPayload{EpochId:rb.epochId, ProposerId:proposerId, GSignShare:gsigshare}, nil
 }
 
@@ -670,7 +708,7 @@ func (rb *RandomBeacon) sendDKG1(payloadObj *vm.RbDKG1FlatTxPayload) error {
 		return err
 	}
 
-	return rb.doSendRBTx(payload)
+	return rb.doSendRBTx(vm.RbDkg1Stage, payloadObj.ProposerId, payload)
 }
 
 func (rb *RandomBeacon) sendDKG2(payloadObj *vm.RbDKG2FlatTxPayload) error {
@@ -680,7 +718,7 @@ func (rb *RandomBeacon) sendDKG2(payloadObj *vm.RbDKG2FlatTxPayload) error {
 		return err
 	}
 
-	return rb.doSendRBTx(payload)
+	return rb.doSendRBTx(vm.RbDkg2Stage, payloadObj.ProposerId, payload)
 }
 
 func (rb *RandomBeacon) sendSIG(payloadObj *vm.RbSIGTxPayload) error {
@@ -690,13 +728,13 @@ func (rb *RandomBeacon) sendSIG(payloadObj *vm.RbSIGTxPayload) error {
 		return err
 	}
 
-	return rb.doSendRBTx(payload)
+	return rb.doSendRBTx(vm.RbSignStage, payloadObj.ProposerId, payload)
 }
 
-func (rb *RandomBeacon) doSendRBTx(payload []byte) error {
+func (rb *RandomBeacon) doSendRBTx(stage int, proposerId uint32, payload []byte) error {
 	to := vm.GetRBAddress()
 	data := hexutil.Bytes(payload)
-	gas := core.IntrinsicGas(data, &to, true)
+	gas := core.IntrinsicGas(data, &to, true, rb.isIstanbul())
 
 	arg := map[string]interface{}{}
 	arg["from"] = rb.getTxFrom()
@@ -708,7 +746,17 @@ func (rb *RandomBeacon) doSendRBTx(payload []byte) error {
 
 
 	log.SyslogInfo("do send rb tx", "payload len", len(payload))
-	go util.SendPosTx(rb.rpcClient, arg)
+	journal := rb.journal
+	journal.update(stage, proposerId, RbTaskSent, common.Hash{})
+	go func() {
+		txHash, err := util.SendPosTx(rb.rpcClient, arg)
+		if err != nil {
+			// resend it after a restart
+			journal.update(stage, proposerId, RbTaskGenerated, common.Hash{})
+			return
+		}
+		journal.update(stage, proposerId, RbTaskSent, txHash)
+	}()
 	return nil
 }
 
@@ -716,6 +764,14 @@ func (rb *RandomBeacon) getTxFrom() common.Address {
 	return posconfig.Cfg().GetMinerAddr()
 }
 
+// isIstanbul returns whether the next block, which includes the sent
+// transactions, is past the Istanbul fork.
+func (rb *RandomBeacon) isIstanbul() bool {
+	bc := rb.epocher.GetBlkChain()
+	next := new(big.Int).Add(bc.CurrentBlock().Number(), big.NewInt(1))
+	return bc.Config().IsIstanbul(next)
+}
+
 func (rb *RandomBeacon) storePolys() error {
 	if len(rb.polys) == 0 {
 		return nil
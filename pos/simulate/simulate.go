@@ -0,0 +1,204 @@
+// Package simulate runs the pos leader selection offline, to estimate how often a
+// staker is selected for a given staker set and random beacon value.
+package simulate
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/pos/epochLeader"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/uleaderselection"
+)
+
+var (
+	ErrNoStaker     = errors.New("no staker can be selected in the epoch")
+	ErrInvalidKey   = errors.New("invalid staker public key")
+	ErrInvalidInput = errors.New("invalid staker")
+	ErrTooManyWhite = errors.New("more white listed epoch leaders than epoch leaders")
+)
+
+// Staker is one entry of a JSON staker list. Amount and Partners are in wei.
+type Staker struct {
+	Address    common.Address `json:"address"`
+	PubSec256  hexutil.Bytes  `json:"pubSec256"`
+	PubBn256   hexutil.Bytes  `json:"pubBn256"`
+	Amount     *hexutil.Big   `json:"amount"`
+	LockEpochs uint64         `json:"lockEpochs"`
+	FeeRate    uint64         `json:"feeRate"`
+	Delegated  *hexutil.Big   `json:"delegated"`
+}
+
+// ToStakerInfo converts the JSON staker to the staking contract storage format,
+// as if it joined before epochID. The delegated amount is counted as one client.
+func (s *Staker) ToStakerInfo(epochID uint64) (vm.StakerInfo, error) {
+	if s.Amount == nil || len(s.PubSec256) == 0 {
+		return vm.StakerInfo{}, ErrInvalidInput
+	}
+	pub := crypto.ToECDSAPub(s.PubSec256)
+	if pub == nil {
+		return vm.StakerInfo{}, ErrInvalidKey
+	}
+	if s.Address == (common.Address{}) {
+		s.Address = crypto.PubkeyToAddress(*pub)
+	}
+
+	weight := big.NewInt(int64(vm.CalLocktimeWeight(s.LockEpochs)))
+	info := vm.StakerInfo{
+		Address:        s.Address,
+		PubSec256:      s.PubSec256,
+		PubBn256:       s.PubBn256,
+		Amount:         new(big.Int).Set(s.Amount.ToInt()),
+		StakeAmount:    new(big.Int).Mul(s.Amount.ToInt(), weight),
+		LockEpochs:     s.LockEpochs,
+		NextLockEpochs: s.LockEpochs,
+		From:           s.Address,
+		StakingEpoch:   epochID,
+		FeeRate:        s.FeeRate,
+	}
+	if s.Delegated != nil && s.Delegated.ToInt().Sign() > 0 {
+		info.Clients = append(info.Clients, vm.ClientInfo{
+			Amount:      new(big.Int).Set(s.Delegated.ToInt()),
+			StakeAmount: new(big.Int).Mul(s.Delegated.ToInt(), big.NewInt(int64(vm.CalLocktimeWeight(vm.PSMinEpochNum)))),
+		})
+	}
+	return info, nil
+}
+
+// StakerResult is the selection result of one staker.
+type StakerResult struct {
+	Address      common.Address `json:"address"`
+	Probability  *hexutil.Big   `json:"probability"`
+	Share        float64        `json:"share"`
+	EpochLeader  int            `json:"epochLeader"`
+	RBProposer   int            `json:"rbProposer"`
+	SlotLeader   int            `json:"slotLeader"`
+	ExpectedSlot float64        `json:"expectedSlot"`
+}
+
+// Schedule is the simulated leader schedule of an epoch.
+type Schedule struct {
+	EpochID      uint64           `json:"epochId"`
+	R            *hexutil.Big     `json:"r"`
+	Stakers      []StakerResult   `json:"stakers"`
+	EpochLeaders []common.Address `json:"epochLeaders"`
+	RBProposers  []common.Address `json:"rbProposers"`
+	SlotLeaders  []common.Address `json:"slotLeaders"`
+}
+
+// Simulate selects the epoch leaders, random beacon proposers and slot leaders of
+// epochID from stakers with the random beacon value r, the same way a node does.
+// white are the public keys of the white listed epoch leaders of the epoch, they
+// take their seats before the stakers are selected and close the epoch leaders.
+//
+// The slot leader sequence depends on the secret message array built by the
+// epoch leaders, which can't be known in advance. It is derived from the epoch
+// leader keys the same way the genesis one is, so the sequence is a sample with
+// the right distribution rather than the one the chain will produce.
+func Simulate(stakers []vm.StakerInfo, white [][]byte, r *big.Int, epochID uint64) (*Schedule, error) {
+	if r == nil {
+		return nil, errors.New("random beacon value is required")
+	}
+	if len(white) > posconfig.EpochLeaderCount {
+		return nil, ErrTooManyWhite
+	}
+	selected := posconfig.EpochLeaderCount - len(white)
+	ps := epochLeader.BuildProbabilityArray(stakers, epochID)
+	if len(ps) == 0 {
+		return nil, ErrNoStaker
+	}
+
+	results := make([]StakerResult, len(ps))
+	index := make(map[common.Address]int)
+	total := ps[len(ps)-1].Probabilities
+	prev := big.NewInt(0)
+	for i := range ps {
+		addr, err := proposerAddress(&ps[i])
+		if err != nil {
+			return nil, err
+		}
+		p := new(big.Int).Sub(ps[i].Probabilities, prev)
+		prev = ps[i].Probabilities
+		share, _ := new(big.Rat).SetFrac(p, total).Float64()
+
+		results[i] = StakerResult{
+			Address:      addr,
+			Probability:  (*hexutil.Big)(p),
+			Share:        share,
+			ExpectedSlot: share * float64(posconfig.SlotCount*selected) / float64(posconfig.EpochLeaderCount),
+		}
+		index[addr] = i
+	}
+
+	schedule := &Schedule{
+		EpochID: epochID,
+		R:       (*hexutil.Big)(r),
+		Stakers: results,
+	}
+
+	pks := make([]*ecdsa.PublicKey, 0, posconfig.EpochLeaderCount)
+	if selected > 0 {
+		epochLeaders := epochLeader.SelectLeaders(r.Bytes(), 0, ps, selected)
+		for i := range epochLeaders {
+			addr, _ := proposerAddress(&epochLeaders[i])
+			schedule.EpochLeaders = append(schedule.EpochLeaders, addr)
+			results[index[addr]].EpochLeader++
+			pks = append(pks, crypto.ToECDSAPub(epochLeaders[i].PubSec256))
+		}
+	}
+	for i := range white {
+		pk := crypto.ToECDSAPub(white[i])
+		if pk == nil {
+			return nil, ErrInvalidKey
+		}
+		schedule.EpochLeaders = append(schedule.EpochLeaders, crypto.PubkeyToAddress(*pk))
+		pks = append(pks, pk)
+	}
+
+	rbProposers := epochLeader.SelectLeaders(r.Bytes(), 1, ps, posconfig.RandomProperCount)
+	for i := range rbProposers {
+		addr, _ := proposerAddress(&rbProposers[i])
+		schedule.RBProposers = append(schedule.RBProposers, addr)
+		results[index[addr]].RBProposer++
+	}
+
+	_, _, slotIndexes, err := uleaderselection.GenerateSlotLeaderSeqAndIndex(buildSMA(pks), pks, r.Bytes(), posconfig.SlotCount, epochID)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range slotIndexes {
+		addr := schedule.EpochLeaders[idx]
+		schedule.SlotLeaders = append(schedule.SlotLeaders, addr)
+		if idx < uint64(selected) {
+			results[index[addr]].SlotLeader++
+		}
+	}
+	return schedule, nil
+}
+
+// buildSMA derives one secret message piece alpha*G from each epoch leader, with
+// alpha = hash(pk), like the genesis secret message array.
+func buildSMA(pks []*ecdsa.PublicKey) []*ecdsa.PublicKey {
+	sma := make([]*ecdsa.PublicKey, len(pks))
+	for i, pk := range pks {
+		alpha := new(big.Int).SetBytes(crypto.Keccak256(crypto.FromECDSAPub(pk)))
+		piece := new(ecdsa.PublicKey)
+		piece.Curve = crypto.S256()
+		piece.X, piece.Y = crypto.S256().ScalarBaseMult(alpha.Bytes())
+		sma[i] = piece
+	}
+	return sma
+}
+
+func proposerAddress(p *epochLeader.Proposer) (common.Address, error) {
+	pub := crypto.ToECDSAPub(p.PubSec256)
+	if pub == nil {
+		return common.Address{}, ErrInvalidKey
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,129 @@
+package simulate
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/pos/posconfig"
+)
+
+func testStakers(t *testing.T, count int) []vm.StakerInfo {
+	stakers := make([]vm.StakerInfo, 0, count)
+	for i := 0; i < count; i++ {
+		key, _ := crypto.GenerateKey()
+		amount := new(big.Int).Mul(big.NewInt(int64(50000*(i+1))), big.NewInt(1e18))
+		s := &Staker{
+			PubSec256:  crypto.FromECDSAPub(&key.PublicKey),
+			Amount:     (*hexutil.Big)(amount),
+			LockEpochs: 30,
+			FeeRate:    1000,
+		}
+		info, err := s.ToStakerInfo(100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stakers = append(stakers, info)
+	}
+	return stakers
+}
+
+func TestSimulate(t *testing.T) {
+	stakers := testStakers(t, 5)
+	r := big.NewInt(123456789)
+
+	schedule, err := Simulate(stakers, nil, r, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedule.Stakers) != 5 {
+		t.Fatal("wrong staker count", len(schedule.Stakers))
+	}
+	if len(schedule.EpochLeaders) != posconfig.EpochLeaderCount ||
+		len(schedule.RBProposers) != posconfig.RandomProperCount ||
+		len(schedule.SlotLeaders) != posconfig.SlotCount {
+		t.Fatal("wrong schedule length")
+	}
+
+	el, rb, sl := 0, 0, 0
+	share := 0.0
+	for _, s := range schedule.Stakers {
+		el += s.EpochLeader
+		rb += s.RBProposer
+		sl += s.SlotLeader
+		share += s.Share
+	}
+	if el != posconfig.EpochLeaderCount || rb != posconfig.RandomProperCount || sl != posconfig.SlotCount {
+		t.Fatal("selection counts mismatch", el, rb, sl)
+	}
+	if share < 0.999 || share > 1.001 {
+		t.Fatal("shares should sum to 1", share)
+	}
+
+	again, err := Simulate(stakers, nil, r, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range schedule.SlotLeaders {
+		if schedule.SlotLeaders[i] != again.SlotLeaders[i] {
+			t.Fatal("simulation is not deterministic")
+		}
+	}
+}
+
+func TestSimulateNoStaker(t *testing.T) {
+	if _, err := Simulate(nil, nil, big.NewInt(1), 100); err != ErrNoStaker {
+		t.Fatal("expect ErrNoStaker", err)
+	}
+
+	// not enough to be a validator accepting delegation
+	s := &Staker{
+		PubSec256:  testStakers(t, 1)[0].PubSec256,
+		Amount:     (*hexutil.Big)(big.NewInt(1)),
+		LockEpochs: 30,
+	}
+	info, err := s.ToStakerInfo(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Simulate([]vm.StakerInfo{info}, nil, big.NewInt(1), 100); err != ErrNoStaker {
+		t.Fatal("expect ErrNoStaker", err)
+	}
+}
+
+func TestSimulateWhiteList(t *testing.T) {
+	stakers := testStakers(t, 5)
+	white := make([][]byte, 3)
+	for i := range white {
+		key, _ := crypto.GenerateKey()
+		white[i] = crypto.FromECDSAPub(&key.PublicKey)
+	}
+
+	schedule, err := Simulate(stakers, white, big.NewInt(123456789), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedule.EpochLeaders) != posconfig.EpochLeaderCount {
+		t.Fatal("wrong epoch leader count", len(schedule.EpochLeaders))
+	}
+	for i := range white {
+		pk := crypto.ToECDSAPub(white[i])
+		if schedule.EpochLeaders[posconfig.EpochLeaderCount-len(white)+i] != crypto.PubkeyToAddress(*pk) {
+			t.Fatal("white listed leader not at the end of the epoch leaders", i)
+		}
+	}
+
+	el := 0
+	for _, s := range schedule.Stakers {
+		el += s.EpochLeader
+	}
+	if el != posconfig.EpochLeaderCount-len(white) {
+		t.Fatal("stakers should fill the seats left by the white list", el)
+	}
+
+	if _, err := Simulate(stakers, make([][]byte, posconfig.EpochLeaderCount+1), big.NewInt(1), 100); err != ErrTooManyWhite {
+		t.Fatal("expect ErrTooManyWhite", err)
+	}
+}
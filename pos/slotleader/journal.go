@@ -0,0 +1,219 @@
+package slotleader
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/common/hexutil"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+	"github.com/wanchain/go-wanchain/pos/util/convert"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+const (
+	slsJournalKey = "slsJournal"
+
+	// SlsJournalVersion is the version of the stored journal, journals of other
+	// versions are ignored.
+	SlsJournalVersion = 2
+)
+
+// stage of a journal entry
+const (
+	SlsJournalStage1 = 1
+	SlsJournalStage2 = 2
+)
+
+// status of a journal entry
+const (
+	SlsTaskGenerated = 1 // payload generated, tx not sent
+	SlsTaskSent      = 2 // tx accepted by the tx pool
+	SlsTaskFailed    = 3 // tx rejected by the tx pool
+)
+
+var journalMu sync.Mutex
+
+// SlsJournalEntry is the stage 1 or stage 2 work of one epoch leader index.
+type SlsJournalEntry struct {
+	Stage     uint64
+	SelfIndex uint64
+	Status    uint64
+	Payload   []byte
+	TxHash    common.Hash
+	Time      uint64
+}
+
+// SlsJournal records the slot leader selection work of the local node in one
+// epoch, so that a restarted node keeps the alpha it committed to. The alpha
+// itself is only kept in the "alpha" entry of posdb.
+type SlsJournal struct {
+	Version uint64
+	EpochID uint64
+	Entries []SlsJournalEntry
+}
+
+// loadSlsJournal returns the stored journal of the epoch, or an empty one.
+// journalMu must be held.
+func loadSlsJournal(epochID uint64) *SlsJournal {
+	j := &SlsJournal{Version: SlsJournalVersion, EpochID: epochID}
+	buf, err := posdb.GetDb().Get(epochID, slsJournalKey)
+	if err != nil || len(buf) == 0 {
+		return j
+	}
+
+	var stored SlsJournal
+	if err := rlp.DecodeBytes(buf, &stored); err != nil {
+		log.SyslogErr("SLS load journal fail", "epochID", epochID, "err", err.Error())
+		return j
+	}
+	if stored.Version != SlsJournalVersion || stored.EpochID != epochID {
+		log.SyslogWarning("SLS ignore journal", "epochID", epochID, "version", stored.Version)
+		return j
+	}
+	return &stored
+}
+
+func (j *SlsJournal) store() error {
+	buf, err := rlp.EncodeToBytes(j)
+	if err != nil {
+		return err
+	}
+	_, err = posdb.GetDb().Put(j.EpochID, slsJournalKey, buf)
+	return err
+}
+
+func (j *SlsJournal) get(stage uint64, selfIndex uint64) *SlsJournalEntry {
+	for i := range j.Entries {
+		if j.Entries[i].Stage == stage && j.Entries[i].SelfIndex == selfIndex {
+			return &j.Entries[i]
+		}
+	}
+	return nil
+}
+
+// updateJournal applies fn to the entry of the stage and index and stores the
+// journal of the epoch.
+func updateJournal(epochID uint64, stage uint64, selfIndex uint64, fn func(e *SlsJournalEntry)) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	j := loadSlsJournal(epochID)
+	e := j.get(stage, selfIndex)
+	if e == nil {
+		j.Entries = append(j.Entries, SlsJournalEntry{Stage: stage, SelfIndex: selfIndex})
+		e = &j.Entries[len(j.Entries)-1]
+	}
+	fn(e)
+	e.Time = uint64(time.Now().Unix())
+
+	if err := j.store(); err != nil {
+		log.SyslogErr("SLS store journal fail", "epochID", epochID, "err", err.Error())
+	}
+}
+
+func getJournalEntry(epochID uint64, stage uint64, selfIndex uint64) *SlsJournalEntry {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	e := loadSlsJournal(epochID).get(stage, selfIndex)
+	if e == nil {
+		return nil
+	}
+	ret := *e
+	return &ret
+}
+
+// journalAlpha returns the alpha of the stage 1 payload generated for the index,
+// or nil if there is none.
+func (s *SLS) journalAlpha(epochID uint64, selfIndex uint64) *big.Int {
+	if getJournalEntry(epochID, SlsJournalStage1, selfIndex) == nil {
+		return nil
+	}
+	buf, err := posdb.GetDb().GetWithIndex(epochID, selfIndex, "alpha")
+	if err != nil || len(buf) == 0 {
+		return nil
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+// journalGenerated records a generated stage 1 or stage 2 payload.
+func (s *SLS) journalGenerated(epochID uint64, stage uint64, selfIndex uint64, payload []byte) {
+	updateJournal(epochID, stage, selfIndex, func(e *SlsJournalEntry) {
+		if e.Status == SlsTaskSent {
+			return
+		}
+		e.Status = SlsTaskGenerated
+		e.Payload = payload
+	})
+}
+
+// isJournalSent reports whether the tx of the stage and index was sent already.
+func isJournalSent(epochID uint64, stage uint64, selfIndex uint64) bool {
+	e := getJournalEntry(epochID, stage, selfIndex)
+	return e != nil && e.Status == SlsTaskSent
+}
+
+// SlsJournalEntryJson is a journal entry.
+type SlsJournalEntryJson struct {
+	Stage     uint64        `json:"stage"`
+	SelfIndex uint64        `json:"selfIndex"`
+	Status    string        `json:"status"`
+	Payload   hexutil.Bytes `json:"payload"`
+	TxHash    common.Hash   `json:"txHash"`
+	Time      uint64        `json:"time"`
+}
+
+// SlsWorkStatus is the slot leader selection work of the local node in an epoch.
+type SlsWorkStatus struct {
+	EpochID        uint64                `json:"epochId"`
+	WorkingEpochID uint64                `json:"workingEpochId"`
+	WorkStage      string                `json:"workStage"`
+	Entries        []SlsJournalEntryJson `json:"entries"`
+}
+
+var (
+	slsStageNames = map[int]string{
+		slotLeaderSelectionInit:          "init",
+		slotLeaderSelectionStage1:        "stage1",
+		slotLeaderSelectionStage2:        "stage2",
+		slotLeaderSelectionStage3:        "stage3",
+		slotLeaderSelectionStageFinished: "finished",
+	}
+	slsTaskNames = map[uint64]string{
+		SlsTaskGenerated: "generated",
+		SlsTaskSent:      "sent",
+		SlsTaskFailed:    "failed",
+	}
+)
+
+// GetWorkStatus returns the work stage and the journal of the epoch, without the
+// alphas. It doesn't change the stored work stage.
+func (s *SLS) GetWorkStatus(epochID uint64) *SlsWorkStatus {
+	status := &SlsWorkStatus{EpochID: epochID, WorkStage: "none"}
+	if buf, err := posdb.GetDb().Get(0, "slotLeaderCurrentSlotID"); err == nil {
+		status.WorkingEpochID = convert.BytesToUint64(buf)
+	}
+	if buf, err := posdb.GetDb().Get(epochID, "slotLeaderWorkStage"); err == nil {
+		status.WorkStage = slsStageNames[int(new(big.Int).SetBytes(buf).Int64())]
+	}
+
+	journalMu.Lock()
+	j := loadSlsJournal(epochID)
+	journalMu.Unlock()
+
+	status.Entries = make([]SlsJournalEntryJson, 0, len(j.Entries))
+	for _, e := range j.Entries {
+		status.Entries = append(status.Entries, SlsJournalEntryJson{
+			Stage:     e.Stage,
+			SelfIndex: e.SelfIndex,
+			Status:    slsTaskNames[e.Status],
+			Payload:   e.Payload,
+			TxHash:    e.TxHash,
+			Time:      e.Time,
+		})
+	}
+	return status
+}
@@ -0,0 +1,54 @@
+package slotleader
+
+import (
+	"testing"
+
+	"github.com/wanchain/go-wanchain/accounts/keystore"
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/crypto"
+	"github.com/wanchain/go-wanchain/pos/posdb"
+)
+
+func TestJournalReuseAlpha(t *testing.T) {
+	posdb.GetDb().DbInit("test")
+	defer RmDB("test")
+
+	key, _ := crypto.GenerateKey()
+	s := &SLS{key: &keystore.Key{PrivateKey: key}}
+	epochID := uint64(9000)
+
+	payload, err := s.generateCommitment(&key.PublicKey, epochID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alpha, _ := posdb.GetDb().GetWithIndex(epochID, 2, "alpha")
+
+	// a restart before the tx is sent generates the same commitment
+	again, err := s.generateCommitment(&key.PublicKey, epochID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.Bytes2Hex(payload) != common.Bytes2Hex(again) {
+		t.Fatal("commitment should be the same after restart")
+	}
+
+	if journalAlpha := s.journalAlpha(epochID, 2); journalAlpha == nil || common.Bytes2Hex(journalAlpha.Bytes()) != common.Bytes2Hex(alpha) {
+		t.Fatal("alpha of the journal isn't the stored one")
+	}
+	if s.journalAlpha(epochID, 3) != nil {
+		t.Fatal("alpha of an index without commitment")
+	}
+
+	updateJournal(epochID, SlsJournalStage1, 2, func(e *SlsJournalEntry) {
+		e.Status = SlsTaskSent
+		e.TxHash = common.HexToHash("0x01")
+	})
+	if !isJournalSent(epochID, SlsJournalStage1, 2) {
+		t.Fatal("stage1 should be sent")
+	}
+
+	status := s.GetWorkStatus(epochID)
+	if len(status.Entries) != 1 || status.Entries[0].Status != "sent" || status.Entries[0].TxHash != common.HexToHash("0x01") {
+		t.Fatal("wrong work status", status)
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/wanchain/go-wanchain/crypto"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/possigner"
 
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/pos/uleaderselection"
@@ -126,8 +127,8 @@ func (s *SLS) VerifySlotProof(block *types.Block, epochID uint64, slotID uint64,
 	return uleaderselection.VerifySlotLeaderProof(Proof[:], ProofMeg[:], epochLeadersPtrPre[:], rbBytes[:])
 }
 
-func (s *SLS) PackSlotProof(epochID uint64, slotID uint64, prvKey *ecdsa.PrivateKey) ([]byte, error) {
-	proofMeg, proof, err := s.getSlotLeaderProof(prvKey, epochID, slotID)
+func (s *SLS) PackSlotProof(epochID uint64, slotID uint64, signer possigner.Signer) ([]byte, error) {
+	proofMeg, proof, err := s.getSlotLeaderProof(signer, epochID, slotID)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +176,7 @@ func (s *SLS) GetInfoFromHeadExtra(epochID uint64, input []byte) ([]*big.Int, []
 	return proof, proofMeg, nil
 }
 
-func (s *SLS) getSlotLeaderProofByGenesis(PrivateKey *ecdsa.PrivateKey, epochID uint64,
+func (s *SLS) getSlotLeaderProofByGenesis(signer possigner.Signer, epochID uint64,
 	slotID uint64) ([]*ecdsa.PublicKey, []*big.Int, error) {
 
 	//1. SMA PRE
@@ -186,26 +187,26 @@ func (s *SLS) getSlotLeaderProofByGenesis(PrivateKey *ecdsa.PrivateKey, epochID
 	log.Debug("getSlotLeaderProofByGenesis", "epochID", epochID, "slotID", slotID)
 	log.Debug("getSlotLeaderProofByGenesis", "epochID", epochID, "slotID", slotID, "slotLeaderRb",
 		hex.EncodeToString(rbBytes[:]))
-	profMeg, proof, err := uleaderselection.GenerateSlotLeaderProof(PrivateKey, smaPiecesPtr[:],
-		epochLeadersPtrPre[:], rbBytes[:], slotID, epochID)
+	profMeg, proof, err := signer.SlotLeaderProof(smaPiecesPtr[:], epochLeadersPtrPre[:], rbBytes[:],
+		slotID, epochID)
 	return profMeg, proof, err
 }
 
-func (s *SLS) getSlotLeaderProof(PrivateKey *ecdsa.PrivateKey, epochID uint64,
+func (s *SLS) getSlotLeaderProof(signer possigner.Signer, epochID uint64,
 	slotID uint64) ([]*ecdsa.PublicKey, []*big.Int, error) {
 	if epochID <= posconfig.FirstEpochId+2 {
-		return s.getSlotLeaderProofByGenesis(PrivateKey, 0, slotID)
+		return s.getSlotLeaderProofByGenesis(signer, 0, slotID)
 	}
 	epochLeadersPtrPre, isDefault := s.GetPreEpochLeadersPK(epochID)
 	if isDefault {
 		log.Warn("getSlotLeaderProof", "isDefault", isDefault)
-		return s.getSlotLeaderProofByGenesis(PrivateKey, 0, slotID)
+		return s.getSlotLeaderProofByGenesis(signer, 0, slotID)
 	}
 
 	//SMA PRE
 	smaPiecesPtr, isGenesis, _ := s.getSMAPieces(epochID)
 	if isGenesis {
-		return s.getSlotLeaderProofByGenesis(PrivateKey, 0, slotID)
+		return s.getSlotLeaderProofByGenesis(signer, 0, slotID)
 	}
 
 	//RB PRE
@@ -228,8 +229,7 @@ func (s *SLS) getSlotLeaderProof(PrivateKey *ecdsa.PrivateKey, epochID uint64,
 	}
 	log.Debug("getSlotLeaderProof", "epochID", epochID, "slotID", slotID, "smaPiecesHexStr", smaPiecesHexStr)
 
-	profMeg, proof, err := uleaderselection.GenerateSlotLeaderProof(PrivateKey, smaPiecesPtr, epochLeadersPtrPre,
-		rbBytes[:], slotID, epochID)
+	profMeg, proof, err := signer.SlotLeaderProof(smaPiecesPtr, epochLeadersPtrPre, rbBytes[:], slotID, epochID)
 
 	return profMeg, proof, err
 }
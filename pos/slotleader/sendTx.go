@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math/big"
 
+	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/common/hexutil"
 	"github.com/wanchain/go-wanchain/core"
 	"github.com/wanchain/go-wanchain/core/types"
@@ -16,16 +17,18 @@ var (
 	errRCNotReady = errors.New("rc is not ready")
 )
 
-type SendTxFn func(rc *rpc.Client, tx map[string]interface{})
+type SendTxFn func(rc *rpc.Client, tx map[string]interface{}) (common.Hash, error)
 
-func (s *SLS) sendSlotTx(payload []byte, posSender SendTxFn) error {
+// sendSlotTx sends the stage 1 or stage 2 payload of the epoch leader index, and
+// records the result in the journal of the epoch.
+func (s *SLS) sendSlotTx(epochID uint64, stage uint64, selfIndex uint64, payload []byte, posSender SendTxFn) error {
 	if s.rc == nil {
 		return errRCNotReady
 	}
 
 	to := vm.GetSlotLeaderSCAddress()
 	data := hexutil.Bytes(payload)
-	gas := core.IntrinsicGas(data, &to, true)
+	gas := core.IntrinsicGas(data, &to, true, s.isIstanbul())
 
 	arg := map[string]interface{}{}
 	arg["from"] = s.key.Address
@@ -36,6 +39,16 @@ func (s *SLS) sendSlotTx(payload []byte, posSender SendTxFn) error {
 	arg["data"] = data
 	log.Debug("Write data of payload", "length", len(data))
 
-	go posSender(s.rc, arg)
+	go func() {
+		txHash, err := posSender(s.rc, arg)
+		updateJournal(epochID, stage, selfIndex, func(e *SlsJournalEntry) {
+			if err != nil {
+				e.Status = SlsTaskFailed
+				return
+			}
+			e.Status = SlsTaskSent
+			e.TxHash = txHash
+		})
+	}()
 	return nil
 }
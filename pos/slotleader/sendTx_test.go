@@ -1,6 +1,7 @@
 package slotleader
 
 import (
+	"github.com/wanchain/go-wanchain/common"
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
@@ -10,8 +11,8 @@ import (
 //	GetSlotLeaderSelection().Init(nil, &rpc.Client{}, &keystore.Key{})
 //}
 
-func testSender(rc *rpc.Client, tx map[string]interface{}) {
-	return
+func testSender(rc *rpc.Client, tx map[string]interface{}) (common.Hash, error) {
+	return common.Hash{}, nil
 }
 
 //func TestSendStage1Tx(t *testing.T) {
@@ -23,6 +23,7 @@ import (
 	"github.com/wanchain/go-wanchain/log"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 	"github.com/wanchain/go-wanchain/pos/posdb"
+	"github.com/wanchain/go-wanchain/pos/possigner"
 	"github.com/wanchain/go-wanchain/pos/util/convert"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -276,15 +277,18 @@ func (s *SLS) getAlpha(epochID uint64, selfIndex uint64) (*big.Int, error) {
 }
 
 func (s *SLS) getLocalPublicKey() (*ecdsa.PublicKey, error) {
-	if s.key == nil || s.key.PrivateKey == nil {
+	signer, err := s.getSigner()
+	if err != nil || signer.PublicKey() == nil {
 		log.SyslogErr("SLS", "getLocalPublicKey", vm.ErrInvalidLocalPublicKey.Error())
 		return nil, vm.ErrInvalidLocalPublicKey
 	}
-	return &s.key.PrivateKey.PublicKey, nil
+	return signer.PublicKey(), nil
 }
 
-func (s *SLS) getLocalPrivateKey() (*ecdsa.PrivateKey, error) {
-	return s.key.PrivateKey, nil
+// getSigner returns the remote signer if there is one, otherwise a signer of the
+// unlocked key.
+func (s *SLS) getSigner() (possigner.Signer, error) {
+	return possigner.ForKey(s.key)
 }
 
 func (s *SLS) getEpochLeaders(epochID uint64) [][]byte {
@@ -743,10 +747,10 @@ func (s *SLS) collectStagesData(epochID uint64) (err error) {
 	return nil
 }
 
-func (s *SLS) generateSecurityMsg(epochID uint64, PrivateKey *ecdsa.PrivateKey) error {
+func (s *SLS) generateSecurityMsg(epochID uint64, signer possigner.Signer) error {
 	if !s.isLocalPkInCurrentEpochLeaders() {
 		log.Debug("generateSecurityMsg", "input public key",
-			hex.EncodeToString(crypto.FromECDSAPub(&PrivateKey.PublicKey)))
+			hex.EncodeToString(crypto.FromECDSAPub(signer.PublicKey())))
 		return vm.ErrPkNotInCurrentEpochLeadersGroup
 	}
 	// collect data
@@ -765,7 +769,7 @@ func (s *SLS) generateSecurityMsg(epochID uint64, PrivateKey *ecdsa.PrivateKey)
 	smasPtr := make([]*ecdsa.PublicKey, 0)
 	var smasBytes bytes.Buffer
 
-	smasPtr, err = uleaderselection.GenerateSMA(PrivateKey, ArrayPiece)
+	smasPtr, err = signer.GenerateSMA(ArrayPiece)
 	if err != nil {
 		log.Error("generateSecurityMsg:GenerateSMA", "error", err.Error())
 		return err
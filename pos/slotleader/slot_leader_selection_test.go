@@ -17,6 +17,7 @@ import (
 	"github.com/wanchain/go-wanchain/core/vm"
 	"github.com/wanchain/go-wanchain/ethdb"
 	"github.com/wanchain/go-wanchain/pos/posconfig"
+	"github.com/wanchain/go-wanchain/pos/possigner"
 	"github.com/wanchain/go-wanchain/pos/uleaderselection"
 	"github.com/wanchain/go-wanchain/pos/util/convert"
 
@@ -378,16 +379,6 @@ func TestGetLocalPublicKey(t *testing.T) {
 	if !uleaderselection.PublicKeyEqual(keyGot1, &s.key.PrivateKey.PublicKey) {
 		t.Fail()
 	}
-	//getLocalPrivateKey
-	prvKeyGot, err := s.getLocalPrivateKey()
-	if err != nil {
-		t.Fail()
-	}
-
-	if prvKeyGot != key {
-		t.Fail()
-	}
-
 }
 
 func TestGetSlotCreateStatusByEpochID(t *testing.T) {
@@ -829,7 +820,7 @@ func TestGenerateSecurityMsg(t *testing.T) {
 	// build security pieces
 	//pieces,_:= s.buildSecurityPieces(epochID)
 	// create SMA
-	err = s.generateSecurityMsg(epochID, key)
+	err = s.generateSecurityMsg(epochID, possigner.NewLocalSigner(s.key))
 	if err != nil {
 		t.Logf("generate security message error. err:%v \n", err.Error())
 		t.Fail()
@@ -2,6 +2,8 @@ package slotleader
 
 import (
 	"errors"
+	"math/big"
+
 	"github.com/wanchain/go-wanchain/pos/posconfig"
 
 	"github.com/wanchain/go-wanchain/core/state"
@@ -36,3 +38,10 @@ func (s *SLS) getLastSlotIDFromChain() uint64 {
 func (s *SLS) getBlockChainHeight() uint64 {
 	return s.blockChain.CurrentBlock().NumberU64()
 }
+
+// isIstanbul returns whether the next block, which includes the stage
+// transactions, is past the Istanbul fork.
+func (s *SLS) isIstanbul() bool {
+	next := new(big.Int).SetUint64(s.getBlockChainHeight() + 1)
+	return s.blockChain.Config().IsIstanbul(next)
+}
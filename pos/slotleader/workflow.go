@@ -192,7 +192,10 @@ func (s *SLS) Loop(rc *rpc.Client, key *keystore.Key, epochID uint64, slotID uin
 			break
 		}
 
-		err := s.generateSecurityMsg(epochID, s.key.PrivateKey)
+		signer, err := s.getSigner()
+		if err == nil {
+			err = s.generateSecurityMsg(epochID, signer)
+		}
 		if err != nil {
 			log.Warn(err.Error())
 		} else {
@@ -232,12 +235,17 @@ func (s *SLS) startStage1Work() error {
 		workingEpochID := s.getWorkingEpochID()
 
 		for i := 0; i < len(selfPublicKeyIndex); i++ {
+			if isJournalSent(workingEpochID, SlsJournalStage1, selfPublicKeyIndex[i]) {
+				log.Info("stage1 tx sent already", "epochID", workingEpochID, "selfIndex", selfPublicKeyIndex[i])
+				continue
+			}
+
 			data, err := s.generateCommitment(selfPublicKey, workingEpochID, selfPublicKeyIndex[i])
 			if err != nil {
 				log.Error("generateCommitment error", "error", err.Error())
 				continue
 			}
-			err = s.sendSlotTx(data, s.sendTransactionFn)
+			err = s.sendSlotTx(workingEpochID, SlsJournalStage1, selfPublicKeyIndex[i], data, s.sendTransactionFn)
 			if err != nil {
 				log.Error("sendSlotTx error", "error", err.Error())
 				continue
@@ -270,12 +278,18 @@ func (s *SLS) startStage2Work() error {
 	if inEpochLeaders {
 		for i := 0; i < len(selfPublicKeyIndex); i++ {
 			workingEpochID := s.getWorkingEpochID()
+			if isJournalSent(workingEpochID, SlsJournalStage2, selfPublicKeyIndex[i]) {
+				log.Info("stage2 tx sent already", "epochID", workingEpochID, "selfIndex", selfPublicKeyIndex[i])
+				continue
+			}
+
 			data, err := s.buildStage2TxPayload(workingEpochID, uint64(selfPublicKeyIndex[i]))
 			if err != nil {
 				log.Error("buildStage2TxPayload error", "error", err.Error())
 				continue
 			}
-			err = s.sendSlotTx(data, s.sendTransactionFn)
+			s.journalGenerated(workingEpochID, SlsJournalStage2, selfPublicKeyIndex[i], data)
+			err = s.sendSlotTx(workingEpochID, SlsJournalStage2, selfPublicKeyIndex[i], data, s.sendTransactionFn)
 			if err != nil {
 				log.Error("sendSlotTx error", "error", err.Error())
 				continue
@@ -299,9 +313,15 @@ func (s *SLS) generateCommitment(publicKey *ecdsa.PublicKey,
 		return nil, vm.ErrNotOnCurve
 	}
 
-	alpha, err := uleaderselection.RandFieldElement(Rand.Reader)
-	if err != nil {
-		return nil, err
+	// reuse the alpha of a commitment generated before a restart, a different
+	// one would break the stage 2 proof if the first commitment gets included
+	alpha := s.journalAlpha(epochID, selfIndexInEpochLeader)
+	if alpha == nil {
+		var err error
+		alpha, err = uleaderselection.RandFieldElement(Rand.Reader)
+		if err != nil {
+			return nil, err
+		}
 	}
 	//fmt.Println("alpha:", hex.EncodeToString(alpha.Bytes()))
 
@@ -314,6 +334,9 @@ func (s *SLS) generateCommitment(publicKey *ecdsa.PublicKey,
 		vm.GetSlotLeaderScAbiString())
 
 	posdb.GetDb().PutWithIndex(epochID, selfIndexInEpochLeader, "alpha", alpha.Bytes())
+	if err == nil {
+		s.journalGenerated(epochID, SlsJournalStage1, selfIndexInEpochLeader, buffer)
+	}
 
 	log.Debug(fmt.Sprintf("----Put alpha epochID:%d, selfIndex:%d, alpha:%s, mi:%s, pk:%s", epochID,
 		selfIndexInEpochLeader, alpha.String(), hex.EncodeToString(crypto.FromECDSAPub(commitment[1])),
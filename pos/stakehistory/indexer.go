@@ -0,0 +1,270 @@
+package stakehistory
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/log"
+	"github.com/wanchain/go-wanchain/pos/util"
+	"github.com/wanchain/go-wanchain/rlp"
+)
+
+const (
+	// SectionSize is the number of blocks digested in one index section.
+	SectionSize = 1024
+
+	// sectionConfirms is the number of confirmation blocks before a section is indexed.
+	sectionConfirms = 256
+
+	// sectionThrottling is the time to wait between processing two consecutive sections.
+	sectionThrottling = 100 * time.Millisecond
+)
+
+var (
+	validatorPrefix = []byte("v") // validatorPrefix + address + section (uint64 big endian) -> rlp([]StakeEvent)
+	delegatorPrefix = []byte("d") // delegatorPrefix + address + section (uint64 big endian) -> rlp([]StakeEvent)
+	sectionsPrefix  = []byte("s") // sectionsPrefix + role + address -> rlp([]uint64) sections holding entries
+
+	ErrNotInitialized = errors.New("stake history indexer is not running")
+
+	mu      sync.RWMutex
+	indexDb ethdb.Database
+	chainDb ethdb.Database
+)
+
+// StakeEvent is one staking contract call recorded in the history index.
+type StakeEvent struct {
+	Event       string
+	EpochId     uint64
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+	LogIndex    uint64
+	Sender      common.Address
+	Validator   common.Address
+	Value       *big.Int
+	FeeRate     uint64
+	MaxFeeRate  uint64
+	LockEpochs  uint64
+	Renewal     bool
+}
+
+// Indexer implements core.ChainIndexerBackend, collecting the logs of the pos
+// staking contract into a per address timeline.
+type Indexer struct {
+	db      ethdb.Database // chain database to read receipts from
+	table   ethdb.Database // index table to write the timeline into
+	section uint64
+
+	validators map[common.Address][]StakeEvent
+	delegators map[common.Address][]StakeEvent
+}
+
+// NewIndexer returns a chain indexer that builds the staker and delegator
+// history of the canonical chain.
+func NewIndexer(db ethdb.Database) *core.ChainIndexer {
+	table := ethdb.NewTable(db, string(core.StakeHistoryIndexPrefix))
+	backend := &Indexer{
+		db:    db,
+		table: table,
+	}
+
+	mu.Lock()
+	indexDb, chainDb = table, db
+	mu.Unlock()
+
+	return core.NewChainIndexer(db, table, backend, SectionSize, sectionConfirms, sectionThrottling, "stakehistory")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new section.
+func (b *Indexer) Reset(section uint64) {
+	b.section = section
+	b.validators = make(map[common.Address][]StakeEvent)
+	b.delegators = make(map[common.Address][]StakeEvent)
+}
+
+// Process implements core.ChainIndexerBackend, digesting the staking logs of a block.
+func (b *Indexer) Process(header *types.Header) {
+	hash, number := header.Hash(), header.Number.Uint64()
+	if !types.BloomLookup(header.Bloom, vm.WanCscPrecompileAddr) {
+		return
+	}
+	epochId, _ := util.CalEpochSlotID(header.Time.Uint64())
+
+	receipts := core.GetBlockReceipts(b.db, hash, number)
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			if l.Address != vm.WanCscPrecompileAddr {
+				continue
+			}
+			info, err := vm.ParseStakingLog(l)
+			if err != nil {
+				log.Debug("skip staking log", "number", number, "tx", l.TxHash, "err", err)
+				continue
+			}
+			ev := StakeEvent{
+				Event:       info.Event,
+				EpochId:     epochId,
+				BlockNumber: number,
+				BlockHash:   hash,
+				TxHash:      l.TxHash,
+				LogIndex:    uint64(l.Index),
+				Sender:      info.Sender,
+				Validator:   info.Validator,
+				Value:       info.Value,
+				FeeRate:     info.FeeRate,
+				MaxFeeRate:  info.MaxFeeRate,
+				LockEpochs:  info.LockEpochs,
+				Renewal:     info.Renewal,
+			}
+			b.validators[ev.Validator] = append(b.validators[ev.Validator], ev)
+			if isDelegatorEvent(ev.Event) {
+				b.delegators[ev.Sender] = append(b.delegators[ev.Sender], ev)
+			}
+		}
+	}
+}
+
+// Commit implements core.ChainIndexerBackend, writing the section out into the database.
+func (b *Indexer) Commit() error {
+	batch := b.table.NewBatch()
+	if err := b.commitRole(batch, validatorPrefix, b.validators); err != nil {
+		return err
+	}
+	if err := b.commitRole(batch, delegatorPrefix, b.delegators); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (b *Indexer) commitRole(batch ethdb.Batch, role []byte, events map[common.Address][]StakeEvent) error {
+	for addr, evs := range events {
+		enc, err := rlp.EncodeToBytes(evs)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(entryKey(role, addr, b.section), enc); err != nil {
+			return err
+		}
+
+		sections, added := insertSection(readSections(b.table, role, addr), b.section)
+		if added {
+			enc, err = rlp.EncodeToBytes(sections)
+			if err != nil {
+				return err
+			}
+			if err := batch.Put(sectionsKey(role, addr), enc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// insertSection adds section into the sorted list if it is not there yet.
+func insertSection(sections []uint64, section uint64) ([]uint64, bool) {
+	i := sort.Search(len(sections), func(i int) bool { return sections[i] >= section })
+	if i < len(sections) && sections[i] == section {
+		return sections, false
+	}
+	sections = append(sections, 0)
+	copy(sections[i+1:], sections[i:])
+	sections[i] = section
+	return sections, true
+}
+
+func isDelegatorEvent(event string) bool {
+	return event == "delegateIn" || event == "delegateOut" || event == "partnerIn"
+}
+
+func entryKey(role []byte, addr common.Address, section uint64) []byte {
+	key := make([]byte, 0, len(role)+common.AddressLength+8)
+	key = append(key, role...)
+	key = append(key, addr[:]...)
+	return append(key, encodeUint64(section)...)
+}
+
+func sectionsKey(role []byte, addr common.Address) []byte {
+	key := make([]byte, 0, len(sectionsPrefix)+len(role)+common.AddressLength)
+	key = append(key, sectionsPrefix...)
+	key = append(key, role...)
+	return append(key, addr[:]...)
+}
+
+func encodeUint64(n uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, n)
+	return enc
+}
+
+func readSections(db ethdb.Database, role []byte, addr common.Address) []uint64 {
+	data, _ := db.Get(sectionsKey(role, addr))
+	if len(data) == 0 {
+		return nil
+	}
+	var sections []uint64
+	if err := rlp.DecodeBytes(data, &sections); err != nil {
+		log.Error("Invalid stake history sections RLP", "addr", addr, "err", err)
+		return nil
+	}
+	return sections
+}
+
+// readHistory returns the canonical events of addr in the role table whose
+// epoch lies in [fromEpoch, toEpoch].
+func readHistory(table, chain ethdb.Database, role []byte, addr common.Address, fromEpoch, toEpoch uint64) []StakeEvent {
+	history := make([]StakeEvent, 0)
+	for _, section := range readSections(table, role, addr) {
+		data, _ := table.Get(entryKey(role, addr, section))
+		if len(data) == 0 {
+			continue
+		}
+		var evs []StakeEvent
+		if err := rlp.DecodeBytes(data, &evs); err != nil {
+			log.Error("Invalid stake history RLP", "addr", addr, "section", section, "err", err)
+			continue
+		}
+		for _, ev := range evs {
+			if ev.EpochId < fromEpoch || ev.EpochId > toEpoch {
+				continue
+			}
+			// entries left over by a reorg of an already indexed section are skipped
+			if core.GetCanonicalHash(chain, ev.BlockNumber) != ev.BlockHash {
+				continue
+			}
+			history = append(history, ev)
+		}
+	}
+	return history
+}
+
+// GetStakerHistory returns the staking events of the validator addr between
+// fromEpoch and toEpoch (both inclusive), in chain order.
+func GetStakerHistory(addr common.Address, fromEpoch, toEpoch uint64) ([]StakeEvent, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if indexDb == nil {
+		return nil, ErrNotInitialized
+	}
+	return readHistory(indexDb, chainDb, validatorPrefix, addr, fromEpoch, toEpoch), nil
+}
+
+// GetDelegatorHistory returns the delegateIn, delegateOut and partnerIn events
+// sent by addr between fromEpoch and toEpoch (both inclusive), in chain order.
+func GetDelegatorHistory(addr common.Address, fromEpoch, toEpoch uint64) ([]StakeEvent, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if indexDb == nil {
+		return nil, ErrNotInitialized
+	}
+	return readHistory(indexDb, chainDb, delegatorPrefix, addr, fromEpoch, toEpoch), nil
+}
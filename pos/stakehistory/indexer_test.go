@@ -0,0 +1,94 @@
+package stakehistory
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/wanchain/go-wanchain/common"
+	"github.com/wanchain/go-wanchain/core"
+	"github.com/wanchain/go-wanchain/core/types"
+	"github.com/wanchain/go-wanchain/core/vm"
+	"github.com/wanchain/go-wanchain/ethdb"
+	"github.com/wanchain/go-wanchain/params"
+)
+
+var (
+	testSender    = common.HexToAddress("0x2d0e7c0813a51d3bd1d08246af2a8a7a57d8922e")
+	testValidator = common.HexToAddress("0x8b157b3ffead48c8a4cdc6bddbe1c1d170049da4")
+)
+
+func delegateInLog(value *big.Int) *types.Log {
+	return &types.Log{
+		Address: vm.WanCscPrecompileAddr,
+		Topics: []common.Hash{
+			vm.StakingEventId("delegateIn"),
+			common.BytesToHash(testSender.Bytes()),
+			testValidator.Hash(),
+			common.BigToHash(value),
+		},
+	}
+}
+
+func writeTestBlock(t *testing.T, db ethdb.Database, number uint64, logs []*types.Log) *types.Header {
+	receipt := types.NewReceipt(nil, false, big.NewInt(0))
+	receipt.Logs = logs
+	receipts := types.Receipts{receipt}
+
+	header := &types.Header{
+		Number: new(big.Int).SetUint64(number),
+		Time:   big.NewInt(0),
+		Bloom:  types.CreateBloom(receipts),
+	}
+	if err := core.WriteBlockReceipts(db, header.Hash(), number, receipts); err != nil {
+		t.Fatal(err)
+	}
+	if err := core.WriteCanonicalHash(db, header.Hash(), number); err != nil {
+		t.Fatal(err)
+	}
+	return header
+}
+
+func TestInsertSection(t *testing.T) {
+	sections, added := insertSection(nil, 3)
+	if !added || !reflect.DeepEqual(sections, []uint64{3}) {
+		t.Fatalf("wrong sections %v", sections)
+	}
+	sections, added = insertSection(sections, 1)
+	if !added || !reflect.DeepEqual(sections, []uint64{1, 3}) {
+		t.Fatalf("wrong sections %v", sections)
+	}
+	sections, added = insertSection(sections, 3)
+	if added || !reflect.DeepEqual(sections, []uint64{1, 3}) {
+		t.Fatalf("wrong sections %v", sections)
+	}
+}
+
+func TestIndexerHistory(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	b := &Indexer{db: db, table: ethdb.NewTable(db, string(core.StakeHistoryIndexPrefix))}
+
+	in := delegateInLog(new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Wan)))
+
+	b.Reset(0)
+	b.Process(writeTestBlock(t, db, 1, []*types.Log{in}))
+	b.Process(writeTestBlock(t, db, 2, nil))
+	if err := b.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	validator := readHistory(b.table, db, validatorPrefix, testValidator, 0, 10)
+	if len(validator) != 1 || validator[0].Event != "delegateIn" || validator[0].BlockNumber != 1 {
+		t.Fatalf("wrong validator history %v", validator)
+	}
+	delegator := readHistory(b.table, db, delegatorPrefix, testSender, 0, 10)
+	if len(delegator) != 1 || delegator[0].Validator != testValidator {
+		t.Fatalf("wrong delegator history %v", delegator)
+	}
+
+	// once block 1 is reorged out, the entry is not reported anymore
+	core.WriteCanonicalHash(db, common.HexToHash("0x01"), 1)
+	if history := readHistory(b.table, db, validatorPrefix, testValidator, 0, 10); len(history) != 0 {
+		t.Fatalf("reorged entry reported %v", history)
+	}
+}
@@ -12,6 +12,16 @@ import (
 	"github.com/wanchain/go-wanchain/rpc"
 )
 
+// posTxSender submits the pos transactions inside the node instead of the
+// eth_sendPosTransaction api, it's set when they are signed by a remote signer.
+var posTxSender func(tx map[string]interface{}) (common.Hash, error)
+
+// SetPosTxSender sets the sender of the pos transactions, nil restores the
+// eth_sendPosTransaction api.
+func SetPosTxSender(sender func(tx map[string]interface{}) (common.Hash, error)) {
+	posTxSender = sender
+}
+
 //type SendTxArgs struct {
 //  From     common.Address  `json:"from"`
 //  To       *common.Address `json:"to"`
@@ -28,9 +38,13 @@ func SendTx(rc *rpc.Client, tx map[string]interface{}) (common.Hash, error) {
 		return common.Hash{}, errors.New("rc is not ready")
 	}
 
-	ctx := context.Background()
 	var txHash common.Hash
-	err := rc.CallContext(ctx, &txHash, "eth_sendPosTransaction", tx)
+	var err error
+	if sender := posTxSender; sender != nil {
+		txHash, err = sender(tx)
+	} else {
+		err = rc.CallContext(context.Background(), &txHash, "eth_sendPosTransaction", tx)
+	}
 	if nil != err {
 		log.SyslogErr("send pos tx fail", "err", err)
 		return common.Hash{}, err
@@ -40,11 +54,11 @@ func SendTx(rc *rpc.Client, tx map[string]interface{}) (common.Hash, error) {
 	return txHash, nil
 }
 
-func SendPosTx(rc *rpc.Client, tx map[string]interface{})  {
+func SendPosTx(rc *rpc.Client, tx map[string]interface{}) (common.Hash, error) {
 	if posconfig.TxDelay != 0 {
 		delay := rand.Intn(posconfig.TxDelay)
 		time.Sleep(time.Duration(delay)*time.Second)
 		log.Debug("SendPosTx", "delay",delay )
 	}
-	SendTx(rc, tx)
+	return SendTx(rc, tx)
 }
\ No newline at end of file
@@ -55,6 +55,22 @@ var Forks = map[string]*params.ChainConfig{
 		//DAOForkBlock:   big.NewInt(0),
 		ByzantiumBlock: big.NewInt(0),
 	},
+	"ConstantinopleFix": &params.ChainConfig{
+		ChainId:         big.NewInt(1),
+		ByzantiumBlock:  big.NewInt(0),
+		PetersburgBlock: big.NewInt(0),
+	},
+	"Petersburg": &params.ChainConfig{
+		ChainId:         big.NewInt(1),
+		ByzantiumBlock:  big.NewInt(0),
+		PetersburgBlock: big.NewInt(0),
+	},
+	"Istanbul": &params.ChainConfig{
+		ChainId:         big.NewInt(1),
+		ByzantiumBlock:  big.NewInt(0),
+		PetersburgBlock: big.NewInt(0),
+		IstanbulBlock:   big.NewInt(0),
+	},
 //
 //	"FrontierToHomesteadAt5": &params.ChainConfig{
 //		ChainId:        big.NewInt(1),
@@ -58,8 +58,10 @@ func TestState(t *testing.T) {
 			key := fmt.Sprintf("%s/%d", subtest.Fork, subtest.Index)
 			name := name + "/" + key
 			t.Run(key, func(t *testing.T) {
+				// EIP-1283 is declined, Petersburg keeps the SSTORE metering of
+				// Byzantium and the net metering comes with EIP-2200 in Istanbul.
 				if subtest.Fork == "Constantinople" {
-					t.Skip("constantinople not supported yet")
+					t.Skip("EIP-1283 declined, run as ConstantinopleFix")
 				}
 				withTrace(t, test.gasLimit(subtest), func(vmconfig vm.Config) error {
 					_, err := test.Run(subtest, vmconfig)